@@ -54,6 +54,19 @@ type ClientConfigType string
 // ClusterType represents an interface implementing the  ClientConfigManager
 type ClusterType string
 
+// NodeDiscoveryMode defines how the nodes of an external cluster are resolved
+type NodeDiscoveryMode string
+
+// KeystoreFormat defines the format of a keystore included into a user secret
+// +kubebuilder:validation:Enum={"jks","pkcs12"}
+type KeystoreFormat string
+
+// TenantPruningMode defines how the users and groups not managed by the operator are handled
+type TenantPruningMode string
+
+// SensitivePropertiesKeyRotationState holds info about the state of the sensitive properties key rotation
+type SensitivePropertiesKeyRotationState string
+
 // AccessPolicyType represents the type of access policy
 type AccessPolicyType string
 
@@ -63,6 +76,9 @@ type AccessPolicyAction string
 // AccessPolicyResource represents the access policy resource
 type AccessPolicyResource string
 
+// ComponentReferenceKind represents the kind of resource referenced by a component access policy
+type ComponentReferenceKind string
+
 func (r State) IsUpscale() bool {
 	return r == GracefulUpscaleRequired || r == GracefulUpscaleSucceeded || r == GracefulUpscaleRunning
 }
@@ -141,6 +157,13 @@ type ParameterContextReference struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// ParameterProviderReference states a reference to a parameter provider for parameter context
+// provisioning
+type ParameterProviderReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
 // SecretReference states a reference to a secret for parameter context
 // provisioning
 type SecretReference struct {
@@ -155,6 +178,13 @@ type UserReference struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// UserGroupReference states a reference to a user group for access policy
+// provisioning
+type UserGroupReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
 type AccessPolicy struct {
 	// +kubebuilder:validation:Enum={"global","component"}
 	// type defines the kind of access policy, could be "global" or "component".
@@ -172,6 +202,19 @@ type AccessPolicy struct {
 	// componentId is used if the type is "component", it's allow to define the id of the component on which is the
 	// access policy
 	ComponentId string `json:"componentId,omitempty"`
+	// componentRef is used if the type is "component", it's allow to reference the NifiDataflow, NifiParameterContext
+	// or NifiRegistryClient on which is the access policy, instead of defining its id.
+	ComponentRef *ComponentReference `json:"componentRef,omitempty"`
+}
+
+// ComponentReference states a reference to a NifiDataflow, NifiParameterContext or NifiRegistryClient for component
+// access policy provisioning
+type ComponentReference struct {
+	// +kubebuilder:validation:Enum={"NifiDataflow","NifiParameterContext","NifiRegistryClient"}
+	// kind of the referenced resource.
+	Kind      ComponentReferenceKind `json:"kind"`
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace,omitempty"`
 }
 
 func (a *AccessPolicy) GetResource(rootProcessGroupId string) string {
@@ -245,12 +288,33 @@ const (
 	DataTransferAccessPolicyResource AccessPolicyResource = "/data-transfer"
 
 	// ComponentType
-	ProcessGroupType string = "process-groups"
+	ProcessGroupType     string = "process-groups"
+	ParameterContextType string = "parameter-contexts"
+	RegistryClientType   string = "registry-clients"
+
+	// ComponentReferenceKind
+	DataflowComponentReferenceKind         ComponentReferenceKind = "NifiDataflow"
+	ParameterContextComponentReferenceKind ComponentReferenceKind = "NifiParameterContext"
+	RegistryClientComponentReferenceKind   ComponentReferenceKind = "NifiRegistryClient"
 )
 
+// GetComponentType returns the type of component of the resources of the given kind.
+func (k ComponentReferenceKind) GetComponentType() string {
+	switch k {
+	case ParameterContextComponentReferenceKind:
+		return ParameterContextType
+	case RegistryClientComponentReferenceKind:
+		return RegistryClientType
+	default:
+		return ProcessGroupType
+	}
+}
+
 const (
 	// PKIBackendCertManager invokes cert-manager for user certificate management
 	PKIBackendCertManager PKIBackend = "cert-manager"
+	// PKIBackendK8sCSR invokes kubernetes CertificateSigningRequests for user certificate management
+	PKIBackendK8sCSR PKIBackend = "k8s-csr"
 	// TODO : Add vault
 	//PKIBackendVault invokes vault PKI for user certificate management
 	//PKIBackendVault PKIBackend = "vault"
@@ -266,6 +330,64 @@ const (
 	InternalCluster ClusterType = "internal"
 )
 
+const (
+	// StaticNodeDiscovery computes the nodes from the nodeURITemplate and the nodes list
+	StaticNodeDiscovery NodeDiscoveryMode = "static"
+	// ApiNodeDiscovery discovers the nodes from the NiFi cluster API
+	ApiNodeDiscovery NodeDiscoveryMode = "api"
+)
+
+const (
+	// JKSKeystoreFormat includes a Java keystore and truststore into the user secret
+	JKSKeystoreFormat KeystoreFormat = "jks"
+	// PKCS12KeystoreFormat includes a PKCS12 keystore and truststore into the user secret
+	PKCS12KeystoreFormat KeystoreFormat = "pkcs12"
+)
+
+const (
+	// TenantPruningOff ignores the users and groups not managed by the operator
+	TenantPruningOff TenantPruningMode = "off"
+	// TenantPruningReport lists the users and groups not managed by the operator into the cluster status
+	TenantPruningReport TenantPruningMode = "report"
+	// TenantPruningEnforce removes the users and groups not managed by the operator from the NiFi cluster
+	TenantPruningEnforce TenantPruningMode = "enforce"
+)
+
+const (
+	// SensitivePropertiesKeyRotationRunning states that the nodes are re-encrypting their flow with the new key
+	SensitivePropertiesKeyRotationRunning SensitivePropertiesKeyRotationState = "Running"
+	// SensitivePropertiesKeyRotationSucceeded states that the flow of every node is encrypted with the new key
+	SensitivePropertiesKeyRotationSucceeded SensitivePropertiesKeyRotationState = "Succeeded"
+)
+
+const (
+	// SensitivePropertiesKey is the key of the sensitive properties key into the operator managed secret
+	SensitivePropertiesKey = "key"
+	// SensitivePropertiesAlgorithm is the key of the sensitive properties algorithm into the operator managed secret
+	SensitivePropertiesAlgorithm = "algorithm"
+	// PreviousSensitivePropertiesKey is the key of the sensitive properties key being rotated into the operator managed secret
+	PreviousSensitivePropertiesKey = "previousKey"
+	// PreviousSensitivePropertiesAlgorithm is the key of the sensitive properties algorithm being rotated into the operator managed secret
+	PreviousSensitivePropertiesAlgorithm = "previousAlgorithm"
+	// DefaultSensitivePropertiesAlgorithm is the algorithm used to encrypt the sensitive properties when not specified
+	DefaultSensitivePropertiesAlgorithm = "PBEWITHMD5AND256BITAES-CBC-OPENSSL"
+)
+
+const (
+	// SingleUserUsername is the key of the single user username into the operator managed secret
+	SingleUserUsername = "username"
+	// SingleUserPasswordHash is the key of the bcrypt hash of the single user password into the operator managed secret
+	SingleUserPasswordHash = "passwordHash"
+)
+
+const (
+	// TrustedCAsTruststore is the name of the truststore holding the additional trusted certificate authorities
+	TrustedCAsTruststore = "truststore.jks"
+	// TrustedCAsTruststorePassword is the password of the truststore holding the additional trusted certificate
+	// authorities, which only contains public certificates and keeps the JVM default one
+	TrustedCAsTruststorePassword = "changeit"
+)
+
 const (
 	// DataflowStateCreated describes the status of a NifiDataflow as created
 	DataflowStateCreated DataflowState = "Created"
@@ -277,6 +399,10 @@ const (
 	DataflowStateOutOfSync DataflowState = "OutOfSync"
 	// DataflowStateInSync describes the status of a NifiDataflow as in sync
 	DataflowStateInSync DataflowState = "InSync"
+	// DataflowStateStopped describes the status of a NifiDataflow as stopped outside of its schedule
+	DataflowStateStopped DataflowState = "Stopped"
+	// DataflowStateDegraded describes the status of a NifiDataflow whose update has been aborted
+	DataflowStateDegraded DataflowState = "Degraded"
 
 	// RevertRequestType defines a revert changes request.
 	RevertRequestType DataflowUpdateRequestType = "Revert"
@@ -288,6 +414,9 @@ const (
 	DrainStrategy DataflowUpdateStrategy = "drain"
 	// DropStrategy leads to shutting down all components and dropping all flowfiles from the flow.
 	DropStrategy DataflowUpdateStrategy = "drop"
+	// BlueGreenStrategy leads to importing the new flow version into a sibling process group, started and monitored
+	// during a soak period before replacing the previous one, which is drained and removed.
+	BlueGreenStrategy DataflowUpdateStrategy = "blueGreen"
 
 	// UserStateCreated describes the status of a NifiUser as created
 	UserStateCreated UserState = "created"
@@ -299,6 +428,10 @@ const (
 	TLSJKSKeyStore string = "keystore.jks"
 	// TLSJKSTrustStore is where a JKS truststore is stored in a user secret when requested
 	TLSJKSTrustStore string = "truststore.jks"
+	// TLSPKCS12KeyStore is where a PKCS12 keystore is stored in a user secret when requested
+	TLSPKCS12KeyStore string = "keystore.p12"
+	// TLSPKCS12TrustStore is where a PKCS12 truststore is stored in a user secret when requested
+	TLSPKCS12TrustStore string = "truststore.p12"
 	// CoreCACertKey is where ca ceritificates are stored in user certificates
 	CoreCACertKey string = "ca.crt"
 	// CACertKey is the key where the CA certificate is stored in the operator secrets
@@ -313,7 +446,7 @@ const (
 	PeerCertKey string = "peerCert"
 	// PeerPrivateKeyKey stores the peer private key
 	PeerPrivateKeyKey string = "peerKey"
-	// PasswordKey stores the JKS password
+	// PasswordKey stores the JKS and PKCS12 password
 	PasswordKey string = "password"
 )
 
@@ -339,6 +472,43 @@ type NodeState struct {
 	InitClusterNode InitClusterNode `json:"initClusterNode"`
 	// PodIsReady whether or not the associated pod is ready
 	PodIsReady bool `json:"podIsReady"`
+	// DiscoveredNode holds the node information discovered from the NiFi cluster API (used if external type)
+	DiscoveredNode *DiscoveredNode `json:"discoveredNode,omitempty"`
+	// NodeHealth holds the health of the node as reported by the NiFi cluster API
+	NodeHealth *NodeHealthState `json:"nodeHealth,omitempty"`
+}
+
+// NodeHealthState holds the health of a node as reported by the NiFi cluster API
+type NodeHealthState struct {
+	// ConnectionStatus is the status of the node on NiFi cluster side (CONNECTED, DISCONNECTED, ...)
+	ConnectionStatus string `json:"connectionStatus"`
+	// LastHeartbeat is the time of the last heartbeat received from the node, as of the last change of the node health
+	LastHeartbeat string `json:"lastHeartbeat,omitempty"`
+	// HeartbeatAgeSeconds is the age of the last heartbeat, as of the last change of the node health or of the last
+	// change of at least 60 seconds of the age
+	HeartbeatAgeSeconds int64 `json:"heartbeatAgeSeconds,omitempty"`
+	// Roles contains the roles of the node into the cluster (Primary Node, Cluster Coordinator)
+	Roles []string `json:"roles,omitempty"`
+	// ActiveThreadCount is the number of active threads on the node, as of the last change of the node health or of the
+	// last change of at least 10 threads
+	ActiveThreadCount int32 `json:"activeThreadCount,omitempty"`
+	// DisconnectedSince holds the time since when the node is not connected to the cluster
+	DisconnectedSince string `json:"disconnectedSince,omitempty"`
+	// LastReconnectAttempt holds the time of the last reconnection requested by the operator
+	LastReconnectAttempt string `json:"lastReconnectAttempt,omitempty"`
+}
+
+// IsConnected returns true if the node is connected to the cluster
+func (h NodeHealthState) IsConnected() bool {
+	return h.ConnectionStatus == string(ConnectStatus)
+}
+
+// DiscoveredNode holds the information of a node discovered from the NiFi cluster API
+type DiscoveredNode struct {
+	// ClusterNodeId is the id of the node on NiFi cluster side
+	ClusterNodeId string `json:"clusterNodeId"`
+	// Address is the address used to request the node, in the form hostname:port
+	Address string `json:"address"`
 }
 
 // RackAwarenessState holds info about rack awareness status
@@ -438,3 +608,79 @@ const (
 	SyncOnce   DataflowSyncMode = "once"
 	SyncAlways DataflowSyncMode = "always"
 )
+
+// BulletinLevel defines the minimum severity of the reported bulletins
+type BulletinLevel string
+
+const (
+	// WarnBulletinLevel reports the WARN and ERROR bulletins
+	WarnBulletinLevel BulletinLevel = "WARN"
+	// ErrorBulletinLevel reports only the ERROR bulletins
+	ErrorBulletinLevel BulletinLevel = "ERROR"
+	// NoneBulletinLevel disables the bulletins reporting
+	NoneBulletinLevel BulletinLevel = "NONE"
+)
+
+// LocalChangesPolicy defines the way the operator deals with the local changes of a versioned dataflow
+type LocalChangesPolicy string
+
+const (
+	// RevertLocalChangesPolicy reverts the local changes to the deployed flow version
+	RevertLocalChangesPolicy LocalChangesPolicy = "revert"
+	// ReportLocalChangesPolicy keeps the local changes and reports the modified components into the status
+	ReportLocalChangesPolicy LocalChangesPolicy = "report"
+	// CommitLocalChangesPolicy saves the local changes as a new flow version into the registry
+	CommitLocalChangesPolicy LocalChangesPolicy = "commit"
+)
+
+// DrainFallback defines the action taken when a dataflow is not drained within the drain timeout
+type DrainFallback string
+
+const (
+	// DropDrainFallback drops the flowfiles remaining in the dataflow queues
+	DropDrainFallback DrainFallback = "drop"
+	// AbortDrainFallback abandons the update and marks the dataflow as degraded
+	AbortDrainFallback DrainFallback = "abort"
+	// ForceDrainFallback proceeds with the update whatever the flowfiles remaining in the dataflow queues
+	ForceDrainFallback DrainFallback = "force"
+)
+
+// ComponentType defines the type of a dataflow component
+type ComponentType string
+
+const (
+	// ProcessorComponentType defines a processor
+	ProcessorComponentType ComponentType = "processor"
+	// InputPortComponentType defines an input port
+	InputPortComponentType ComponentType = "inputPort"
+	// OutputPortComponentType defines an output port
+	OutputPortComponentType ComponentType = "outputPort"
+)
+
+// ComponentRunState defines the state a dataflow component is kept in
+type ComponentRunState string
+
+const (
+	// StoppedComponentRunState keeps the component stopped
+	StoppedComponentRunState ComponentRunState = "STOPPED"
+	// DisabledComponentRunState keeps the component disabled
+	DisabledComponentRunState ComponentRunState = "DISABLED"
+)
+
+// BlueGreenPhase defines the phase of a blue/green dataflow update
+type BlueGreenPhase string
+
+const (
+	// BlueGreenPhaseValidating describes the new process group as imported and being validated
+	BlueGreenPhaseValidating BlueGreenPhase = "Validating"
+	// BlueGreenPhaseStarting describes the new process group as starting
+	BlueGreenPhaseStarting BlueGreenPhase = "Starting"
+	// BlueGreenPhaseSoaking describes the new process group as running and monitored during the soak period
+	BlueGreenPhaseSoaking BlueGreenPhase = "Soaking"
+	// BlueGreenPhasePromoting describes the previous process group as being drained and removed
+	BlueGreenPhasePromoting BlueGreenPhase = "Promoting"
+	// BlueGreenPhaseRollingBack describes the new process group as being removed after a failure
+	BlueGreenPhaseRollingBack BlueGreenPhase = "RollingBack"
+	// BlueGreenPhaseRolledBack describes the new process group as removed after a failure
+	BlueGreenPhaseRolledBack BlueGreenPhase = "RolledBack"
+)
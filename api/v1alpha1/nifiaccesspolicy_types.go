@@ -0,0 +1,89 @@
+/*
+Copyright 2020.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NifiAccessPolicySpec defines the desired state of NifiAccessPolicy
+type NifiAccessPolicySpec struct {
+	// clusterRef contains the reference to the NifiCluster with the one the access policy is linked.
+	ClusterRef ClusterReference `json:"clusterRef"`
+	// accessPolicy defines the access policy whose members are managed.
+	AccessPolicy AccessPolicy `json:"accessPolicy"`
+	// usersRef contains the list of reference to NifiUsers that are granted the access policy.
+	UsersRef []UserReference `json:"usersRef,omitempty"`
+	// userGroupsRef contains the list of reference to NifiUserGroups that are granted the access policy.
+	UserGroupsRef []UserGroupReference `json:"userGroupsRef,omitempty"`
+}
+
+// NifiAccessPolicyStatus defines the observed state of NifiAccessPolicy
+type NifiAccessPolicyStatus struct {
+	// the nifi access policy id.
+	Id string `json:"id"`
+	// the last nifi access policy revision version catched.
+	Version int64 `json:"version"`
+	// the nifi action of the access policy.
+	Action AccessPolicyAction `json:"action,omitempty"`
+	// the nifi resource of the access policy.
+	Resource string `json:"resource,omitempty"`
+	// the users granted the access policy.
+	Users []AccessPolicyMember `json:"users,omitempty"`
+	// the user groups granted the access policy.
+	UserGroups []AccessPolicyMember `json:"userGroups,omitempty"`
+	// the NifiUsers, NifiUserGroups and NifiAccessPolicies also defining the access policy, which prevent it from
+	// being managed.
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// AccessPolicyMember represents a NifiUser or NifiUserGroup granted the access policy.
+type AccessPolicyMember struct {
+	// the name of the resource.
+	Name string `json:"name"`
+	// the namespace of the resource.
+	Namespace string `json:"namespace"`
+	// the nifi tenant id.
+	Id string `json:"id"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Action",type="string",JSONPath=".spec.accessPolicy.action"
+// +kubebuilder:printcolumn:name="Resource",type="string",JSONPath=".status.resource"
+
+// NifiAccessPolicy is the Schema for the nifiaccesspolicies API
+type NifiAccessPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NifiAccessPolicySpec   `json:"spec,omitempty"`
+	Status NifiAccessPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NifiAccessPolicyList contains a list of NifiAccessPolicy
+type NifiAccessPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NifiAccessPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NifiAccessPolicy{}, &NifiAccessPolicyList{})
+}
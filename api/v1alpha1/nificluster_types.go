@@ -48,6 +48,11 @@ type NifiClusterSpec struct {
 	NodeURITemplate string `json:"nodeURITemplate,omitempty"`
 	// nifiURI used access through a LB uri (used if external type)
 	NifiURI string `json:"nifiURI,omitempty"`
+	// nodeDiscovery defines how the nodes of the cluster are resolved (used if external type) :
+	// "static" computes them from nodeURITemplate and nodes, "api" discovers them from the NiFi cluster API
+	// reachable through nifiURI.
+	// +kubebuilder:validation:Enum={"static","api"}
+	NodeDiscovery NodeDiscoveryMode `json:"nodeDiscovery,omitempty"`
 	// rootProcessGroupId contains the uuid of the root process group for this cluster (used if external type)
 	RootProcessGroupId string `json:"rootProcessGroupId,omitempty"`
 	// secretRef reference the secret containing the informations required to authentiticate to the cluster (used if external type)
@@ -93,8 +98,22 @@ type NifiClusterSpec struct {
 	DisruptionBudget DisruptionBudget `json:"disruptionBudget,omitempty"`
 	// LdapConfiguration specifies the configuration if you want to use LDAP
 	LdapConfiguration LdapConfiguration `json:"ldapConfiguration,omitempty"`
+	// SingleUserConfiguration specifies the configuration if you want to use the single user login identity provider
+	SingleUserConfiguration SingleUserConfiguration `json:"singleUserConfiguration,omitempty"`
+	// LoginIdentityProviders specifies additional login identity providers rendered by the operator into the
+	// login-identity-providers.xml file of the nodes
+	LoginIdentityProviders LoginIdentityProvidersConfiguration `json:"loginIdentityProviders,omitempty"`
 	// NifiClusterTaskSpec specifies the configuration of the nifi cluster Tasks
 	NifiClusterTaskSpec NifiClusterTaskSpec `json:"nifiClusterTaskSpec,omitempty"`
+	// NodesHealthCheck specifies the configuration of the nodes health monitoring
+	NodesHealthCheck NodesHealthCheckSpec `json:"nodesHealthCheck,omitempty"`
+	// TenantPruning specifies how the users and groups of the NiFi cluster not managed by the operator are handled
+	TenantPruning TenantPruningSpec `json:"tenantPruning,omitempty"`
+	// SensitiveProperties specifies the key and the algorithm used to encrypt the sensitive properties of the flow
+	SensitiveProperties SensitivePropertiesSpec `json:"sensitiveProperties,omitempty"`
+	// AdditionalTrustedCAs references the PEM bundles of the certificate authorities merged into a separate truststore
+	// mounted in every node and used as the JVM default truststore, so that the flows can reach external TLS services.
+	AdditionalTrustedCAs []TrustedCASource `json:"additionalTrustedCAs,omitempty"`
 	// TODO : add vault
 	//VaultConfig         	VaultConfig         `json:"vaultConfig,omitempty"`
 	// listenerConfig specifies nifi's listener specifig configs
@@ -174,6 +193,30 @@ type ReadOnlyConfig struct {
 	LogbackConfig LogbackConfig `json:"logbackConfig,omitempty"`
 	// BootstrapNotificationServices configuration that will be applied to the node.
 	BootstrapNotificationServicesReplaceConfig BootstrapNotificationServicesConfig `json:"bootstrapNotificationServicesConfig,omitempty"`
+	// Encryption configuration of the content, flowfile and provenance repositories that will be applied to the node.
+	Encryption *RepositoryEncryptionConfig `json:"encryption,omitempty"`
+}
+
+// TrustedCASource references a PEM bundle of certificate authorities from a secret or a configmap key, in the cluster
+// namespace. Exactly one of secretRef and configMapRef must be set.
+type TrustedCASource struct {
+	// secretRef references the secret key containing the PEM bundle.
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+	// configMapRef references the configmap key containing the PEM bundle.
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+}
+
+// RepositoryEncryptionConfig configures the encryption of the content, flowfile and provenance repositories, using the
+// keys of a keystore as key provider (requires NiFi 1.14+).
+type RepositoryEncryptionConfig struct {
+	// keystoreSecretRef references the secret key, in the cluster namespace, containing the PKCS12 keystore holding the
+	// AES keys used to encrypt the repositories. It is mounted into the nodes.
+	KeystoreSecretRef corev1.SecretKeySelector `json:"keystoreSecretRef"`
+	// keystorePasswordSecretRef references the secret key, in the cluster namespace, containing the password of the keystore.
+	KeystorePasswordSecretRef corev1.SecretKeySelector `json:"keystorePasswordSecretRef"`
+	// keyId is the alias of the key used to encrypt the new records, the other keys of the keystore remaining used to
+	// decrypt the existing ones.
+	KeyId string `json:"keyId"`
 }
 
 // NifiProperties configuration that will be applied to the node.
@@ -322,9 +365,23 @@ type SSLSecrets struct {
 	// https://cert-manager.io/docs/concepts/issuer/
 	IssuerRef *cmmeta.ObjectReference `json:"issuerRef,omitempty"`
 	// TODO : add vault
-	// +kubebuilder:validation:Enum={"cert-manager","vault"}
+	// +kubebuilder:validation:Enum={"cert-manager","k8s-csr","vault"}
 	PKIBackend PKIBackend `json:"pkiBackend,omitempty"`
 	//,"vault"
+
+	// csr configures the k8s-csr pki backend, signing the certificates through kubernetes CertificateSigningRequests
+	CSR *CSRConfig `json:"csr,omitempty"`
+}
+
+// CSRConfig defines the configuration of the k8s-csr pki backend
+type CSRConfig struct {
+	// signerName is the signer requested by the CertificateSigningRequests of the node and user certificates, it must
+	// be allowed to issue certificates with the client auth and server auth usages :
+	// https://kubernetes.io/docs/reference/access-authn-authz/certificate-signing-requests/#signers
+	SignerName string `json:"signerName"`
+	// caBundleRef references the PEM bundle of the certificate authority of the signer, added to the truststores.
+	// It defaults to the ca.crt key of the kube-root-ca.crt config map of the cluster namespace.
+	CABundleRef *corev1.ConfigMapKeySelector `json:"caBundleRef,omitempty"`
 }
 
 // TODO : Add vault
@@ -449,12 +506,86 @@ type LdapConfiguration struct {
 	SearchFilter string `json:"searchFilter,omitempty"`
 }
 
+// SingleUserConfiguration specifies the configuration if you want to use the single user login identity provider
+type SingleUserConfiguration struct {
+	// If set to true, we will enable the single user login identity provider into nifi.properties configuration
+	// (requires NiFi 1.14+). The LDAP login identity provider takes precedence when both are enabled. The single user
+	// is added to the managed admins, and is the initial admin of the clusters created with the basic client type.
+	Enabled bool `json:"enabled,omitempty"`
+	// secretRef references the secret containing the "username" and "password" of the single user. It is also used by
+	// the operator to authenticate when the basic client type is used.
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+}
+
+// LoginIdentityProvidersConfiguration specifies additional login identity providers, e.g. the ones shipped with a
+// custom NiFi image, rendered by the operator into the login-identity-providers.xml file of the nodes.
+type LoginIdentityProvidersConfiguration struct {
+	// selected is the identifier of the provider used as nifi.security.user.login.identity.provider. The LDAP and the
+	// single user login identity providers take precedence when they are enabled.
+	Selected string `json:"selected,omitempty"`
+	// providers is the list of the login identity providers, their identifiers must be unique.
+	Providers []LoginIdentityProvider `json:"providers,omitempty"`
+}
+
+// LoginIdentityProvider defines a login identity provider of the login-identity-providers.xml file.
+type LoginIdentityProvider struct {
+	// identifier of the provider, ldap-provider and single-user-provider being reserved by the operator.
+	Identifier string `json:"identifier"`
+	// class is the fully qualified name of the provider implementation, which must be available into the NiFi image.
+	Class string `json:"class"`
+	// properties of the provider, rendered in the given order.
+	Properties []LoginIdentityProviderProperty `json:"properties,omitempty"`
+}
+
+// LoginIdentityProviderProperty defines a property of a login identity provider, its value being either set directly
+// or read from a secret key in the cluster namespace.
+type LoginIdentityProviderProperty struct {
+	// name of the property.
+	Name string `json:"name"`
+	// value of the property.
+	Value string `json:"value,omitempty"`
+	// secretRef references the secret key containing the value of the property, e.g. for a password.
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+}
+
 // NifiClusterTaskSpec specifies the configuration of the nifi cluster Tasks
 type NifiClusterTaskSpec struct {
 	// RetryDurationMinutes describes the amount of time the Operator waits for the task
 	RetryDurationMinutes int `json:"retryDurationMinutes"`
 }
 
+// NodesHealthCheckSpec specifies the configuration of the nodes health monitoring
+type NodesHealthCheckSpec struct {
+	// If set to true, the operator will request the reconnection of the nodes disconnected
+	// from the NiFi cluster for longer than the reconnect grace period.
+	AutoReconnect bool `json:"autoReconnect,omitempty"`
+	// ReconnectGracePeriodMinutes describes the amount of time a node may stay disconnected
+	// before the operator requests its reconnection.
+	ReconnectGracePeriodMinutes int `json:"reconnectGracePeriodMinutes,omitempty"`
+}
+
+// TenantPruningSpec specifies how the users and groups of the NiFi cluster not managed by the operator are handled
+type TenantPruningSpec struct {
+	// mode defines how the users and groups not declared by a NifiUser, a NifiUserGroup or the cluster itself are handled :
+	// "off" ignores them, "report" lists them into the cluster status, "enforce" removes them from the NiFi cluster.
+	// The access policies are never removed, only reported: NiFi revokes the bindings of the removed users and groups.
+	// +kubebuilder:validation:Enum={"off","report","enforce"}
+	Mode TenantPruningMode `json:"mode,omitempty"`
+	// allowlist contains the identities of the users and groups which are never reported nor removed (e.g. break-glass accounts).
+	Allowlist []string `json:"allowlist,omitempty"`
+}
+
+// SensitivePropertiesSpec specifies the key and the algorithm used to encrypt the sensitive properties of the flow
+type SensitivePropertiesSpec struct {
+	// keySecretRef references the secret key containing the sensitive properties key, which must be at least 12 characters long.
+	// When not set, the key in effect on the existing nodes is kept if long enough, a random key being generated otherwise.
+	// Changing the referenced key rotates it on every node.
+	KeySecretRef *corev1.SecretKeySelector `json:"keySecretRef,omitempty"`
+	// algorithm used to encrypt the sensitive properties, changing it migrates the flow to the new algorithm (requires NiFi 1.16+).
+	// +kubebuilder:validation:Enum={"PBEWITHMD5AND256BITAES-CBC-OPENSSL","NIFI_PBKDF2_AES_GCM_256","NIFI_ARGON2_AES_GCM_256","NIFI_BCRYPT_AES_GCM_256","NIFI_SCRYPT_AES_GCM_256"}
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
 // NifiClusterStatus defines the observed state of NifiCluster
 type NifiClusterStatus struct {
 	// Store the state of each nifi node
@@ -467,6 +598,21 @@ type NifiClusterStatus struct {
 	RootProcessGroupId string `json:"rootProcessGroupId,omitempty"`
 	// PrometheusReportingTask contains the status of the prometheus reporting task managed by the operator
 	PrometheusReportingTask PrometheusReportingTaskStatus `json:"prometheusReportingTask,omitempty"`
+	// UnmanagedTenants contains the users and groups of the NiFi cluster not managed by the operator
+	UnmanagedTenants *UnmanagedTenantsStatus `json:"unmanagedTenants,omitempty"`
+	// SensitivePropertiesKeyRotation holds the state of the last rotation of the sensitive properties key
+	SensitivePropertiesKeyRotation SensitivePropertiesKeyRotationState `json:"sensitivePropertiesKeyRotation,omitempty"`
+}
+
+// UnmanagedTenantsStatus contains the users and groups of the NiFi cluster not managed by the operator, found by the
+// tenant pruning
+type UnmanagedTenantsStatus struct {
+	// The identities of the unmanaged users
+	Users []string `json:"users,omitempty"`
+	// The identities of the unmanaged user groups
+	UserGroups []string `json:"userGroups,omitempty"`
+	// The access policies granted to the unmanaged users and user groups, formatted as "<action> <resource>"
+	AccessPolicies []string `json:"accessPolicies,omitempty"`
 }
 
 type PrometheusReportingTaskStatus struct {
@@ -552,6 +698,38 @@ func (nReadOnlyConfig *ReadOnlyConfig) GetMaximumTimerDrivenThreadCount() int32
 	return *nReadOnlyConfig.MaximumTimerDrivenThreadCount
 }
 
+// GetNodeDiscovery returns the default "static" NodeDiscovery if not specified otherwise
+func (nSpec *NifiClusterSpec) GetNodeDiscovery() NodeDiscoveryMode {
+	if nSpec.NodeDiscovery == "" {
+		return StaticNodeDiscovery
+	}
+	return nSpec.NodeDiscovery
+}
+
+// GetReconnectGracePeriodMinutes returns the default 5 minutes grace period if not specified otherwise
+func (hSpec *NodesHealthCheckSpec) GetReconnectGracePeriodMinutes() float64 {
+	if hSpec.ReconnectGracePeriodMinutes == 0 {
+		return 5
+	}
+	return float64(hSpec.ReconnectGracePeriodMinutes)
+}
+
+// GetAlgorithm returns the algorithm used to encrypt the sensitive properties
+func (sSpec *SensitivePropertiesSpec) GetAlgorithm() string {
+	if sSpec.Algorithm == "" {
+		return DefaultSensitivePropertiesAlgorithm
+	}
+	return sSpec.Algorithm
+}
+
+// GetMode returns the tenant pruning mode, tenants are not pruned by default
+func (tSpec *TenantPruningSpec) GetMode() TenantPruningMode {
+	if tSpec.Mode == "" {
+		return TenantPruningOff
+	}
+	return tSpec.Mode
+}
+
 func (nTaskSpec *NifiClusterTaskSpec) GetDurationMinutes() float64 {
 	if nTaskSpec.RetryDurationMinutes == 0 {
 		return 5
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,6 +29,10 @@ import (
 type NifiDataflowSpec struct {
 	// the UUID of the parent process group where you want to deploy your dataflow, if not set deploy at root level.
 	ParentProcessGroupID string `json:"parentProcessGroupID,omitempty"`
+	// the UUID of an existing versioned process group to adopt, in place of importing the flow.
+	AdoptProcessGroupId string `json:"adoptProcessGroupId,omitempty"`
+	// the name of an existing versioned process group under the parent process group to adopt, in place of importing the flow.
+	AdoptProcessGroupName string `json:"adoptProcessGroupName,omitempty"`
 	// the UUID of the Bucket containing the flow.
 	BucketId string `json:"bucketId"`
 	// the UUID of the flow to run.
@@ -48,9 +54,79 @@ type NifiDataflowSpec struct {
 	ClusterRef ClusterReference `json:"clusterRef,omitempty"`
 	// contains the reference to the NifiRegistry with the one the dataflow is linked.
 	RegistryClientRef *RegistryClientReference `json:"registryClientRef,omitempty"`
-	// describes the way the operator will deal with data when a dataflow will be updated : drop or drain
-	// +kubebuilder:validation:Enum={"drop","drain"}
+	// describes the way the operator will deal with data when a dataflow will be updated : drop, drain or blueGreen
+	// +kubebuilder:validation:Enum={"drop","drain","blueGreen"}
 	UpdateStrategy DataflowUpdateStrategy `json:"updateStrategy"`
+	// the duration the new process group is monitored before replacing the previous one with the blueGreen update strategy.
+	SoakPeriod *metav1.Duration `json:"soakPeriod,omitempty"`
+	// the maximum duration to wait for the dataflow to be drained with the drain update strategy, waits indefinitely if not set.
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+	// the action taken when the dataflow is not drained within the drain timeout : drop, abort or force
+	// +kubebuilder:validation:Enum={"drop","abort","force"}
+	DrainFallback DrainFallback `json:"drainFallback,omitempty"`
+	// the minimum severity of the bulletins emitted by the dataflow components, reported as events and into the status.
+	// +kubebuilder:validation:Enum={"WARN","ERROR","NONE"}
+	BulletinLevel BulletinLevel `json:"bulletinLevel,omitempty"`
+	// describes the way the operator will deal with the local changes of the dataflow : revert, report or commit
+	// +kubebuilder:validation:Enum={"revert","report","commit"}
+	LocalChangesPolicy LocalChangesPolicy `json:"localChangesPolicy,omitempty"`
+	// the window during which the dataflow runs, stopped outside of it.
+	Schedule *DataflowSchedule `json:"schedule,omitempty"`
+	// the processor configurations overriding the registry flow ones, which are not reverted as local changes.
+	ComponentOverrides []ComponentOverride `json:"componentOverrides,omitempty"`
+	// the processors and ports kept stopped or disabled when the dataflow is started.
+	ComponentStates []ComponentState `json:"componentStates,omitempty"`
+}
+
+type ComponentState struct {
+	// the type of the component : processor, inputPort or outputPort.
+	// +kubebuilder:validation:Enum={"processor","inputPort","outputPort"}
+	Type ComponentType `json:"type"`
+	// the UUID of the component, matched in place of its name path.
+	Id string `json:"id,omitempty"`
+	// the name path of the component from the dataflow process group, its name prefixed by the names of its parent
+	// process groups separated by a slash, e.g : "debug/LogAttribute".
+	NamePath string `json:"namePath,omitempty"`
+	// the state the component is kept in : STOPPED or DISABLED.
+	// +kubebuilder:validation:Enum={"STOPPED","DISABLED"}
+	State ComponentRunState `json:"state"`
+}
+
+type ComponentOverride struct {
+	// the UUID of the processor, matched in place of its name path.
+	Id string `json:"id,omitempty"`
+	// the name path of the processor from the dataflow process group, its name prefixed by the names of its parent
+	// process groups separated by a slash, e.g : "ingest/ConsumeKafka".
+	NamePath string `json:"namePath,omitempty"`
+	// the number of tasks the processor is scheduled to run concurrently.
+	ConcurrentTasks *int32 `json:"concurrentTasks,omitempty"`
+	// the run schedule of the processor : a time period (e.g "10 sec") or a cron expression.
+	SchedulingPeriod string `json:"schedulingPeriod,omitempty"`
+	// the scheduling strategy of the processor.
+	// +kubebuilder:validation:Enum={"TIMER_DRIVEN","CRON_DRIVEN","EVENT_DRIVEN"}
+	SchedulingStrategy string `json:"schedulingStrategy,omitempty"`
+	// the nodes the processor runs on.
+	// +kubebuilder:validation:Enum={"ALL","PRIMARY"}
+	ExecutionNode string `json:"executionNode,omitempty"`
+}
+
+const (
+	// ScheduleOverrideAnnotation forces the dataflow to run ("start") or to stop ("stop") whatever its schedule.
+	ScheduleOverrideAnnotation = "nifidataflows.nifi.orange.com/schedule-override"
+	// ScheduleStart defines the start of a dataflow schedule.
+	ScheduleStart = "start"
+	// ScheduleStop defines the stop of a dataflow schedule.
+	ScheduleStop = "stop"
+)
+
+type DataflowSchedule struct {
+	// the cron expression of the dataflow start times (e.g "0 20 * * *").
+	Start string `json:"start"`
+	// the cron expression of the dataflow stop times (e.g "0 6 * * *").
+	Stop string `json:"stop"`
+	// the IANA timezone in which the cron expressions are evaluated (e.g "Europe/Paris"), UTC by default, unless they
+	// start with their own CRON_TZ= prefix.
+	Timezone string `json:"timezone,omitempty"`
 }
 
 type FlowPosition struct {
@@ -116,6 +192,25 @@ type DropRequest struct {
 	State string `json:"state"`
 }
 
+type Bulletin struct {
+	// the id of the bulletin.
+	Id int64 `json:"id"`
+	// the level of the bulletin.
+	Level string `json:"level"`
+	// the id of the source component.
+	SourceId string `json:"sourceId"`
+	// the name of the source component.
+	SourceName string `json:"sourceName,omitempty"`
+	// the group id of the source component.
+	GroupId string `json:"groupId,omitempty"`
+	// the bulletin message.
+	Message string `json:"message"`
+	// when this bulletin was generated.
+	Timestamp string `json:"timestamp,omitempty"`
+	// if clustered, the address of the node from which the bulletin originated.
+	NodeAddress string `json:"nodeAddress,omitempty"`
+}
+
 // NifiDataflowStatus defines the observed state of NifiDataflow
 type NifiDataflowStatus struct {
 	// process Group ID
@@ -126,10 +221,130 @@ type NifiDataflowStatus struct {
 	LatestUpdateRequest *UpdateRequest `json:"latestUpdateRequest,omitempty"`
 	// the latest queue drop request sent.
 	LatestDropRequest *DropRequest `json:"latestDropRequest,omitempty"`
+	// the latest bulletins emitted by the dataflow components.
+	RecentBulletins []Bulletin `json:"recentBulletins,omitempty"`
+	// the id of the latest bulletin handled.
+	LastBulletinId int64 `json:"lastBulletinId,omitempty"`
+	// the runtime statistics of the dataflow.
+	FlowStatistics *FlowStatistics `json:"flowStatistics,omitempty"`
+	// the version of the flow deployed, which differs from the spec one when local changes have been committed.
+	FlowVersion *int32 `json:"flowVersion,omitempty"`
+	// the spec flow version on top of which the local changes have been committed.
+	CommittedFromVersion *int32 `json:"committedFromVersion,omitempty"`
+	// the components locally modified, reported with the report local changes policy.
+	LocalChanges []LocalChange `json:"localChanges,omitempty"`
+	// the components impacted by the latest parameter context change, stopped before the change and restored after.
+	ImpactedComponents []ImpactedComponent `json:"impactedComponents,omitempty"`
+	// the state of the dataflow schedule.
+	Schedule *DataflowScheduleStatus `json:"schedule,omitempty"`
+	// the progress of the current drain of the dataflow.
+	Drain *DrainStatus `json:"drain,omitempty"`
+	// the progress of the current blue/green update of the dataflow.
+	BlueGreen *BlueGreenStatus `json:"blueGreen,omitempty"`
+}
+
+type BlueGreenStatus struct {
+	// the UUID of the process group the new flow version is imported into.
+	ProcessGroupID string `json:"processGroupID,omitempty"`
+	// the new flow version.
+	FlowVersion int32 `json:"flowVersion"`
+	// the generation of the dataflow the update has been started for.
+	Generation int64 `json:"generation,omitempty"`
+	// the phase of the update.
+	Phase BlueGreenPhase `json:"phase"`
+	// the time the soak period started, in RFC 3339 format, the soak period is restarted when it can't be read.
+	SoakStartTime string `json:"soakStartTime,omitempty"`
+	// the reason of the rollback, if any.
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+type DrainStatus struct {
+	// the time the drain started, in RFC 3339 format.
+	StartTime string `json:"startTime"`
+	// the generation of the dataflow the drain has been started for.
+	Generation int64 `json:"generation,omitempty"`
+	// the number of flowfiles remaining in the dataflow queues.
+	RemainingFlowFiles int32 `json:"remainingFlowFiles"`
+	// whether the drain timeout has been reached.
+	TimedOut bool `json:"timedOut,omitempty"`
+}
+
+type DataflowScheduleStatus struct {
+	// whether the dataflow is inside its running window.
+	InWindow bool `json:"inWindow"`
+	// the override set through the schedule override annotation : start or stop.
+	Override string `json:"override,omitempty"`
+	// the time of the next scheduled start or stop, in RFC 3339 format.
+	NextTransitionTime string `json:"nextTransitionTime,omitempty"`
+	// the next scheduled transition : start or stop.
+	NextTransition string `json:"nextTransition,omitempty"`
+}
+
+type ImpactedComponent struct {
+	// the type of the component : PROCESSOR or CONTROLLER_SERVICE.
+	ComponentType string `json:"componentType"`
+	// the id of the component.
+	ComponentId string `json:"componentId"`
+	// the name of the component.
+	ComponentName string `json:"componentName,omitempty"`
+	// the id of the process group that the component belongs to.
+	ProcessGroupId string `json:"processGroupId,omitempty"`
+	// the changed parameters referenced by the component, empty if impacted through a controller service.
+	Parameters []string `json:"parameters,omitempty"`
+	// the state of the component before the parameter context change, to restore after it.
+	State string `json:"state,omitempty"`
+	// whether the component has been restored in its state after the parameter context change.
+	Restored bool `json:"restored,omitempty"`
+}
+
+type LocalChange struct {
+	// the type of the component.
+	ComponentType string `json:"componentType"`
+	// the id of the component.
+	ComponentId string `json:"componentId"`
+	// the name of the component.
+	ComponentName string `json:"componentName,omitempty"`
+	// the id of the process group that the component belongs to.
+	ProcessGroupId string `json:"processGroupId,omitempty"`
+	// the description of the differences with the deployed flow version.
+	Differences []string `json:"differences,omitempty"`
+}
+
+type FlowStatistics struct {
+	// the number of FlowFiles queued into the dataflow.
+	FlowFilesQueued int32 `json:"flowFilesQueued"`
+	// the number of bytes queued into the dataflow.
+	BytesQueued int64 `json:"bytesQueued"`
+	// the number of bytes that have come into the dataflow in the last 5 minutes.
+	BytesIn int64 `json:"bytesIn"`
+	// the number of bytes transferred out of the dataflow in the last 5 minutes.
+	BytesOut int64 `json:"bytesOut"`
+	// the number of active threads of the dataflow.
+	ActiveThreadCount int32 `json:"activeThreadCount"`
+	// the number of running components of the dataflow.
+	RunningCount int32 `json:"runningCount"`
+	// the number of stopped components of the dataflow.
+	StoppedCount int32 `json:"stoppedCount"`
+	// the number of invalid components of the dataflow.
+	InvalidCount int32 `json:"invalidCount"`
+	// the number of disabled components of the dataflow.
+	DisabledCount int32 `json:"disabledCount"`
+	// the time the statistics were last refreshed by NiFi.
+	LastRefreshed string `json:"lastRefreshed,omitempty"`
+	// the time the statistics were last stored into the status, they are stored at most every 5 minutes unless the
+	// component counts change.
+	UpdateTime string `json:"updateTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="The dataflow current state"
+// +kubebuilder:printcolumn:name="Queued",type="integer",JSONPath=".status.flowStatistics.flowFilesQueued",description="The number of FlowFiles queued into the dataflow"
+// +kubebuilder:printcolumn:name="Bytes Queued",type="integer",JSONPath=".status.flowStatistics.bytesQueued",description="The number of bytes queued into the dataflow"
+// +kubebuilder:printcolumn:name="Threads",type="integer",JSONPath=".status.flowStatistics.activeThreadCount",description="The number of active threads of the dataflow"
+// +kubebuilder:printcolumn:name="Invalid",type="integer",JSONPath=".status.flowStatistics.invalidCount",description="The number of invalid components of the dataflow",priority=1
+// +kubebuilder:printcolumn:name="Next Transition",type="string",JSONPath=".status.schedule.nextTransitionTime",description="The time of the next scheduled start or stop of the dataflow",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // NifiDataflow is the Schema for the nifidataflows API
 type NifiDataflow struct {
@@ -188,6 +403,65 @@ func (d *NifiDataflowSpec) GetParentProcessGroupID(rootProcessGroupId string) st
 	return d.ParentProcessGroupID
 }
 
+// IsAdoption returns whether the dataflow adopts an existing process group instead of importing the flow.
+func (d *NifiDataflowSpec) IsAdoption() bool {
+	return d.AdoptProcessGroupId != "" || d.AdoptProcessGroupName != ""
+}
+
+func (d *NifiDataflowSpec) GetBulletinLevel() BulletinLevel {
+	if d.BulletinLevel == "" {
+		return WarnBulletinLevel
+	}
+	return d.BulletinLevel
+}
+
+func (d *NifiDataflowSpec) GetDrainFallback() DrainFallback {
+	if d.DrainFallback == "" {
+		return AbortDrainFallback
+	}
+	return d.DrainFallback
+}
+
+func (d *NifiDataflowSpec) GetLocalChangesPolicy() LocalChangesPolicy {
+	if d.LocalChangesPolicy == "" {
+		return RevertLocalChangesPolicy
+	}
+	return d.LocalChangesPolicy
+}
+
+// GetSoakPeriod returns the time the new flow version of a blue/green update runs before being promoted, 5 minutes
+// by default.
+func (d *NifiDataflowSpec) GetSoakPeriod() time.Duration {
+	if d.SoakPeriod == nil {
+		return 5 * time.Minute
+	}
+	return d.SoakPeriod.Duration
+}
+
+// GetDesiredFlowVersion returns the flow version to deploy, which is the last committed one if the local changes
+// have been committed on top of the spec flow version.
+func (d *NifiDataflow) GetDesiredFlowVersion() int32 {
+	if d.Spec.GetLocalChangesPolicy() == CommitLocalChangesPolicy &&
+		d.Status.CommittedFromVersion != nil && d.Status.FlowVersion != nil &&
+		*d.Status.CommittedFromVersion == *d.Spec.FlowVersion {
+		return *d.Status.FlowVersion
+	}
+	return *d.Spec.FlowVersion
+}
+
+// IsUpdateAborted returns whether the update of the current dataflow generation has been aborted after a drain timeout
+// or rolled back.
+func (d *NifiDataflow) IsUpdateAborted() bool {
+	if d.Status.State != DataflowStateDegraded {
+		return false
+	}
+	if d.Status.Drain != nil && d.Status.Drain.Generation == d.Generation {
+		return true
+	}
+	return d.Status.BlueGreen != nil && d.Status.BlueGreen.Phase == BlueGreenPhaseRolledBack &&
+		d.Status.BlueGreen.Generation == d.Generation
+}
+
 func (p *FlowPosition) GetX() int64 {
 	if p.X == nil || *p.X == 0 {
 		return 1
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,11 +29,26 @@ type NifiParameterContextSpec struct {
 	// the Description of the Parameter Context.
 	Description string `json:"description,omitempty"`
 	// a list of non-sensitive Parameters.
-	Parameters []Parameter `json:"parameters"`
+	Parameters []Parameter `json:"parameters,omitempty"`
 	// contains the reference to the NifiCluster with the one the parameter context is linked.
 	ClusterRef ClusterReference `json:"clusterRef,omitempty"`
 	// a list of secret containing sensitive parameters (the key will name of the parameter).
 	SecretRefs []SecretReference `json:"secretRefs,omitempty"`
+	// a list of references to the parameter contexts this one inherits from, ordered from the highest precedence (requires NiFi 1.15+).
+	InheritedParameterContexts []ParameterContextReference `json:"inheritedParameterContexts,omitempty"`
+	// the parameter group of a NifiParameterProvider providing the parameters of the parameter context, instead of
+	// the parameters and secretRefs (requires NiFi 1.18+).
+	ParameterProvider *ParameterProviderSource `json:"parameterProvider,omitempty"`
+}
+
+// ParameterProviderSource represents the parameter group of a parameter provider providing the parameters of a parameter context.
+type ParameterProviderSource struct {
+	// contains the reference to the NifiParameterProvider fetching the parameter group.
+	ProviderRef ParameterProviderReference `json:"providerRef"`
+	// the name of the parameter group fetched by the parameter provider.
+	GroupName string `json:"groupName"`
+	// the names of the parameters of the group which are sensitive.
+	SensitiveParameters []string `json:"sensitiveParameters,omitempty"`
 }
 
 type Parameter struct {
@@ -44,6 +60,16 @@ type Parameter struct {
 	Description string `json:"description,omitempty"`
 	// Whether the parameter is sensitive or not.
 	Sensitive bool `json:"sensitive,omitempty"`
+	// the source of the value of the Parameter, used instead of the value.
+	ValueFrom *ParameterSource `json:"valueFrom,omitempty"`
+}
+
+// ParameterSource represents a source for the value of a Parameter.
+type ParameterSource struct {
+	// selects a key of a ConfigMap in the parameter context namespace.
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// selects a key of a Secret in the parameter context namespace.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
 // NifiParameterContextStatus defines the observed state of NifiParameterContext
@@ -54,6 +80,9 @@ type NifiParameterContextStatus struct {
 	Version int64 `json:"version"`
 	// the latest update request.
 	LatestUpdateRequest *ParameterContextUpdateRequest `json:"latestUpdateRequest,omitempty"`
+	// the hash of the sources of the sensitive parameter values last applied (the inline sensitive values and the
+	// resource versions of the ConfigMaps and Secrets), to detect their changes without storing their values.
+	SensitiveSourcesVersion string `json:"sensitiveSourcesVersion,omitempty"`
 }
 
 type ParameterContextUpdateRequest struct {
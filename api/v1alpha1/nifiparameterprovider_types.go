@@ -0,0 +1,130 @@
+/*
+Copyright 2020.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// NifiParameterProviderSpec defines the desired state of NifiParameterProvider
+type NifiParameterProviderSpec struct {
+	// the fully qualified type of the parameter provider (e.g org.apache.nifi.parameter.EnvironmentVariableParameterProvider).
+	Type string `json:"type"`
+	// the bundle providing the parameter provider type, required only if several bundles provide the same type.
+	Bundle *Bundle `json:"bundle,omitempty"`
+	// the properties of the parameter provider.
+	Properties map[string]string `json:"properties,omitempty"`
+	// the Description of the parameter provider.
+	Description string `json:"description,omitempty"`
+	// contains the reference to the NifiCluster with the one the parameter provider is linked.
+	ClusterRef ClusterReference `json:"clusterRef,omitempty"`
+	// the interval between two fetches of the parameters, which are otherwise only fetched when the spec changes.
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// Bundle identifies the NAR providing a component type.
+type Bundle struct {
+	// the group of the bundle.
+	Group string `json:"group"`
+	// the artifact of the bundle.
+	Artifact string `json:"artifact"`
+	// the version of the bundle.
+	Version string `json:"version"`
+}
+
+// NifiParameterProviderStatus defines the observed state of NifiParameterProvider
+type NifiParameterProviderStatus struct {
+	// the nifi parameter provider id.
+	Id string `json:"id"`
+	// the last nifi parameter provider revision version catched.
+	Version int64 `json:"version"`
+	// the parameter groups fetched by the parameter provider during the last fetch.
+	FetchedGroups []string `json:"fetchedGroups,omitempty"`
+	// the generation of the resource whose spec has been used for the last fetch.
+	FetchedGeneration int64 `json:"fetchedGeneration,omitempty"`
+	// the time of the last fetch of the parameters.
+	LastFetchTime string `json:"lastFetchTime,omitempty"`
+	// the parameter contexts provided by the parameter provider.
+	ParameterContexts []ProvidedParameterContext `json:"parameterContexts,omitempty"`
+	// the latest apply parameters request.
+	LatestApplyRequest *ParameterProviderApplyRequest `json:"latestApplyRequest,omitempty"`
+}
+
+// ProvidedParameterContext represents a NiFi parameter context whose parameters are provided by the parameter provider.
+type ProvidedParameterContext struct {
+	// the name of the parameter context.
+	Name string `json:"name"`
+	// the nifi parameter context id.
+	Id string `json:"id"`
+}
+
+type ParameterProviderApplyRequest struct {
+	// the id of the apply parameters request.
+	Id string `json:"id"`
+	// the uri for this request.
+	Uri string `json:"uri"`
+	// the timestamp of when the request was submitted This property is read only.
+	SubmissionTime string `json:"submissionTime"`
+	// the last time this request was updated.
+	LastUpdated string `json:"lastUpdated"`
+	// whether or not this request has completed.
+	Complete bool `json:"complete"`
+	// an explication of why the request failed, or null if this request has not failed.
+	FailureReason string `json:"failureReason"`
+	// the percentage complete of the request, between 0 and 100.
+	PercentCompleted int32 `json:"percentCompleted"`
+	// the state of the request.
+	State string `json:"state"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// NifiParameterProvider is the Schema for the nifiparameterproviders API
+type NifiParameterProvider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NifiParameterProviderSpec   `json:"spec,omitempty"`
+	Status NifiParameterProviderStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NifiParameterProviderList contains a list of NifiParameterProvider
+type NifiParameterProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NifiParameterProvider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NifiParameterProvider{}, &NifiParameterProviderList{})
+}
+
+// GetParameterContextId returns the id of the provided parameter context with the given name, empty if not provided yet.
+func (p *NifiParameterProvider) GetParameterContextId(name string) string {
+	for _, pc := range p.Status.ParameterContexts {
+		if pc.Name == name {
+			return pc.Id
+		}
+	}
+	return ""
+}
@@ -35,13 +35,33 @@ type NifiUserSpec struct {
 	// List of DNSNames that the user will used to request the NifiCluster (allowing to create the right certificates associated)
 	DNSNames []string `json:"dnsNames,omitempty"`
 	// Whether or not the the operator also include a Java keystore format (JKS) with you secret
+	// Deprecated: use keystoreFormats instead.
 	IncludeJKS bool `json:"includeJKS,omitempty"`
+	// keystoreFormats defines the keystore formats included with the PEM certificate and key into the secret,
+	// sharing the password stored under the "password" key.
+	KeystoreFormats []KeystoreFormat `json:"keystoreFormats,omitempty"`
+	// subject defines the organization fields of the certificate subject, its common name remaining the user name.
+	Subject *CertificateSubject `json:"subject,omitempty"`
+	// duration defines the requested lifetime of the certificate, not supported by the k8s-csr pki backend.
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// renewBefore defines how long before the certificate expiry it is renewed.
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
 	// Whether or not a certificate will be created for this user.
 	CreateCert *bool `json:"createCert,omitempty"`
 	// accessPolicies defines the list of access policies that will be granted to the group.
 	AccessPolicies []AccessPolicy `json:"accessPolicies,omitempty"`
 }
 
+// CertificateSubject defines the organization fields of a user certificate subject
+type CertificateSubject struct {
+	// Organizations to be used on the certificate.
+	Organizations []string `json:"organizations,omitempty"`
+	// Organizational units to be used on the certificate.
+	OrganizationalUnits []string `json:"organizationalUnits,omitempty"`
+	// Countries to be used on the certificate.
+	Countries []string `json:"countries,omitempty"`
+}
+
 // NifiUserStatus defines the observed state of NifiUser
 type NifiUserStatus struct {
 	// The nifi user's node id
@@ -82,6 +102,24 @@ func (u *NifiUserSpec) GetCreateCert() bool {
 	return true
 }
 
+// GetKeystoreFormats returns the keystore formats to include into the secret, JKS being included when includeJKS is set
+func (u *NifiUserSpec) GetKeystoreFormats() []KeystoreFormat {
+	if u.IncludeJKS && !u.HasKeystoreFormat(JKSKeystoreFormat) {
+		return append([]KeystoreFormat{JKSKeystoreFormat}, u.KeystoreFormats...)
+	}
+	return u.KeystoreFormats
+}
+
+// HasKeystoreFormat returns whether the keystore format is explicitly requested
+func (u *NifiUserSpec) HasKeystoreFormat(format KeystoreFormat) bool {
+	for _, f := range u.KeystoreFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
 func (u *NifiUser) GetIdentity() string {
 	if u.Spec.Identity == "" {
 		return u.Name
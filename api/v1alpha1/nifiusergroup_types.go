@@ -30,6 +30,10 @@ type NifiUserGroupSpec struct {
 	ClusterRef ClusterReference `json:"clusterRef"`
 	// userRef contains the list of reference to NifiUsers that are part to the group.
 	UsersRef []UserReference `json:"usersRef,omitempty"`
+	// usersSelector selects the NifiUsers that are part to the group in addition to usersRef, among the ones linked to
+	// the same cluster in the watched namespaces. The users no more selected are removed from the group, while the
+	// members added out of the operator are kept.
+	UsersSelector *metav1.LabelSelector `json:"usersSelector,omitempty"`
 	// accessPolicies defines the list of access policies that will be granted to the group.
 	AccessPolicies []AccessPolicy `json:"accessPolicies,omitempty"`
 }
@@ -40,6 +44,8 @@ type NifiUserGroupStatus struct {
 	Id string `json:"id"`
 	// The last nifi usergroup's node revision version catched
 	Version int64 `json:"version"`
+	// The nifi ids of the users added to the group by the users selector
+	SelectedUsers []string `json:"selectedUsers,omitempty"`
 }
 
 // +kubebuilder:object:root=true
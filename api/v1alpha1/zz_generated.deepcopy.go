@@ -23,12 +23,18 @@ package v1alpha1
 import (
 	metav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	"k8s.io/api/core/v1"
+	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AccessPolicy) DeepCopyInto(out *AccessPolicy) {
 	*out = *in
+	if in.ComponentRef != nil {
+		in, out := &in.ComponentRef, &out.ComponentRef
+		*out = new(ComponentReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessPolicy.
@@ -41,6 +47,36 @@ func (in *AccessPolicy) DeepCopy() *AccessPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessPolicyMember) DeepCopyInto(out *AccessPolicyMember) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessPolicyMember.
+func (in *AccessPolicyMember) DeepCopy() *AccessPolicyMember {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessPolicyMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreenStatus) DeepCopyInto(out *BlueGreenStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenStatus.
+func (in *BlueGreenStatus) DeepCopy() *BlueGreenStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreenStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootstrapNotificationServicesConfig) DeepCopyInto(out *BootstrapNotificationServicesConfig) {
 	*out = *in
@@ -91,6 +127,86 @@ func (in *BootstrapProperties) DeepCopy() *BootstrapProperties {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bulletin) DeepCopyInto(out *Bulletin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bulletin.
+func (in *Bulletin) DeepCopy() *Bulletin {
+	if in == nil {
+		return nil
+	}
+	out := new(Bulletin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bundle) DeepCopyInto(out *Bundle) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bundle.
+func (in *Bundle) DeepCopy() *Bundle {
+	if in == nil {
+		return nil
+	}
+	out := new(Bundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSRConfig) DeepCopyInto(out *CSRConfig) {
+	*out = *in
+	if in.CABundleRef != nil {
+		in, out := &in.CABundleRef, &out.CABundleRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSRConfig.
+func (in *CSRConfig) DeepCopy() *CSRConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CSRConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateSubject) DeepCopyInto(out *CertificateSubject) {
+	*out = *in
+	if in.Organizations != nil {
+		in, out := &in.Organizations, &out.Organizations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrganizationalUnits != nil {
+		in, out := &in.OrganizationalUnits, &out.OrganizationalUnits
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Countries != nil {
+		in, out := &in.Countries, &out.Countries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateSubject.
+func (in *CertificateSubject) DeepCopy() *CertificateSubject {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateSubject)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterReference) DeepCopyInto(out *ClusterReference) {
 	*out = *in
@@ -106,6 +222,56 @@ func (in *ClusterReference) DeepCopy() *ClusterReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentOverride) DeepCopyInto(out *ComponentOverride) {
+	*out = *in
+	if in.ConcurrentTasks != nil {
+		in, out := &in.ConcurrentTasks, &out.ConcurrentTasks
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentOverride.
+func (in *ComponentOverride) DeepCopy() *ComponentOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentReference) DeepCopyInto(out *ComponentReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentReference.
+func (in *ComponentReference) DeepCopy() *ComponentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentState) DeepCopyInto(out *ComponentState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentState.
+func (in *ComponentState) DeepCopy() *ComponentState {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigmapReference) DeepCopyInto(out *ConfigmapReference) {
 	*out = *in
@@ -121,6 +287,51 @@ func (in *ConfigmapReference) DeepCopy() *ConfigmapReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataflowSchedule) DeepCopyInto(out *DataflowSchedule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataflowSchedule.
+func (in *DataflowSchedule) DeepCopy() *DataflowSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(DataflowSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataflowScheduleStatus) DeepCopyInto(out *DataflowScheduleStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataflowScheduleStatus.
+func (in *DataflowScheduleStatus) DeepCopy() *DataflowScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataflowScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveredNode) DeepCopyInto(out *DiscoveredNode) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredNode.
+func (in *DiscoveredNode) DeepCopy() *DiscoveredNode {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveredNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DisruptionBudget) DeepCopyInto(out *DisruptionBudget) {
 	*out = *in
@@ -136,6 +347,21 @@ func (in *DisruptionBudget) DeepCopy() *DisruptionBudget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainStatus) DeepCopyInto(out *DrainStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainStatus.
+func (in *DrainStatus) DeepCopy() *DrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DropRequest) DeepCopyInto(out *DropRequest) {
 	*out = *in
@@ -229,6 +455,21 @@ func (in *FlowPosition) DeepCopy() *FlowPosition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlowStatistics) DeepCopyInto(out *FlowStatistics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlowStatistics.
+func (in *FlowStatistics) DeepCopy() *FlowStatistics {
+	if in == nil {
+		return nil
+	}
+	out := new(FlowStatistics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GracefulActionState) DeepCopyInto(out *GracefulActionState) {
 	*out = *in
@@ -244,6 +485,26 @@ func (in *GracefulActionState) DeepCopy() *GracefulActionState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImpactedComponent) DeepCopyInto(out *ImpactedComponent) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImpactedComponent.
+func (in *ImpactedComponent) DeepCopy() *ImpactedComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(ImpactedComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InternalListenerConfig) DeepCopyInto(out *InternalListenerConfig) {
 	*out = *in
@@ -299,6 +560,26 @@ func (in *ListenersConfig) DeepCopy() *ListenersConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalChange) DeepCopyInto(out *LocalChange) {
+	*out = *in
+	if in.Differences != nil {
+		in, out := &in.Differences, &out.Differences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalChange.
+func (in *LocalChange) DeepCopy() *LocalChange {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogbackConfig) DeepCopyInto(out *LogbackConfig) {
 	*out = *in
@@ -324,6 +605,70 @@ func (in *LogbackConfig) DeepCopy() *LogbackConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoginIdentityProvider) DeepCopyInto(out *LoginIdentityProvider) {
+	*out = *in
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make([]LoginIdentityProviderProperty, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoginIdentityProvider.
+func (in *LoginIdentityProvider) DeepCopy() *LoginIdentityProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(LoginIdentityProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoginIdentityProviderProperty) DeepCopyInto(out *LoginIdentityProviderProperty) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoginIdentityProviderProperty.
+func (in *LoginIdentityProviderProperty) DeepCopy() *LoginIdentityProviderProperty {
+	if in == nil {
+		return nil
+	}
+	out := new(LoginIdentityProviderProperty)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoginIdentityProvidersConfiguration) DeepCopyInto(out *LoginIdentityProvidersConfiguration) {
+	*out = *in
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]LoginIdentityProvider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoginIdentityProvidersConfiguration.
+func (in *LoginIdentityProvidersConfiguration) DeepCopy() *LoginIdentityProvidersConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(LoginIdentityProvidersConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagedUser) DeepCopyInto(out *ManagedUser) {
 	*out = *in
@@ -339,6 +684,122 @@ func (in *ManagedUser) DeepCopy() *ManagedUser {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NifiAccessPolicy) DeepCopyInto(out *NifiAccessPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiAccessPolicy.
+func (in *NifiAccessPolicy) DeepCopy() *NifiAccessPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NifiAccessPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NifiAccessPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NifiAccessPolicyList) DeepCopyInto(out *NifiAccessPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NifiAccessPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiAccessPolicyList.
+func (in *NifiAccessPolicyList) DeepCopy() *NifiAccessPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(NifiAccessPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NifiAccessPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NifiAccessPolicySpec) DeepCopyInto(out *NifiAccessPolicySpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	in.AccessPolicy.DeepCopyInto(&out.AccessPolicy)
+	if in.UsersRef != nil {
+		in, out := &in.UsersRef, &out.UsersRef
+		*out = make([]UserReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.UserGroupsRef != nil {
+		in, out := &in.UserGroupsRef, &out.UserGroupsRef
+		*out = make([]UserGroupReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiAccessPolicySpec.
+func (in *NifiAccessPolicySpec) DeepCopy() *NifiAccessPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NifiAccessPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NifiAccessPolicyStatus) DeepCopyInto(out *NifiAccessPolicyStatus) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]AccessPolicyMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.UserGroups != nil {
+		in, out := &in.UserGroups, &out.UserGroups
+		*out = make([]AccessPolicyMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiAccessPolicyStatus.
+func (in *NifiAccessPolicyStatus) DeepCopy() *NifiAccessPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NifiAccessPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NifiCluster) DeepCopyInto(out *NifiCluster) {
 	*out = *in
@@ -438,7 +899,19 @@ func (in *NifiClusterSpec) DeepCopyInto(out *NifiClusterSpec) {
 	}
 	out.DisruptionBudget = in.DisruptionBudget
 	out.LdapConfiguration = in.LdapConfiguration
+	in.SingleUserConfiguration.DeepCopyInto(&out.SingleUserConfiguration)
+	in.LoginIdentityProviders.DeepCopyInto(&out.LoginIdentityProviders)
 	out.NifiClusterTaskSpec = in.NifiClusterTaskSpec
+	out.NodesHealthCheck = in.NodesHealthCheck
+	in.TenantPruning.DeepCopyInto(&out.TenantPruning)
+	in.SensitiveProperties.DeepCopyInto(&out.SensitiveProperties)
+	if in.AdditionalTrustedCAs != nil {
+		in, out := &in.AdditionalTrustedCAs, &out.AdditionalTrustedCAs
+		*out = make([]TrustedCASource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ListenersConfig != nil {
 		in, out := &in.ListenersConfig, &out.ListenersConfig
 		*out = new(ListenersConfig)
@@ -477,11 +950,16 @@ func (in *NifiClusterStatus) DeepCopyInto(out *NifiClusterStatus) {
 		in, out := &in.NodesState, &out.NodesState
 		*out = make(map[string]NodeState, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 	out.RollingUpgrade = in.RollingUpgrade
 	out.PrometheusReportingTask = in.PrometheusReportingTask
+	if in.UnmanagedTenants != nil {
+		in, out := &in.UnmanagedTenants, &out.UnmanagedTenants
+		*out = new(UnmanagedTenantsStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiClusterStatus.
@@ -597,6 +1075,33 @@ func (in *NifiDataflowSpec) DeepCopyInto(out *NifiDataflowSpec) {
 		*out = new(RegistryClientReference)
 		**out = **in
 	}
+	if in.SoakPeriod != nil {
+		in, out := &in.SoakPeriod, &out.SoakPeriod
+		*out = new(apismetav1.Duration)
+		**out = **in
+	}
+	if in.DrainTimeout != nil {
+		in, out := &in.DrainTimeout, &out.DrainTimeout
+		*out = new(apismetav1.Duration)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(DataflowSchedule)
+		**out = **in
+	}
+	if in.ComponentOverrides != nil {
+		in, out := &in.ComponentOverrides, &out.ComponentOverrides
+		*out = make([]ComponentOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ComponentStates != nil {
+		in, out := &in.ComponentStates, &out.ComponentStates
+		*out = make([]ComponentState, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiDataflowSpec.
@@ -622,6 +1127,55 @@ func (in *NifiDataflowStatus) DeepCopyInto(out *NifiDataflowStatus) {
 		*out = new(DropRequest)
 		**out = **in
 	}
+	if in.RecentBulletins != nil {
+		in, out := &in.RecentBulletins, &out.RecentBulletins
+		*out = make([]Bulletin, len(*in))
+		copy(*out, *in)
+	}
+	if in.FlowStatistics != nil {
+		in, out := &in.FlowStatistics, &out.FlowStatistics
+		*out = new(FlowStatistics)
+		**out = **in
+	}
+	if in.FlowVersion != nil {
+		in, out := &in.FlowVersion, &out.FlowVersion
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CommittedFromVersion != nil {
+		in, out := &in.CommittedFromVersion, &out.CommittedFromVersion
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LocalChanges != nil {
+		in, out := &in.LocalChanges, &out.LocalChanges
+		*out = make([]LocalChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImpactedComponents != nil {
+		in, out := &in.ImpactedComponents, &out.ImpactedComponents
+		*out = make([]ImpactedComponent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(DataflowScheduleStatus)
+		**out = **in
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainStatus)
+		**out = **in
+	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(BlueGreenStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiDataflowStatus.
@@ -662,31 +1216,148 @@ func (in *NifiParameterContext) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NifiParameterContextList) DeepCopyInto(out *NifiParameterContextList) {
+func (in *NifiParameterContextList) DeepCopyInto(out *NifiParameterContextList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NifiParameterContext, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterContextList.
+func (in *NifiParameterContextList) DeepCopy() *NifiParameterContextList {
+	if in == nil {
+		return nil
+	}
+	out := new(NifiParameterContextList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NifiParameterContextList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NifiParameterContextSpec) DeepCopyInto(out *NifiParameterContextSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]Parameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.ClusterRef = in.ClusterRef
+	if in.SecretRefs != nil {
+		in, out := &in.SecretRefs, &out.SecretRefs
+		*out = make([]SecretReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.InheritedParameterContexts != nil {
+		in, out := &in.InheritedParameterContexts, &out.InheritedParameterContexts
+		*out = make([]ParameterContextReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ParameterProvider != nil {
+		in, out := &in.ParameterProvider, &out.ParameterProvider
+		*out = new(ParameterProviderSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterContextSpec.
+func (in *NifiParameterContextSpec) DeepCopy() *NifiParameterContextSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NifiParameterContextSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NifiParameterContextStatus) DeepCopyInto(out *NifiParameterContextStatus) {
+	*out = *in
+	if in.LatestUpdateRequest != nil {
+		in, out := &in.LatestUpdateRequest, &out.LatestUpdateRequest
+		*out = new(ParameterContextUpdateRequest)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterContextStatus.
+func (in *NifiParameterContextStatus) DeepCopy() *NifiParameterContextStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NifiParameterContextStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NifiParameterProvider) DeepCopyInto(out *NifiParameterProvider) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterProvider.
+func (in *NifiParameterProvider) DeepCopy() *NifiParameterProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(NifiParameterProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NifiParameterProvider) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NifiParameterProviderList) DeepCopyInto(out *NifiParameterProviderList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NifiParameterContext, len(*in))
+		*out = make([]NifiParameterProvider, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterContextList.
-func (in *NifiParameterContextList) DeepCopy() *NifiParameterContextList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterProviderList.
+func (in *NifiParameterProviderList) DeepCopy() *NifiParameterProviderList {
 	if in == nil {
 		return nil
 	}
-	out := new(NifiParameterContextList)
+	out := new(NifiParameterProviderList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NifiParameterContextList) DeepCopyObject() runtime.Object {
+func (in *NifiParameterProviderList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -694,49 +1365,64 @@ func (in *NifiParameterContextList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NifiParameterContextSpec) DeepCopyInto(out *NifiParameterContextSpec) {
+func (in *NifiParameterProviderSpec) DeepCopyInto(out *NifiParameterProviderSpec) {
 	*out = *in
-	if in.Parameters != nil {
-		in, out := &in.Parameters, &out.Parameters
-		*out = make([]Parameter, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Bundle != nil {
+		in, out := &in.Bundle, &out.Bundle
+		*out = new(Bundle)
+		**out = **in
+	}
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 	out.ClusterRef = in.ClusterRef
-	if in.SecretRefs != nil {
-		in, out := &in.SecretRefs, &out.SecretRefs
-		*out = make([]SecretReference, len(*in))
-		copy(*out, *in)
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(apismetav1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterContextSpec.
-func (in *NifiParameterContextSpec) DeepCopy() *NifiParameterContextSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterProviderSpec.
+func (in *NifiParameterProviderSpec) DeepCopy() *NifiParameterProviderSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NifiParameterContextSpec)
+	out := new(NifiParameterProviderSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NifiParameterContextStatus) DeepCopyInto(out *NifiParameterContextStatus) {
+func (in *NifiParameterProviderStatus) DeepCopyInto(out *NifiParameterProviderStatus) {
 	*out = *in
-	if in.LatestUpdateRequest != nil {
-		in, out := &in.LatestUpdateRequest, &out.LatestUpdateRequest
-		*out = new(ParameterContextUpdateRequest)
+	if in.FetchedGroups != nil {
+		in, out := &in.FetchedGroups, &out.FetchedGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ParameterContexts != nil {
+		in, out := &in.ParameterContexts, &out.ParameterContexts
+		*out = make([]ProvidedParameterContext, len(*in))
+		copy(*out, *in)
+	}
+	if in.LatestApplyRequest != nil {
+		in, out := &in.LatestApplyRequest, &out.LatestApplyRequest
+		*out = new(ParameterProviderApplyRequest)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterContextStatus.
-func (in *NifiParameterContextStatus) DeepCopy() *NifiParameterContextStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiParameterProviderStatus.
+func (in *NifiParameterProviderStatus) DeepCopy() *NifiParameterProviderStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NifiParameterContextStatus)
+	out := new(NifiParameterProviderStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -894,7 +1580,7 @@ func (in *NifiUserGroup) DeepCopyInto(out *NifiUserGroup) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiUserGroup.
@@ -956,10 +1642,17 @@ func (in *NifiUserGroupSpec) DeepCopyInto(out *NifiUserGroupSpec) {
 		*out = make([]UserReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.UsersSelector != nil {
+		in, out := &in.UsersSelector, &out.UsersSelector
+		*out = new(apismetav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AccessPolicies != nil {
 		in, out := &in.AccessPolicies, &out.AccessPolicies
 		*out = make([]AccessPolicy, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -976,6 +1669,11 @@ func (in *NifiUserGroupSpec) DeepCopy() *NifiUserGroupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NifiUserGroupStatus) DeepCopyInto(out *NifiUserGroupStatus) {
 	*out = *in
+	if in.SelectedUsers != nil {
+		in, out := &in.SelectedUsers, &out.SelectedUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NifiUserGroupStatus.
@@ -1029,6 +1727,26 @@ func (in *NifiUserSpec) DeepCopyInto(out *NifiUserSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.KeystoreFormats != nil {
+		in, out := &in.KeystoreFormats, &out.KeystoreFormats
+		*out = make([]KeystoreFormat, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subject != nil {
+		in, out := &in.Subject, &out.Subject
+		*out = new(CertificateSubject)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(apismetav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(apismetav1.Duration)
+		**out = **in
+	}
 	if in.CreateCert != nil {
 		in, out := &in.CreateCert, &out.CreateCert
 		*out = new(bool)
@@ -1037,7 +1755,9 @@ func (in *NifiUserSpec) DeepCopyInto(out *NifiUserSpec) {
 	if in.AccessPolicies != nil {
 		in, out := &in.AccessPolicies, &out.AccessPolicies
 		*out = make([]AccessPolicy, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -1164,10 +1884,40 @@ func (in *NodeConfig) DeepCopy() *NodeConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeHealthState) DeepCopyInto(out *NodeHealthState) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeHealthState.
+func (in *NodeHealthState) DeepCopy() *NodeHealthState {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeHealthState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeState) DeepCopyInto(out *NodeState) {
 	*out = *in
 	out.GracefulActionState = in.GracefulActionState
+	if in.DiscoveredNode != nil {
+		in, out := &in.DiscoveredNode, &out.DiscoveredNode
+		*out = new(DiscoveredNode)
+		**out = **in
+	}
+	if in.NodeHealth != nil {
+		in, out := &in.NodeHealth, &out.NodeHealth
+		*out = new(NodeHealthState)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeState.
@@ -1180,6 +1930,21 @@ func (in *NodeState) DeepCopy() *NodeState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodesHealthCheckSpec) DeepCopyInto(out *NodesHealthCheckSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodesHealthCheckSpec.
+func (in *NodesHealthCheckSpec) DeepCopy() *NodesHealthCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodesHealthCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Parameter) DeepCopyInto(out *Parameter) {
 	*out = *in
@@ -1188,6 +1953,11 @@ func (in *Parameter) DeepCopyInto(out *Parameter) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(ParameterSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Parameter.
@@ -1230,6 +2000,82 @@ func (in *ParameterContextUpdateRequest) DeepCopy() *ParameterContextUpdateReque
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterProviderApplyRequest) DeepCopyInto(out *ParameterProviderApplyRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterProviderApplyRequest.
+func (in *ParameterProviderApplyRequest) DeepCopy() *ParameterProviderApplyRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterProviderApplyRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterProviderReference) DeepCopyInto(out *ParameterProviderReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterProviderReference.
+func (in *ParameterProviderReference) DeepCopy() *ParameterProviderReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterProviderReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterProviderSource) DeepCopyInto(out *ParameterProviderSource) {
+	*out = *in
+	out.ProviderRef = in.ProviderRef
+	if in.SensitiveParameters != nil {
+		in, out := &in.SensitiveParameters, &out.SensitiveParameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterProviderSource.
+func (in *ParameterProviderSource) DeepCopy() *ParameterProviderSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterProviderSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterSource) DeepCopyInto(out *ParameterSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterSource.
+func (in *ParameterSource) DeepCopy() *ParameterSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodPolicy) DeepCopyInto(out *PodPolicy) {
 	*out = *in
@@ -1282,6 +2128,21 @@ func (in *PrometheusReportingTaskStatus) DeepCopy() *PrometheusReportingTaskStat
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvidedParameterContext) DeepCopyInto(out *ProvidedParameterContext) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvidedParameterContext.
+func (in *ProvidedParameterContext) DeepCopy() *ProvidedParameterContext {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvidedParameterContext)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReadOnlyConfig) DeepCopyInto(out *ReadOnlyConfig) {
 	*out = *in
@@ -1302,6 +2163,11 @@ func (in *ReadOnlyConfig) DeepCopyInto(out *ReadOnlyConfig) {
 	in.BootstrapProperties.DeepCopyInto(&out.BootstrapProperties)
 	in.LogbackConfig.DeepCopyInto(&out.LogbackConfig)
 	in.BootstrapNotificationServicesReplaceConfig.DeepCopyInto(&out.BootstrapNotificationServicesReplaceConfig)
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(RepositoryEncryptionConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadOnlyConfig.
@@ -1329,6 +2195,23 @@ func (in *RegistryClientReference) DeepCopy() *RegistryClientReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryEncryptionConfig) DeepCopyInto(out *RepositoryEncryptionConfig) {
+	*out = *in
+	in.KeystoreSecretRef.DeepCopyInto(&out.KeystoreSecretRef)
+	in.KeystorePasswordSecretRef.DeepCopyInto(&out.KeystorePasswordSecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryEncryptionConfig.
+func (in *RepositoryEncryptionConfig) DeepCopy() *RepositoryEncryptionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryEncryptionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RollingUpgradeStatus) DeepCopyInto(out *RollingUpgradeStatus) {
 	*out = *in
@@ -1352,6 +2235,11 @@ func (in *SSLSecrets) DeepCopyInto(out *SSLSecrets) {
 		*out = new(metav1.ObjectReference)
 		**out = **in
 	}
+	if in.CSR != nil {
+		in, out := &in.CSR, &out.CSR
+		*out = new(CSRConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSLSecrets.
@@ -1394,6 +2282,26 @@ func (in *SecretReference) DeepCopy() *SecretReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SensitivePropertiesSpec) DeepCopyInto(out *SensitivePropertiesSpec) {
+	*out = *in
+	if in.KeySecretRef != nil {
+		in, out := &in.KeySecretRef, &out.KeySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SensitivePropertiesSpec.
+func (in *SensitivePropertiesSpec) DeepCopy() *SensitivePropertiesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SensitivePropertiesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServicePolicy) DeepCopyInto(out *ServicePolicy) {
 	*out = *in
@@ -1416,6 +2324,26 @@ func (in *ServicePolicy) DeepCopy() *ServicePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SingleUserConfiguration) DeepCopyInto(out *SingleUserConfiguration) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SingleUserConfiguration.
+func (in *SingleUserConfiguration) DeepCopy() *SingleUserConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(SingleUserConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageConfig) DeepCopyInto(out *StorageConfig) {
 	*out = *in
@@ -1436,6 +2364,81 @@ func (in *StorageConfig) DeepCopy() *StorageConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantPruningSpec) DeepCopyInto(out *TenantPruningSpec) {
+	*out = *in
+	if in.Allowlist != nil {
+		in, out := &in.Allowlist, &out.Allowlist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantPruningSpec.
+func (in *TenantPruningSpec) DeepCopy() *TenantPruningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantPruningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedCASource) DeepCopyInto(out *TrustedCASource) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedCASource.
+func (in *TrustedCASource) DeepCopy() *TrustedCASource {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedCASource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnmanagedTenantsStatus) DeepCopyInto(out *UnmanagedTenantsStatus) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UserGroups != nil {
+		in, out := &in.UserGroups, &out.UserGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AccessPolicies != nil {
+		in, out := &in.AccessPolicies, &out.AccessPolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnmanagedTenantsStatus.
+func (in *UnmanagedTenantsStatus) DeepCopy() *UnmanagedTenantsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UnmanagedTenantsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpdateRequest) DeepCopyInto(out *UpdateRequest) {
 	*out = *in
@@ -1451,6 +2454,21 @@ func (in *UpdateRequest) DeepCopy() *UpdateRequest {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserGroupReference) DeepCopyInto(out *UserGroupReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserGroupReference.
+func (in *UserGroupReference) DeepCopy() *UserGroupReference {
+	if in == nil {
+		return nil
+	}
+	out := new(UserGroupReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserReference) DeepCopyInto(out *UserReference) {
 	*out = *in
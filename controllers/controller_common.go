@@ -15,15 +15,24 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
 	"time"
 
 	"emperror.dev/errors"
 	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/accesspolicies"
 	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/k8sutil"
 	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -150,6 +159,17 @@ func GetParameterContextRefNamespace(ns string, ref v1alpha1.ParameterContextRef
 	return parameterContextNamespace
 }
 
+// GetParameterProviderRefNamespace returns the expected namespace for a Nifi parameter provider
+// referenced by a parameter context CR. It takes the namespace of the CR as the first
+// argument and the reference itself as the second.
+func GetParameterProviderRefNamespace(ns string, ref v1alpha1.ParameterProviderReference) string {
+	parameterProviderNamespace := ref.Namespace
+	if parameterProviderNamespace == "" {
+		return ns
+	}
+	return parameterProviderNamespace
+}
+
 // GetSecretRefNamespace returns the expected namespace for a Nifi secret
 // referenced by a parameter context CR. It takes the namespace of the CR as the first
 // argument and the reference itself as the second.
@@ -171,3 +191,178 @@ func GetUserRefNamespace(ns string, ref v1alpha1.UserReference) string {
 	}
 	return userNamespace
 }
+
+// GetComponentRefNamespace returns the expected namespace for a NifiDataflow, NifiParameterContext or
+// NifiRegistryClient referenced by an access policy. It takes the namespace of the CR as the first
+// argument and the reference itself as the second.
+func GetComponentRefNamespace(ns string, ref v1alpha1.ComponentReference) string {
+	componentNamespace := ref.Namespace
+	if componentNamespace == "" {
+		return ns
+	}
+	return componentNamespace
+}
+
+// IsComponentReferenced returns whether one of the access policies, defined in the given namespace, references the
+// resource of the given kind, name and namespace.
+func IsComponentReferenced(accessPolicies []v1alpha1.AccessPolicy, ns string,
+	kind v1alpha1.ComponentReferenceKind, name, namespace string) bool {
+
+	for _, accessPolicy := range accessPolicies {
+		ref := accessPolicy.ComponentRef
+		if ref != nil && ref.Kind == kind && ref.Name == name && GetComponentRefNamespace(ns, *ref) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// componentReferenceKind returns the kind of component reference matching the given object, if any.
+func componentReferenceKind(obj client.Object) (v1alpha1.ComponentReferenceKind, bool) {
+	switch obj.(type) {
+	case *v1alpha1.NifiDataflow:
+		return v1alpha1.DataflowComponentReferenceKind, true
+	case *v1alpha1.NifiParameterContext:
+		return v1alpha1.ParameterContextComponentReferenceKind, true
+	case *v1alpha1.NifiRegistryClient:
+		return v1alpha1.RegistryClientComponentReferenceKind, true
+	}
+	return "", false
+}
+
+// componentReferenceId returns the NiFi id of the component referenced by access policies.
+func componentReferenceId(obj client.Object) string {
+	switch component := obj.(type) {
+	case *v1alpha1.NifiDataflow:
+		return component.Status.ProcessGroupID
+	case *v1alpha1.NifiParameterContext:
+		return component.Status.Id
+	case *v1alpha1.NifiRegistryClient:
+		return component.Status.Id
+	}
+	return ""
+}
+
+// componentReferenceIdChangedPredicate filters the updates of the components referenced by access policies to the
+// changes of their NiFi id, the only field of them the access policies depend on.
+var componentReferenceIdChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return componentReferenceId(e.ObjectOld) != componentReferenceId(e.ObjectNew)
+	},
+}
+
+// componentReferenceIdChanged is the option of the watches of the components referenced by access policies.
+var componentReferenceIdChanged = builder.WithPredicates(componentReferenceIdChangedPredicate)
+
+// GetUserGroupRefNamespace returns the expected namespace for a Nifi user group
+// referenced by an access policy CR. It takes the namespace of the CR as the first
+// argument and the reference itself as the second.
+func GetUserGroupRefNamespace(ns string, ref v1alpha1.UserGroupReference) string {
+	userGroupNamespace := ref.Namespace
+	if userGroupNamespace == "" {
+		return ns
+	}
+	return userGroupNamespace
+}
+
+// IsUserBound returns whether the access policy grants the given user.
+func IsUserBound(accessPolicy *v1alpha1.NifiAccessPolicy, name, namespace string) bool {
+	for _, ref := range accessPolicy.Spec.UsersRef {
+		if ref.Name == name && GetUserRefNamespace(accessPolicy.Namespace, ref) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUserGroupBound returns whether the access policy grants the given user group.
+func IsUserGroupBound(accessPolicy *v1alpha1.NifiAccessPolicy, name, namespace string) bool {
+	for _, ref := range accessPolicy.Spec.UserGroupsRef {
+		if ref.Name == name && GetUserGroupRefNamespace(accessPolicy.Namespace, ref) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBoundAccessPolicies returns the resolved access policies of the NifiAccessPolicies linked to the given cluster and
+// granting the user or user group matched by isBound. The NifiAccessPolicies in conflict, or whose referenced component
+// is not deployed yet, are not managed and ignored.
+func GetBoundAccessPolicies(c client.Client, clusterRef v1alpha1.ClusterReference,
+	isBound func(accessPolicy *v1alpha1.NifiAccessPolicy) bool) ([]v1alpha1.AccessPolicy, error) {
+
+	accessPolicyList := &v1alpha1.NifiAccessPolicyList{}
+	if err := c.List(context.TODO(), accessPolicyList); err != nil {
+		return nil, err
+	}
+
+	var accessPolicies []v1alpha1.AccessPolicy
+	for i := range accessPolicyList.Items {
+		ap := &accessPolicyList.Items[i]
+		if k8sutil.IsMarkedForDeletion(ap.ObjectMeta) || len(ap.Status.Conflicts) > 0 || !isBound(ap) ||
+			ap.Spec.ClusterRef.Name != clusterRef.Name ||
+			GetClusterRefNamespace(ap.Namespace, ap.Spec.ClusterRef) != clusterRef.Namespace {
+			continue
+		}
+
+		resolved, err := accesspolicies.ResolveAccessPolicies(c, []v1alpha1.AccessPolicy{ap.Spec.AccessPolicy}, ap.Namespace)
+		if err != nil {
+			if _, ok := errors.Cause(err).(errorfactory.ResourceNotReady); ok {
+				continue
+			}
+			return nil, err
+		}
+		accessPolicies = append(accessPolicies, resolved...)
+	}
+	return accessPolicies, nil
+}
+
+// IsUserSelected returns whether the user is selected by the users selector of the user group. Only the users linked
+// to the same cluster can be selected, whatever the watched namespace they are deployed in.
+func IsUserSelected(userGroup *v1alpha1.NifiUserGroup, user *v1alpha1.NifiUser) (bool, error) {
+	if userGroup.Spec.UsersSelector == nil {
+		return false, nil
+	}
+
+	clusterNamespace := GetClusterRefNamespace(userGroup.Namespace, userGroup.Spec.ClusterRef)
+	if user.Spec.ClusterRef.Name != userGroup.Spec.ClusterRef.Name ||
+		GetClusterRefNamespace(user.Namespace, user.Spec.ClusterRef) != clusterNamespace {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(userGroup.Spec.UsersSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(user.GetLabels())), nil
+}
+
+// GetSelectedUsers returns the users selected by the users selector of the user group. The users not created on the
+// NiFi cluster yet, or marked for deletion, are ignored until they are.
+func GetSelectedUsers(c client.Client, userGroup *v1alpha1.NifiUserGroup) ([]*v1alpha1.NifiUser, error) {
+	if userGroup.Spec.UsersSelector == nil {
+		return nil, nil
+	}
+
+	userList := &v1alpha1.NifiUserList{}
+	if err := c.List(context.TODO(), userList); err != nil {
+		return nil, err
+	}
+
+	var users []*v1alpha1.NifiUser
+	for i := range userList.Items {
+		user := &userList.Items[i]
+		if user.Status.Id == "" || k8sutil.IsMarkedForDeletion(user.ObjectMeta) {
+			continue
+		}
+
+		selected, err := IsUserSelected(userGroup, user)
+		if err != nil {
+			return nil, err
+		}
+		if selected {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
@@ -23,6 +23,9 @@ import (
 
 	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
 	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 var log = ctrl.Log.WithName("controller_testing")
@@ -195,3 +198,123 @@ func TestApplyClusterRefLabel(t *testing.T) {
 		t.Error("Expected:", labels, "Got:", newLabels)
 	}
 }
+
+func TestIsComponentReferenced(t *testing.T) {
+	accessPolicies := []v1alpha1.AccessPolicy{
+		{Type: v1alpha1.GlobalAccessPolicyType, Action: v1alpha1.ReadAccessPolicyAction, Resource: v1alpha1.FlowAccessPolicyResource},
+		{
+			Type:     v1alpha1.ComponentAccessPolicyType,
+			Action:   v1alpha1.WriteAccessPolicyAction,
+			Resource: v1alpha1.ComponentsAccessPolicyResource,
+			ComponentRef: &v1alpha1.ComponentReference{
+				Kind: v1alpha1.DataflowComponentReferenceKind,
+				Name: "dataflow",
+			},
+		},
+	}
+
+	if !IsComponentReferenced(accessPolicies, "test-namespace", v1alpha1.DataflowComponentReferenceKind, "dataflow", "test-namespace") {
+		t.Error("Expected the dataflow to be referenced in the access policies namespace")
+	}
+	if IsComponentReferenced(accessPolicies, "test-namespace", v1alpha1.DataflowComponentReferenceKind, "dataflow", "other-namespace") {
+		t.Error("Expected the dataflow not to be referenced in another namespace")
+	}
+	if IsComponentReferenced(accessPolicies, "test-namespace", v1alpha1.ParameterContextComponentReferenceKind, "dataflow", "test-namespace") {
+		t.Error("Expected the parameter context not to be referenced")
+	}
+}
+
+func TestComponentReferenceIdChangedPredicate(t *testing.T) {
+	dataflow := &v1alpha1.NifiDataflow{}
+	dataflow.Status.ProcessGroupID = "pg"
+	parameterContext := &v1alpha1.NifiParameterContext{}
+	parameterContext.Status.Id = "pc"
+	registryClient := &v1alpha1.NifiRegistryClient{}
+	registryClient.Status.Id = "rc"
+
+	for obj, id := range map[client.Object]string{dataflow: "pg", parameterContext: "pc", registryClient: "rc", &v1alpha1.NifiUser{}: ""} {
+		if componentId := componentReferenceId(obj); componentId != id {
+			t.Errorf("Expected component id %q, got: %q", id, componentId)
+		}
+	}
+
+	// the other changes of the referenced component are filtered out
+	updated := dataflow.DeepCopy()
+	updated.Generation = 2
+	updated.Status.State = v1alpha1.DataflowStateRan
+	if componentReferenceIdChangedPredicate.Update(event.UpdateEvent{ObjectOld: dataflow, ObjectNew: updated}) {
+		t.Error("Expected the update without id change to be filtered out")
+	}
+
+	updated.Status.ProcessGroupID = "new-pg"
+	if !componentReferenceIdChangedPredicate.Update(event.UpdateEvent{ObjectOld: dataflow, ObjectNew: updated}) {
+		t.Error("Expected the id change to be kept")
+	}
+
+	if !componentReferenceIdChangedPredicate.Create(event.CreateEvent{Object: dataflow}) ||
+		!componentReferenceIdChangedPredicate.Delete(event.DeleteEvent{Object: dataflow}) {
+		t.Error("Expected the creations and deletions to be kept")
+	}
+}
+
+func TestIsUserBound(t *testing.T) {
+	accessPolicy := &v1alpha1.NifiAccessPolicy{}
+	accessPolicy.Namespace = "test-namespace"
+	accessPolicy.Spec.UsersRef = []v1alpha1.UserReference{{Name: "user"}, {Name: "other-user", Namespace: "other-namespace"}}
+	accessPolicy.Spec.UserGroupsRef = []v1alpha1.UserGroupReference{{Name: "group"}}
+
+	if !IsUserBound(accessPolicy, "user", "test-namespace") {
+		t.Error("Expected the user to be bound in the access policy namespace")
+	}
+	if !IsUserBound(accessPolicy, "other-user", "other-namespace") {
+		t.Error("Expected the user to be bound in its namespace")
+	}
+	if IsUserBound(accessPolicy, "group", "test-namespace") {
+		t.Error("Expected the user group not to be bound as a user")
+	}
+	if !IsUserGroupBound(accessPolicy, "group", "test-namespace") {
+		t.Error("Expected the user group to be bound")
+	}
+}
+
+func TestIsUserSelected(t *testing.T) {
+	userGroup := &v1alpha1.NifiUserGroup{}
+	userGroup.Namespace = "test-namespace"
+	userGroup.Spec.ClusterRef = v1alpha1.ClusterReference{Name: "test-nifi"}
+
+	user := &v1alpha1.NifiUser{}
+	user.Namespace = "test-namespace"
+	user.Labels = map[string]string{"team": "data"}
+	user.Spec.ClusterRef = v1alpha1.ClusterReference{Name: "test-nifi"}
+
+	if selected, _ := IsUserSelected(userGroup, user); selected {
+		t.Error("Expected the user not to be selected without users selector")
+	}
+
+	userGroup.Spec.UsersSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "data"}}
+	if selected, err := IsUserSelected(userGroup, user); err != nil || !selected {
+		t.Error("Expected the user to be selected by its labels")
+	}
+
+	user.Labels = map[string]string{"team": "ops"}
+	if selected, _ := IsUserSelected(userGroup, user); selected {
+		t.Error("Expected the relabelled user not to be selected")
+	}
+
+	user.Labels = map[string]string{"team": "data"}
+	user.Spec.ClusterRef = v1alpha1.ClusterReference{Name: "other-nifi"}
+	if selected, _ := IsUserSelected(userGroup, user); selected {
+		t.Error("Expected the user linked to another cluster not to be selected")
+	}
+
+	user.Spec.ClusterRef = v1alpha1.ClusterReference{Name: "test-nifi", Namespace: "test-namespace"}
+	user.Namespace = "other-namespace"
+	if selected, err := IsUserSelected(userGroup, user); err != nil || !selected {
+		t.Error("Expected the user deployed in another namespace to be selected")
+	}
+
+	user.Spec.ClusterRef = v1alpha1.ClusterReference{Name: "test-nifi"}
+	if selected, _ := IsUserSelected(userGroup, user); selected {
+		t.Error("Expected the user linked to the cluster of another namespace not to be selected")
+	}
+}
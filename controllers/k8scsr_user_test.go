@@ -0,0 +1,201 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/pki/k8scsrpki"
+	certutil "github.com/Orange-OpenSource/nifikop/pkg/util/cert"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	certv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// The k8s-csr pki backend is run against the API server of the test environment, so the cluster scoped
+// CertificateSigningRequests are validated and approved as they would be on a kubernetes cluster.
+var _ = Describe("k8s-csr pki backend", func() {
+	const signerName = "example.com/nifi"
+	ctx := context.Background()
+
+	var (
+		clientset *kubernetes.Clientset
+		manager   k8scsrpki.K8sCSR
+		user      *v1alpha1.NifiUser
+		ca        *x509.Certificate
+		caKey     interface{}
+	)
+
+	getCSR := func() (*certv1.CertificateSigningRequest, error) {
+		csr := &certv1.CertificateSigningRequest{}
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: user.Namespace + "-" + user.Name}, csr)
+		return csr, err
+	}
+
+	reconcile := func() error {
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.Name, Namespace: user.Namespace}, user)).To(Succeed())
+		_, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+		return err
+	}
+
+	approve := func(conditionType certv1.RequestConditionType) *certv1.CertificateSigningRequest {
+		csr, err := getCSR()
+		Expect(err).NotTo(HaveOccurred())
+		csr.Status.Conditions = append(csr.Status.Conditions, certv1.CertificateSigningRequestCondition{
+			Type:    conditionType,
+			Status:  corev1.ConditionTrue,
+			Reason:  "Test",
+			Message: "set by test",
+		})
+		csr, err = clientset.CertificatesV1().CertificateSigningRequests().UpdateApproval(ctx, csr.Name, csr, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return csr
+	}
+
+	BeforeEach(func() {
+		var err error
+		clientset, err = kubernetes.NewForConfig(cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		caPEM, caKeyPEM, _, err := certutil.GenerateTestCert()
+		Expect(err).NotTo(HaveOccurred())
+		ca, err = certutil.DecodeCertificate(caPEM)
+		Expect(err).NotTo(HaveOccurred())
+		block, _ := pem.Decode(caKeyPEM)
+		caKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		Expect(err).NotTo(HaveOccurred())
+
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "k8scsr-"}}
+		Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+
+		caBundle := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "signer-ca", Namespace: namespace.Name},
+			Data:       map[string]string{v1alpha1.CoreCACertKey: string(caPEM)},
+		}
+		Expect(k8sClient.Create(ctx, caBundle)).To(Succeed())
+
+		cluster := &v1alpha1.NifiCluster{}
+		cluster.Name = "nifi"
+		cluster.Namespace = namespace.Name
+		cluster.Spec.ListenersConfig = &v1alpha1.ListenersConfig{
+			SSLSecrets: &v1alpha1.SSLSecrets{
+				PKIBackend: v1alpha1.PKIBackendK8sCSR,
+				CSR: &v1alpha1.CSRConfig{
+					SignerName: signerName,
+					CABundleRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: caBundle.Name},
+						Key:                  v1alpha1.CoreCACertKey,
+					},
+				},
+			},
+		}
+		manager = k8scsrpki.New(k8sClient, cluster)
+
+		user = &v1alpha1.NifiUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: namespace.Name},
+			Spec: v1alpha1.NifiUserSpec{
+				ClusterRef: v1alpha1.ClusterReference{Name: cluster.Name},
+				SecretName: "user-secret",
+				DNSNames:   []string{"user.example.com"},
+				IncludeJKS: true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, user)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(manager.FinalizeUserCertificate(ctx, user)).To(Succeed())
+	})
+
+	It("issues the user certificate once the request is approved", func() {
+		err := reconcile()
+		Expect(err).To(BeAssignableToTypeOf(errorfactory.ResourceNotReady{}))
+
+		csr, err := getCSR()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(csr.Spec.SignerName).To(Equal(signerName))
+		Expect(csr.Spec.Usages).To(ContainElements(certv1.UsageClientAuth, certv1.UsageServerAuth))
+
+		csr = approve(certv1.CertificateApproved)
+		block, _ := pem.Decode(csr.Spec.Request)
+		request, err := x509.ParseCertificateRequest(block.Bytes)
+		Expect(err).NotTo(HaveOccurred())
+		template := x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      request.Subject,
+			DNSNames:     request.DNSNames,
+			URIs:         request.URIs,
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(24 * time.Hour),
+			KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, &template, ca, request.PublicKey, caKey)
+		Expect(err).NotTo(HaveOccurred())
+		csr.Status.Certificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		Expect(k8sClient.Status().Update(ctx, csr)).To(Succeed())
+
+		err = reconcile()
+		Expect(err).NotTo(HaveOccurred())
+
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: user.Spec.SecretName, Namespace: user.Namespace}, secret)).To(Succeed())
+		for _, key := range certutil.UserSecretKeys(user) {
+			Expect(secret.Data).To(HaveKey(key))
+		}
+		Expect(metav1.IsControlledBy(secret, user)).To(BeTrue())
+
+		_, err = getCSR()
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("keeps a denied request until the user spec changes", func() {
+		err := reconcile()
+		Expect(err).To(BeAssignableToTypeOf(errorfactory.ResourceNotReady{}))
+		approve(certv1.CertificateDenied)
+
+		for i := 0; i < 2; i++ {
+			err = reconcile()
+			Expect(err).To(BeAssignableToTypeOf(errorfactory.FatalReconcileError{}))
+			csr, err := getCSR()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(csr.Status.Conditions).NotTo(BeEmpty())
+		}
+
+		user.Spec.RenewBefore = &metav1.Duration{Duration: time.Hour}
+		Expect(k8sClient.Update(ctx, user)).To(Succeed())
+
+		err = reconcile()
+		Expect(err).To(BeAssignableToTypeOf(errorfactory.ResourceNotReady{}))
+		err = reconcile()
+		Expect(err).To(BeAssignableToTypeOf(errorfactory.ResourceNotReady{}))
+		csr, err := getCSR()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(csr.Status.Conditions).To(BeEmpty())
+	})
+})
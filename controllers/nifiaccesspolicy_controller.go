@@ -0,0 +1,533 @@
+/*
+Copyright 2020.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"emperror.dev/errors"
+	"encoding/json"
+	"fmt"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/accesspolicies"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/k8sutil"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient/config"
+	"github.com/Orange-OpenSource/nifikop/pkg/util"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+)
+
+var accessPolicyFinalizer = "nifiaccesspolicies.nifi.orange.com/finalizer"
+
+// NifiAccessPolicyReconciler reconciles a NifiAccessPolicy object
+type NifiAccessPolicyReconciler struct {
+	client.Client
+	Log             logr.Logger
+	Scheme          *runtime.Scheme
+	Recorder        record.EventRecorder
+	RequeueInterval int
+	RequeueOffset   int
+}
+
+// +kubebuilder:rbac:groups=nifi.orange.com,resources=nifiaccesspolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nifi.orange.com,resources=nifiaccesspolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nifi.orange.com,resources=nifiaccesspolicies/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
+func (r *NifiAccessPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = r.Log.WithValues("nifiaccesspolicy", req.NamespacedName)
+	interval := util.GetRequeueInterval(r.RequeueInterval, r.RequeueOffset)
+	var err error
+
+	// Fetch the NifiAccessPolicy instance
+	instance := &v1alpha1.NifiAccessPolicy{}
+	if err = r.Client.Get(ctx, req.NamespacedName, instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			return Reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return RequeueWithError(r.Log, err.Error(), err)
+	}
+
+	// Get the last configuration viewed by the operator.
+	o, err := patch.DefaultAnnotator.GetOriginalConfiguration(instance)
+	// Create it if not exist.
+	if o == nil {
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(instance); err != nil {
+			return RequeueWithError(r.Log, "could not apply last state to annotation", err)
+		}
+		if err := r.Client.Update(ctx, instance); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiAccessPolicy", err)
+		}
+		o, err = patch.DefaultAnnotator.GetOriginalConfiguration(instance)
+	}
+
+	// Check if the cluster reference changed.
+	original := &v1alpha1.NifiAccessPolicy{}
+	current := instance.DeepCopy()
+	json.Unmarshal(o, original)
+	if !v1alpha1.ClusterRefsEquals([]v1alpha1.ClusterReference{original.Spec.ClusterRef, instance.Spec.ClusterRef}) {
+		instance.Spec.ClusterRef = original.Spec.ClusterRef
+	}
+
+	// Prepare cluster connection configurations
+	var clientConfig *clientconfig.NifiConfig
+	var clusterConnect clientconfig.ClusterConnect
+
+	// Get the client config manager associated to the cluster ref.
+	clusterRef := instance.Spec.ClusterRef
+	clusterRef.Namespace = GetClusterRefNamespace(instance.Namespace, instance.Spec.ClusterRef)
+	configManager := config.GetClientConfigManager(r.Client, clusterRef)
+
+	// Generate the connect object
+	if clusterConnect, err = configManager.BuildConnect(); err != nil {
+		// This shouldn't trigger anymore, but leaving it here as a safetybelt
+		if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+			r.Log.Info("Cluster is already gone, there is nothing we can do")
+			if err = r.removeFinalizer(ctx, instance); err != nil {
+				return RequeueWithError(r.Log, "failed to remove finalizer", err)
+			}
+			return Reconciled()
+		}
+
+		// If the referenced cluster no more exist, just skip the deletion requirement in cluster ref change case.
+		if !v1alpha1.ClusterRefsEquals([]v1alpha1.ClusterReference{instance.Spec.ClusterRef, current.Spec.ClusterRef}) {
+			if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(current); err != nil {
+				return RequeueWithError(r.Log, "could not apply last state to annotation", err)
+			}
+			if err := r.Client.Update(ctx, current); err != nil {
+				return RequeueWithError(r.Log, "failed to update NifiAccessPolicy", err)
+			}
+			return RequeueAfter(time.Duration(15) * time.Second)
+		}
+
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceClusterError",
+			fmt.Sprintf("Failed to lookup reference cluster : %s in %s",
+				instance.Spec.ClusterRef.Name, clusterRef.Namespace))
+
+		// the cluster does not exist - should have been caught pre-flight
+		return RequeueWithError(r.Log, "failed to lookup referenced cluster", err)
+	}
+
+	// Generate the client configuration.
+	clientConfig, err = configManager.BuildConfig()
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceClusterError",
+			fmt.Sprintf("Failed to create HTTP client for the referenced cluster : %s in %s",
+				instance.Spec.ClusterRef.Name, clusterRef.Namespace))
+		// the cluster does not exist - should have been caught pre-flight
+		return RequeueWithError(r.Log, "failed to create HTTP client the for referenced cluster", err)
+	}
+
+	// Check if marked for deletion and if so run finalizers
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return r.checkFinalizers(ctx, instance, clientConfig)
+	}
+
+	// Ensure the cluster is ready to receive actions
+	if !clusterConnect.IsReady(r.Log) {
+		r.Log.Info("Cluster is not ready yet, will wait until it is.")
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "ReferenceClusterNotReady",
+			fmt.Sprintf("The referenced cluster is not ready yet : %s in %s",
+				instance.Spec.ClusterRef.Name, clusterConnect.Id()))
+		// the cluster does not exist - should have been caught pre-flight
+		return RequeueAfter(interval)
+	}
+
+	// Ìn case of the cluster reference changed.
+	if !v1alpha1.ClusterRefsEquals([]v1alpha1.ClusterReference{instance.Spec.ClusterRef, current.Spec.ClusterRef}) {
+		// Remove the members from the access policy on the previous cluster.
+		if err := accesspolicies.RemoveAccessPolicyMembers(instance, clientConfig); err != nil {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "RemoveError",
+				fmt.Sprintf("Failed to remove NifiAccessPolicy %s members from cluster %s before moving in %s",
+					instance.Name, original.Spec.ClusterRef.Name, current.Spec.ClusterRef.Name))
+			return RequeueWithError(r.Log, "Failed to remove NifiAccessPolicy members before moving", err)
+		}
+		// Update the last view configuration to the current one.
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(current); err != nil {
+			return RequeueWithError(r.Log, "could not apply last state to annotation", err)
+		}
+		if err := r.Client.Update(ctx, current); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiAccessPolicy", err)
+		}
+		return RequeueAfter(interval)
+	}
+
+	// Resolve the component referenced by the access policy
+	accessPolicies, err := accesspolicies.ResolveAccessPolicies(r.Client,
+		[]v1alpha1.AccessPolicy{instance.Spec.AccessPolicy}, instance.Namespace)
+	if err != nil {
+		switch errors.Cause(err).(type) {
+		case errorfactory.ResourceNotReady:
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "ReferenceComponentNotReady",
+				fmt.Sprintf("The component referenced by the access policy is not deployed yet: %s", err.Error()))
+			return RequeueAfter(interval / 3)
+		default:
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceComponentError",
+				fmt.Sprintf("Failed to lookup the component referenced by the access policy: %s", err.Error()))
+			return RequeueWithError(r.Log, "failed to lookup referenced component", err)
+		}
+	}
+	resolved := instance.DeepCopy()
+	resolved.Spec.AccessPolicy = accessPolicies[0]
+
+	// Remove the members from the access policy previously managed, when the action or the component changed.
+	if accesspolicies.IsAccessPolicyMoved(resolved, clientConfig) {
+		if err := accesspolicies.RemoveAccessPolicyMembers(instance, clientConfig); err != nil {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "RemoveError",
+				fmt.Sprintf("Failed to remove NifiAccessPolicy %s members from the previous access policy %s",
+					instance.Name, instance.Status.Resource))
+			return RequeueWithError(r.Log, "failed to remove NifiAccessPolicy members from the previous access policy", err)
+		}
+		instance.Status = v1alpha1.NifiAccessPolicyStatus{Conflicts: instance.Status.Conflicts}
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiAccessPolicy status", err)
+		}
+		resolved.Status = instance.Status
+	}
+
+	// Ensure no other resource defines the access policy, as they would override each other
+	conflicts, err := r.conflictingAccessPolicies(ctx, resolved, clusterRef, clientConfig.RootProcessGroupId)
+	if err != nil {
+		switch errors.Cause(err).(type) {
+		case errorfactory.ResourceNotReady:
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "ReferenceComponentNotReady",
+				fmt.Sprintf("The component referenced by a possibly conflicting access policy is not deployed yet: %s", err.Error()))
+			return RequeueAfter(interval / 3)
+		default:
+			return RequeueWithError(r.Log, "failed to check the access policy conflicts", err)
+		}
+	}
+	if len(conflicts) > 0 {
+		if !reflect.DeepEqual(conflicts, instance.Status.Conflicts) {
+			instance.Status.Conflicts = conflicts
+			if err := r.Client.Status().Update(ctx, instance); err != nil {
+				return RequeueWithError(r.Log, "failed to update NifiAccessPolicy status", err)
+			}
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "AccessPolicyConflict",
+				fmt.Sprintf("The access policy is also defined by: %s", strings.Join(conflicts, ", ")))
+		}
+		return RequeueAfter(interval)
+	}
+
+	// Resolve the users and user groups granted the access policy
+	users, userGroups, err := r.boundMembers(instance, clusterRef)
+	if err != nil {
+		switch errors.Cause(err).(type) {
+		case errorfactory.ResourceNotReady:
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "ReferenceMemberNotReady",
+				fmt.Sprintf("A member of the access policy is not created yet: %s", err.Error()))
+			return RequeueAfter(interval / 3)
+		default:
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceMemberError",
+				fmt.Sprintf("Failed to lookup a member of the access policy: %s", err.Error()))
+			return RequeueWithError(r.Log, "failed to lookup the access policy members", err)
+		}
+	}
+
+	// Sync the access policy members with NiFi side component, the status and the revision of the access policy only
+	// change when it is updated.
+	status, err := accesspolicies.SyncAccessPolicyMembers(resolved, users, userGroups, clientConfig)
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "SynchronizingFailed",
+			fmt.Sprintf("Synchronizing access policy %s failed", instance.Name))
+		return RequeueWithError(r.Log, "failed to sync NifiAccessPolicy", err)
+	}
+
+	status.Conflicts = nil
+	if !reflect.DeepEqual(*status, instance.Status) {
+		instance.Status = *status
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiAccessPolicy status", err)
+		}
+
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "Synchronized",
+			fmt.Sprintf("Synchronized access policy %s", instance.Name))
+	}
+
+	// Ensure NifiCluster label
+	if instance, err = r.ensureClusterLabel(ctx, clusterConnect, instance); err != nil {
+		return RequeueWithError(r.Log, "failed to ensure NifiCluster label on access policy", err)
+	}
+
+	// Ensure finalizer for cleanup on deletion
+	if !util.StringSliceContains(instance.GetFinalizers(), accessPolicyFinalizer) {
+		r.Log.Info("Adding Finalizer for NifiAccessPolicy")
+		instance.SetFinalizers(append(instance.GetFinalizers(), accessPolicyFinalizer))
+	}
+
+	// Push any changes
+	if instance, err = r.updateAndFetchLatest(ctx, instance); err != nil {
+		return RequeueWithError(r.Log, "failed to update NifiAccessPolicy", err)
+	}
+
+	r.Recorder.Event(instance, corev1.EventTypeNormal, "Reconciled",
+		fmt.Sprintf("Reconciling access policy %s", instance.Name))
+
+	r.Log.Info("Ensured Access Policy")
+
+	return RequeueAfter(interval)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NifiAccessPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.NifiAccessPolicy{}).
+		Watches(&source.Kind{Type: &v1alpha1.NifiUser{}}, handler.EnqueueRequestsFromMapFunc(r.referencingAccessPolicies)).
+		Watches(&source.Kind{Type: &v1alpha1.NifiUserGroup{}}, handler.EnqueueRequestsFromMapFunc(r.referencingAccessPolicies)).
+		Watches(&source.Kind{Type: &v1alpha1.NifiDataflow{}}, handler.EnqueueRequestsFromMapFunc(r.referencingAccessPolicies),
+			componentReferenceIdChanged).
+		Watches(&source.Kind{Type: &v1alpha1.NifiParameterContext{}}, handler.EnqueueRequestsFromMapFunc(r.referencingAccessPolicies),
+			componentReferenceIdChanged).
+		Watches(&source.Kind{Type: &v1alpha1.NifiRegistryClient{}}, handler.EnqueueRequestsFromMapFunc(r.referencingAccessPolicies),
+			componentReferenceIdChanged).
+		Complete(r)
+}
+
+// referencingAccessPolicies lists the access policies granted to the given NifiUser or NifiUserGroup, or on the given
+// NifiDataflow, NifiParameterContext or NifiRegistryClient, to re-sync them when its NiFi id changes.
+func (r *NifiAccessPolicyReconciler) referencingAccessPolicies(obj client.Object) []reconcile.Request {
+	accessPolicies := &v1alpha1.NifiAccessPolicyList{}
+	if err := r.Client.List(context.TODO(), accessPolicies); err != nil {
+		r.Log.Error(err, "failed to list the access policies")
+		return nil
+	}
+
+	kind, isComponent := componentReferenceKind(obj)
+	_, isUser := obj.(*v1alpha1.NifiUser)
+	var requests []reconcile.Request
+	for i := range accessPolicies.Items {
+		ap := &accessPolicies.Items[i]
+		var referenced bool
+		switch {
+		case isComponent:
+			referenced = IsComponentReferenced([]v1alpha1.AccessPolicy{ap.Spec.AccessPolicy}, ap.Namespace, kind,
+				obj.GetName(), obj.GetNamespace())
+		case isUser:
+			referenced = IsUserBound(ap, obj.GetName(), obj.GetNamespace())
+		default:
+			referenced = IsUserGroupBound(ap, obj.GetName(), obj.GetNamespace())
+		}
+		if referenced {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: ap.Name, Namespace: ap.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// boundMembers returns the users and user groups granted the access policy, which must be linked to the same cluster
+// and already created on NiFi side.
+func (r *NifiAccessPolicyReconciler) boundMembers(accessPolicy *v1alpha1.NifiAccessPolicy,
+	clusterRef v1alpha1.ClusterReference) ([]*v1alpha1.NifiUser, []*v1alpha1.NifiUserGroup, error) {
+
+	var users []*v1alpha1.NifiUser
+	for _, userRef := range accessPolicy.Spec.UsersRef {
+		userNamespace := GetUserRefNamespace(accessPolicy.Namespace, userRef)
+		user, err := k8sutil.LookupNifiUser(r.Client, userRef.Name, userNamespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		if user.Spec.ClusterRef.Name != clusterRef.Name ||
+			GetClusterRefNamespace(user.Namespace, user.Spec.ClusterRef) != clusterRef.Namespace {
+			return nil, nil, errors.Errorf("inconsistent cluster references with user %s in %s", userRef.Name, userNamespace)
+		}
+		if user.Status.Id == "" {
+			return nil, nil, errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("user id not found"),
+				"user not created yet", "name", userRef.Name, "namespace", userNamespace)
+		}
+		users = append(users, user)
+	}
+
+	var userGroups []*v1alpha1.NifiUserGroup
+	for _, userGroupRef := range accessPolicy.Spec.UserGroupsRef {
+		userGroupNamespace := GetUserGroupRefNamespace(accessPolicy.Namespace, userGroupRef)
+		userGroup, err := k8sutil.LookupNifiUserGroup(r.Client, userGroupRef.Name, userGroupNamespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		if userGroup.Spec.ClusterRef.Name != clusterRef.Name ||
+			GetClusterRefNamespace(userGroup.Namespace, userGroup.Spec.ClusterRef) != clusterRef.Namespace {
+			return nil, nil, errors.Errorf("inconsistent cluster references with user group %s in %s",
+				userGroupRef.Name, userGroupNamespace)
+		}
+		if userGroup.Status.Id == "" {
+			return nil, nil, errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("user group id not found"),
+				"user group not created yet", "name", userGroupRef.Name, "namespace", userGroupNamespace)
+		}
+		userGroups = append(userGroups, userGroup)
+	}
+
+	return users, userGroups, nil
+}
+
+// conflictingAccessPolicies lists the NifiUsers, NifiUserGroups and other NifiAccessPolicies linked to the same cluster
+// which also define the access policy. An access policy which can't be resolved may conflict, its error is returned.
+func (r *NifiAccessPolicyReconciler) conflictingAccessPolicies(ctx context.Context, accessPolicy *v1alpha1.NifiAccessPolicy,
+	clusterRef v1alpha1.ClusterReference, rootProcessGroupId string) ([]string, error) {
+
+	policy := accessPolicy.Spec.AccessPolicy
+	resource := policy.GetResource(rootProcessGroupId)
+	isConflicting := func(ns string, ref v1alpha1.ClusterReference, accessPolicies []v1alpha1.AccessPolicy) (bool, error) {
+		if ref.Name != clusterRef.Name || GetClusterRefNamespace(ns, ref) != clusterRef.Namespace {
+			return false, nil
+		}
+		resolved, err := accesspolicies.ResolveAccessPolicies(r.Client, accessPolicies, ns)
+		if err != nil {
+			return false, err
+		}
+		for _, ap := range resolved {
+			if ap.Action == policy.Action && ap.GetResource(rootProcessGroupId) == resource {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	var conflicts []string
+	users := &v1alpha1.NifiUserList{}
+	if err := r.Client.List(ctx, users); err != nil {
+		return nil, err
+	}
+	for _, user := range users.Items {
+		conflicting, err := isConflicting(user.Namespace, user.Spec.ClusterRef, user.Spec.AccessPolicies)
+		if err != nil {
+			return nil, errors.WrapIf(err, fmt.Sprintf("NifiUser %s/%s", user.Namespace, user.Name))
+		}
+		if conflicting {
+			conflicts = append(conflicts, fmt.Sprintf("NifiUser %s/%s", user.Namespace, user.Name))
+		}
+	}
+
+	userGroups := &v1alpha1.NifiUserGroupList{}
+	if err := r.Client.List(ctx, userGroups); err != nil {
+		return nil, err
+	}
+	for _, userGroup := range userGroups.Items {
+		conflicting, err := isConflicting(userGroup.Namespace, userGroup.Spec.ClusterRef, userGroup.Spec.AccessPolicies)
+		if err != nil {
+			return nil, errors.WrapIf(err, fmt.Sprintf("NifiUserGroup %s/%s", userGroup.Namespace, userGroup.Name))
+		}
+		if conflicting {
+			conflicts = append(conflicts, fmt.Sprintf("NifiUserGroup %s/%s", userGroup.Namespace, userGroup.Name))
+		}
+	}
+
+	accessPolicyList := &v1alpha1.NifiAccessPolicyList{}
+	if err := r.Client.List(ctx, accessPolicyList); err != nil {
+		return nil, err
+	}
+	for _, ap := range accessPolicyList.Items {
+		if ap.Name == accessPolicy.Name && ap.Namespace == accessPolicy.Namespace {
+			continue
+		}
+		conflicting, err := isConflicting(ap.Namespace, ap.Spec.ClusterRef, []v1alpha1.AccessPolicy{ap.Spec.AccessPolicy})
+		if err != nil {
+			return nil, errors.WrapIf(err, fmt.Sprintf("NifiAccessPolicy %s/%s", ap.Namespace, ap.Name))
+		}
+		if conflicting {
+			conflicts = append(conflicts, fmt.Sprintf("NifiAccessPolicy %s/%s", ap.Namespace, ap.Name))
+		}
+	}
+
+	return conflicts, nil
+}
+
+func (r *NifiAccessPolicyReconciler) ensureClusterLabel(ctx context.Context, cluster clientconfig.ClusterConnect,
+	accessPolicy *v1alpha1.NifiAccessPolicy) (*v1alpha1.NifiAccessPolicy, error) {
+
+	labels := ApplyClusterReferenceLabel(cluster, accessPolicy.GetLabels())
+	if !reflect.DeepEqual(labels, accessPolicy.GetLabels()) {
+		accessPolicy.SetLabels(labels)
+		return r.updateAndFetchLatest(ctx, accessPolicy)
+	}
+	return accessPolicy, nil
+}
+
+func (r *NifiAccessPolicyReconciler) updateAndFetchLatest(ctx context.Context,
+	accessPolicy *v1alpha1.NifiAccessPolicy) (*v1alpha1.NifiAccessPolicy, error) {
+
+	typeMeta := accessPolicy.TypeMeta
+	err := r.Client.Update(ctx, accessPolicy)
+	if err != nil {
+		return nil, err
+	}
+	accessPolicy.TypeMeta = typeMeta
+	return accessPolicy, nil
+}
+
+func (r *NifiAccessPolicyReconciler) checkFinalizers(ctx context.Context, accessPolicy *v1alpha1.NifiAccessPolicy,
+	config *clientconfig.NifiConfig) (reconcile.Result, error) {
+
+	r.Log.Info("NiFi access policy is marked for deletion")
+	var err error
+	if util.StringSliceContains(accessPolicy.GetFinalizers(), accessPolicyFinalizer) {
+		if err = r.finalizeNifiAccessPolicy(accessPolicy, config); err != nil {
+			return RequeueWithError(r.Log, "failed to finalize nifiaccesspolicy", err)
+		}
+		if err = r.removeFinalizer(ctx, accessPolicy); err != nil {
+			return RequeueWithError(r.Log, "failed to remove finalizer from nifiaccesspolicy", err)
+		}
+	}
+	return Reconciled()
+}
+
+func (r *NifiAccessPolicyReconciler) removeFinalizer(ctx context.Context, accessPolicy *v1alpha1.NifiAccessPolicy) error {
+	accessPolicy.SetFinalizers(util.StringSliceRemove(accessPolicy.GetFinalizers(), accessPolicyFinalizer))
+	_, err := r.updateAndFetchLatest(ctx, accessPolicy)
+	return err
+}
+
+func (r *NifiAccessPolicyReconciler) finalizeNifiAccessPolicy(
+	accessPolicy *v1alpha1.NifiAccessPolicy,
+	config *clientconfig.NifiConfig) error {
+
+	// The access policy doesn't manage its members while in conflict.
+	if len(accessPolicy.Status.Conflicts) > 0 {
+		return nil
+	}
+
+	if err := accesspolicies.RemoveAccessPolicyMembers(accessPolicy, config); err != nil {
+		return err
+	}
+
+	r.Log.Info("Delete Access policy members")
+
+	return nil
+}
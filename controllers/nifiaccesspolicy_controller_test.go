@@ -0,0 +1,77 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConflictingAccessPolicies(t *testing.T) {
+	v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	clusterRef := v1alpha1.ClusterReference{Name: "nc", Namespace: "nifi"}
+	readFlow := v1alpha1.AccessPolicy{
+		Type:     v1alpha1.GlobalAccessPolicyType,
+		Action:   v1alpha1.ReadAccessPolicyAction,
+		Resource: v1alpha1.FlowAccessPolicyResource,
+	}
+	accessPolicy := &v1alpha1.NifiAccessPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "nifi"}}
+	accessPolicy.Spec.ClusterRef = clusterRef
+	accessPolicy.Spec.AccessPolicy = readFlow
+
+	user := &v1alpha1.NifiUser{ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: "nifi"}}
+	user.Spec.ClusterRef = clusterRef
+	user.Spec.AccessPolicies = []v1alpha1.AccessPolicy{readFlow}
+	otherCluster := &v1alpha1.NifiUser{ObjectMeta: metav1.ObjectMeta{Name: "other-cluster", Namespace: "nifi"}}
+	otherCluster.Spec.ClusterRef = v1alpha1.ClusterReference{Name: "other", Namespace: "nifi"}
+	otherCluster.Spec.AccessPolicies = []v1alpha1.AccessPolicy{readFlow}
+
+	undeployed := &v1alpha1.NifiAccessPolicy{ObjectMeta: metav1.ObjectMeta{Name: "undeployed", Namespace: "nifi"}}
+	undeployed.Spec.ClusterRef = clusterRef
+	undeployed.Spec.AccessPolicy = v1alpha1.AccessPolicy{
+		Type:         v1alpha1.ComponentAccessPolicyType,
+		Action:       v1alpha1.ReadAccessPolicyAction,
+		Resource:     v1alpha1.DataAccessPolicyResource,
+		ComponentRef: &v1alpha1.ComponentReference{Kind: v1alpha1.DataflowComponentReferenceKind, Name: "flow"},
+	}
+	flow := &v1alpha1.NifiDataflow{ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "nifi"}}
+
+	r := &NifiAccessPolicyReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, accessPolicy, user, otherCluster),
+		Log:    log,
+	}
+	conflicts, err := r.conflictingAccessPolicies(context.TODO(), accessPolicy, clusterRef, "root")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if expected := []string{"NifiUser nifi/user"}; !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("Expected conflicts %v, got: %v", expected, conflicts)
+	}
+
+	r.Client = fake.NewFakeClientWithScheme(scheme.Scheme, accessPolicy, undeployed, flow)
+	_, err = r.conflictingAccessPolicies(context.TODO(), accessPolicy, clusterRef, "root")
+	if _, ok := errors.Cause(err).(errorfactory.ResourceNotReady); !ok {
+		t.Error("Expected the unresolved access policy error to be returned, got:", err)
+	}
+}
@@ -20,16 +20,22 @@ import (
 	"context"
 	"emperror.dev/errors"
 	"fmt"
+	clusterwrapper "github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/cluster"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/scale"
 	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
 	"github.com/Orange-OpenSource/nifikop/pkg/k8sutil"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient/config"
 	"github.com/Orange-OpenSource/nifikop/pkg/pki"
 	"github.com/Orange-OpenSource/nifikop/pkg/resources"
 	"github.com/Orange-OpenSource/nifikop/pkg/resources/nifi"
 	"github.com/Orange-OpenSource/nifikop/pkg/util"
+	nifiutil "github.com/Orange-OpenSource/nifikop/pkg/util/nifi"
 	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/record"
+	"math"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"time"
 
@@ -44,6 +50,13 @@ import (
 var clusterFinalizer = "nificlusters.nifi.orange.com/finalizer"
 var clusterUsersFinalizer = "nificlusters.nifi.orange.com/users"
 
+// The heartbeat age and the active threads of a node are only refreshed into the status when they changed by at least
+// these thresholds, or along with the other node health fields
+const (
+	heartbeatAgeThresholdSeconds = 60
+	activeThreadCountThreshold   = 10
+)
+
 // NifiClusterReconciler reconciles a NifiCluster object
 type NifiClusterReconciler struct {
 	client.Client
@@ -100,8 +113,18 @@ func (r *NifiClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	if instance.IsExternal() {
+		if instance.Spec.GetNodeDiscovery() == v1alpha1.ApiNodeDiscovery {
+			if err := r.reconcileDiscoveredNodes(instance); err != nil {
+				r.Recorder.Event(instance, corev1.EventTypeWarning, "NodesDiscoveryError",
+					fmt.Sprintf("Failed to discover the nodes of the cluster through %s : %s", instance.Spec.NifiURI, err.Error()))
+			}
+		}
+		if err := r.reconcileNodesHealth(instance); err != nil {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "NodesHealthCheckError",
+				fmt.Sprintf("Failed to check the health of the nodes of the cluster : %s", err.Error()))
+		}
 		return reconcile.Result{
-			RequeueAfter: time.Duration(15) * time.Second,
+			RequeueAfter: util.GetRequeueInterval(r.RequeueIntervals["CLUSTER_NODES_HEALTH_REQUEUE_INTERVAL"], r.RequeueOffset),
 		}, nil
 	}
 	//
@@ -164,6 +187,11 @@ func (r *NifiClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				return reconcile.Result{
 					RequeueAfter: intervalRunning,
 				}, nil
+			case errorfactory.NodeConfigurationRejected:
+				r.Recorder.Event(instance, corev1.EventTypeWarning, "NodeConfigurationRejected", err.Error())
+				return reconcile.Result{
+					RequeueAfter: intervalNotReady,
+				}, nil
 			default:
 				return RequeueWithError(r.Log, err.Error(), err)
 			}
@@ -186,7 +214,14 @@ func (r *NifiClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return RequeueWithError(r.Log, err.Error(), err)
 	}
 
-	return Reconciled()
+	if err := r.reconcileNodesHealth(instance); err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "NodesHealthCheckError",
+			fmt.Sprintf("Failed to check the health of the nodes of the cluster : %s", err.Error()))
+	}
+
+	return reconcile.Result{
+		RequeueAfter: util.GetRequeueInterval(r.RequeueIntervals["CLUSTER_NODES_HEALTH_REQUEUE_INTERVAL"], r.RequeueOffset),
+	}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -201,6 +236,144 @@ func (r *NifiClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// reconcileDiscoveredNodes stores into the nodes state the topology of the external cluster, as returned by the
+// NiFi cluster API.
+func (r *NifiClusterReconciler) reconcileDiscoveredNodes(cluster *v1alpha1.NifiCluster) error {
+	configManager := config.GetClientConfigManager(r.Client, v1alpha1.ClusterReference{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name,
+	})
+	clientConfig, err := configManager.BuildConfig()
+	if err != nil {
+		return err
+	}
+
+	discoveredNodes, err := clusterwrapper.DiscoverNodes(clientConfig, cluster.Status.NodesState)
+	if err != nil {
+		return err
+	}
+
+	for nId, node := range discoveredNodes {
+		if state, ok := cluster.Status.NodesState[nId]; ok && state.DiscoveredNode != nil && *state.DiscoveredNode == node {
+			continue
+		}
+		r.Log.Info(fmt.Sprintf("Node %s discovered at %s", nId, node.Address))
+		if err := k8sutil.UpdateNodeStatus(r.Client, []string{nId}, cluster, node, r.Log); err != nil {
+			return err
+		}
+	}
+
+	for nId := range cluster.Status.NodesState {
+		if _, ok := discoveredNodes[nId]; !ok {
+			r.Log.Info(fmt.Sprintf("Node %s is no longer part of the cluster", nId))
+			if err := k8sutil.DeleteStatus(r.Client, nId, cluster, r.Log); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reconcileNodesHealth stores into the nodes state the health of each node as reported by the NiFi cluster API,
+// and requests the reconnection of the nodes disconnected for longer than the configured grace period.
+func (r *NifiClusterReconciler) reconcileNodesHealth(cluster *v1alpha1.NifiCluster) error {
+	configManager := config.GetClientConfigManager(r.Client, v1alpha1.ClusterReference{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name,
+	})
+	clientConfig, err := configManager.BuildConfig()
+	if err != nil {
+		return err
+	}
+
+	nodesHealth, err := clusterwrapper.GetNodesHealth(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	healthCheck := cluster.Spec.NodesHealthCheck
+	for nId, health := range nodesHealth {
+		nodeState := cluster.Status.NodesState[nId]
+		previous := nodeState.NodeHealth
+
+		if previous == nil || previous.ConnectionStatus != health.ConnectionStatus {
+			if health.IsConnected() {
+				if previous != nil {
+					r.Recorder.Event(cluster, corev1.EventTypeNormal, "NodeConnected",
+						fmt.Sprintf("Node %s is connected to the cluster", nId))
+				}
+			} else {
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, "NodeNotConnected",
+					fmt.Sprintf("Node %s is %s on NiFi cluster side", nId, health.ConnectionStatus))
+			}
+		}
+
+		if !health.IsConnected() {
+			health.DisconnectedSince = now.Format(nifiutil.TimeStampLayout)
+			if previous != nil && !previous.IsConnected() && previous.DisconnectedSince != "" {
+				health.DisconnectedSince = previous.DisconnectedSince
+				health.LastReconnectAttempt = previous.LastReconnectAttempt
+			}
+
+			// Only reconnect nodes left disconnected outside of any graceful action managed by the operator.
+			if healthCheck.AutoReconnect && health.ConnectionStatus == string(v1alpha1.DisconnectStatus) &&
+				!nodeState.GracefulActionState.State.IsRunningState() && !nodeState.GracefulActionState.State.IsRequiredState() &&
+				r.reconnectGracePeriodExpired(health, now, healthCheck.GetReconnectGracePeriodMinutes()) {
+
+				health.LastReconnectAttempt = now.Format(nifiutil.TimeStampLayout)
+				if _, _, err := scale.ConnectClusterNode(clientConfig, nId); err != nil {
+					r.Recorder.Event(cluster, corev1.EventTypeWarning, "NodeReconnectError",
+						fmt.Sprintf("Failed to reconnect node %s to the cluster : %s", nId, err.Error()))
+				} else {
+					r.Recorder.Event(cluster, corev1.EventTypeNormal, "NodeReconnecting",
+						fmt.Sprintf("Reconnecting node %s, disconnected since %s", nId, health.DisconnectedSince))
+				}
+			}
+		}
+
+		if !nodeHealthChanged(previous, health) {
+			continue
+		}
+		if err := k8sutil.UpdateNodeStatus(r.Client, []string{nId}, cluster, health, r.Log); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nodeHealthChanged returns true if the connection status, the roles or the disconnection tracking of the node changed,
+// or if its heartbeat age or active threads changed by at least their threshold. The heartbeat and the active threads
+// change on every check, so that polling the nodes health would otherwise update the cluster status each time.
+func nodeHealthChanged(previous *v1alpha1.NodeHealthState, current v1alpha1.NodeHealthState) bool {
+	if previous == nil {
+		return true
+	}
+	return previous.ConnectionStatus != current.ConnectionStatus ||
+		!reflect.DeepEqual(previous.Roles, current.Roles) ||
+		previous.DisconnectedSince != current.DisconnectedSince ||
+		previous.LastReconnectAttempt != current.LastReconnectAttempt ||
+		math.Abs(float64(previous.HeartbeatAgeSeconds-current.HeartbeatAgeSeconds)) >= heartbeatAgeThresholdSeconds ||
+		math.Abs(float64(previous.ActiveThreadCount-current.ActiveThreadCount)) >= activeThreadCountThreshold
+}
+
+// reconnectGracePeriodExpired returns true if the node has been disconnected, and not reconnected by the operator,
+// for longer than the grace period.
+func (r *NifiClusterReconciler) reconnectGracePeriodExpired(health v1alpha1.NodeHealthState, now time.Time, gracePeriodMinutes float64) bool {
+	since := health.DisconnectedSince
+	if health.LastReconnectAttempt != "" {
+		since = health.LastReconnectAttempt
+	}
+
+	sinceTime, err := time.Parse(nifiutil.TimeStampLayout, since)
+	if err != nil {
+		return false
+	}
+	return now.Sub(sinceTime).Minutes() > gracePeriodMinutes
+}
+
 func (r *NifiClusterReconciler) checkFinalizers(ctx context.Context,
 	cluster *v1alpha1.NifiCluster) (reconcile.Result, error) {
 
@@ -0,0 +1,100 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	nifiutil "github.com/Orange-OpenSource/nifikop/pkg/util/nifi"
+)
+
+func TestNodeHealthChanged(t *testing.T) {
+	previous := v1alpha1.NodeHealthState{
+		ConnectionStatus:    string(v1alpha1.ConnectStatus),
+		LastHeartbeat:       "01/01/2021 10:00:00 UTC",
+		HeartbeatAgeSeconds: 2,
+		Roles:               []string{"Primary Node"},
+		ActiveThreadCount:   4,
+	}
+
+	if !nodeHealthChanged(nil, previous) {
+		t.Error("Expected a change for a node without health")
+	}
+
+	current := previous
+	current.LastHeartbeat = "01/01/2021 10:00:05 UTC"
+	current.HeartbeatAgeSeconds = 1
+	current.ActiveThreadCount = 8
+	if nodeHealthChanged(&previous, current) {
+		t.Error("Expected no change when the heartbeat and the active threads changed below their threshold")
+	}
+
+	current.HeartbeatAgeSeconds = previous.HeartbeatAgeSeconds + heartbeatAgeThresholdSeconds
+	if !nodeHealthChanged(&previous, current) {
+		t.Error("Expected a change when the heartbeat age changed by its threshold")
+	}
+
+	current = previous
+	current.ActiveThreadCount = previous.ActiveThreadCount + activeThreadCountThreshold
+	if !nodeHealthChanged(&previous, current) {
+		t.Error("Expected a change when the active threads changed by their threshold")
+	}
+
+	current.Roles = []string{"Primary Node", "Cluster Coordinator"}
+	if !nodeHealthChanged(&previous, current) {
+		t.Error("Expected a change when the roles changed")
+	}
+
+	current = previous
+	current.ConnectionStatus = string(v1alpha1.DisconnectStatus)
+	if !nodeHealthChanged(&previous, current) {
+		t.Error("Expected a change when the connection status changed")
+	}
+
+	current = previous
+	current.LastReconnectAttempt = "2021-01-01 10:00:00 UTC"
+	if !nodeHealthChanged(&previous, current) {
+		t.Error("Expected a change when a reconnection was requested")
+	}
+}
+
+func TestReconnectGracePeriodExpired(t *testing.T) {
+	r := &NifiClusterReconciler{}
+	now := time.Now()
+	health := v1alpha1.NodeHealthState{
+		ConnectionStatus:  string(v1alpha1.DisconnectStatus),
+		DisconnectedSince: now.Add(-10 * time.Minute).Format(nifiutil.TimeStampLayout),
+	}
+
+	if !r.reconnectGracePeriodExpired(health, now, 5) {
+		t.Error("Expected the grace period to be expired")
+	}
+	if r.reconnectGracePeriodExpired(health, now, 15) {
+		t.Error("Expected the grace period not to be expired")
+	}
+
+	// The grace period restarts from the last reconnection attempt
+	health.LastReconnectAttempt = now.Add(-2 * time.Minute).Format(nifiutil.TimeStampLayout)
+	if r.reconnectGracePeriodExpired(health, now, 5) {
+		t.Error("Expected the grace period not to be expired since the last reconnection attempt")
+	}
+
+	health.LastReconnectAttempt = "not a time"
+	if r.reconnectGracePeriodExpired(health, now, 5) {
+		t.Error("Expected an unparsable time not to expire the grace period")
+	}
+}
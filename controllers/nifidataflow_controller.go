@@ -28,9 +28,11 @@ import (
 	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/dataflow"
 	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
 	"github.com/Orange-OpenSource/nifikop/pkg/k8sutil"
+	"github.com/Orange-OpenSource/nifikop/pkg/metrics"
 	"github.com/Orange-OpenSource/nifikop/pkg/nificlient/config"
 	"github.com/Orange-OpenSource/nifikop/pkg/util"
 	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/schedule"
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -47,6 +49,12 @@ import (
 
 var dataflowFinalizer = "nifidataflows.nifi.orange.com/finalizer"
 
+// maxRecentBulletins defines the number of bulletins kept into the NifiDataflow status.
+const maxRecentBulletins = 10
+
+// flowStatisticsUpdateInterval defines the minimum interval between two updates of the NifiDataflow statistics status.
+const flowStatisticsUpdateInterval = 5 * time.Minute
+
 // NifiDataflowReconciler reconciles a NifiDataflow object
 type NifiDataflowReconciler struct {
 	client.Client
@@ -274,11 +282,19 @@ func (r *NifiDataflowReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return RequeueAfter(interval)
 	}
 
-	if (instance.Spec.SyncNever() && len(instance.Status.State) > 0) ||
-		(instance.Spec.SyncOnce() && instance.Status.State == v1alpha1.DataflowStateRan) {
+	if instance.Spec.SyncNever() && len(instance.Status.State) > 0 {
 		return Reconciled()
 	}
 
+	// A dataflow synchronized once is still started and stopped according to its schedule, and its statistics collected
+	if instance.Spec.SyncOnce() && (instance.Status.State == v1alpha1.DataflowStateRan ||
+		instance.Status.State == v1alpha1.DataflowStateStopped) {
+		if err := r.reportFlowStatistics(ctx, instance, clientConfig); err != nil {
+			r.Log.Error(err, "failed to collect NifiDataflow statistics")
+		}
+		return r.reconcileSchedule(ctx, instance, clientConfig, interval)
+	}
+
 	r.Recorder.Event(instance, corev1.EventTypeWarning, "Reconciling",
 		fmt.Sprintf("Reconciling failed dataflow %s based on flow {bucketId : %s, flowId: %s, version: %s}",
 			instance.Name, instance.Spec.BucketId,
@@ -290,6 +306,48 @@ func (r *NifiDataflowReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return RequeueWithError(r.Log, "failure checking for existing dataflow", err)
 	}
 
+	// Adopt the existing process group instead of creating the dataflow, a process group removed once adopted is
+	// recreated
+	if !existing && instance.Spec.IsAdoption() && instance.Status.ProcessGroupID == "" {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "Adopting",
+			fmt.Sprintf("Adopting process group %s%s as dataflow %s",
+				instance.Spec.AdoptProcessGroupId, instance.Spec.AdoptProcessGroupName, instance.Name))
+
+		processGroupStatus, err := dataflow.AdoptDataflow(instance, clientConfig, registryClient)
+		if err != nil {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "AdoptionFailed",
+				fmt.Sprintf("Adopting process group %s%s as dataflow %s failed : %s",
+					instance.Spec.AdoptProcessGroupId, instance.Spec.AdoptProcessGroupName, instance.Name, err))
+			return RequeueWithError(r.Log, "failure adopting dataflow", err)
+		}
+
+		owner, err := r.processGroupOwner(ctx, instance, processGroupStatus.ProcessGroupID)
+		if err != nil {
+			return RequeueWithError(r.Log, "failure checking the owner of the process group to adopt", err)
+		}
+		if owner != nil {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "AdoptionFailed",
+				fmt.Sprintf("Adopting process group %s as dataflow %s failed : already owned by dataflow %s/%s",
+					processGroupStatus.ProcessGroupID, instance.Name, owner.Namespace, owner.Name))
+			return RequeueWithError(r.Log, "failure adopting dataflow",
+				errors.Errorf("process group %s is already owned by dataflow %s/%s",
+					processGroupStatus.ProcessGroupID, owner.Namespace, owner.Name))
+		}
+
+		// Set dataflow status
+		instance.Status = *processGroupStatus
+		instance.Status.State = v1alpha1.DataflowStateCreated
+
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiDataflow status", err)
+		}
+
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "Adopted",
+			fmt.Sprintf("Adopted process group %s as dataflow %s", instance.Status.ProcessGroupID, instance.Name))
+
+		existing = true
+	}
+
 	// Create dataflow if it doesn't already exist
 	if !existing {
 		r.Recorder.Event(instance, corev1.EventTypeNormal, "Creating",
@@ -353,6 +411,22 @@ func (r *NifiDataflowReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 		if err != nil {
 			switch errors.Cause(err).(type) {
+			case errorfactory.NifiFlowLocalChangesCommitted:
+				r.Recorder.Event(instance, corev1.EventTypeNormal, "LocalChangesCommitted",
+					fmt.Sprintf("Committed local changes of dataflow %s as flow {bucketId : %s, flowId: %s, version: %s}",
+						instance.Name, instance.Spec.BucketId,
+						instance.Spec.FlowId, strconv.FormatInt(int64(*instance.Status.FlowVersion), 10)))
+				return reconcile.Result{
+					RequeueAfter: interval / 3,
+				}, nil
+			case errorfactory.NifiFlowDrainAborted:
+				return r.abortUpdate(ctx, instance, clientConfig, interval, "SynchronizingAborted",
+					fmt.Sprintf("Syncing dataflow %s aborted as %d flowfiles remain after the drain timeout of %s",
+						instance.Name, instance.Status.Drain.RemainingFlowFiles, instance.Spec.DrainTimeout.Duration))
+			case errorfactory.NifiFlowRolledBack:
+				return r.abortUpdate(ctx, instance, clientConfig, interval, "SynchronizingRolledBack",
+					fmt.Sprintf("Syncing dataflow %s to version %d rolled back: %s",
+						instance.Name, instance.Status.BlueGreen.FlowVersion, instance.Status.BlueGreen.FailureReason))
 			case errorfactory.NifiConnectionDropping,
 				errorfactory.NifiFlowUpdateRequestRunning,
 				errorfactory.NifiFlowDraining,
@@ -381,10 +455,13 @@ func (r *NifiDataflowReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				instance.Spec.FlowId, strconv.FormatInt(int64(*instance.Spec.FlowVersion), 10)))
 	}
 
-	// Check if the flow is out of sync
-	isOutOfSink, err := dataflow.IsOutOfSyncDataflow(instance, clientConfig, registryClient, parameterContext)
-	if err != nil {
-		return RequeueWithError(r.Log, "failed to check NifiDataflow sync", err)
+	// Check if the flow is out of sync, the aborted update is only retried once the spec changes
+	isOutOfSink := false
+	if !instance.IsUpdateAborted() {
+		isOutOfSink, err = dataflow.IsOutOfSyncDataflow(instance, clientConfig, registryClient, parameterContext)
+		if err != nil {
+			return RequeueWithError(r.Log, "failed to check NifiDataflow sync", err)
+		}
 	}
 
 	if isOutOfSink {
@@ -395,11 +472,60 @@ func (r *NifiDataflowReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return Requeue()
 	}
 
+	// Apply the component overrides, re-applied when they drift
+	if err := dataflow.SyncComponentOverrides(instance, clientConfig); err != nil {
+		switch errors.Cause(err).(type) {
+		case errorfactory.NifiFlowSyncing:
+			return RequeueAfter(interval / 3)
+		default:
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "ComponentOverridesFailed",
+				fmt.Sprintf("Applying the component overrides of dataflow %s failed", instance.Name))
+			return RequeueWithError(r.Log, "failed to apply NifiDataflow component overrides", err)
+		}
+	}
+
+	// Evaluate the schedule of the flow
+	previousSchedule := instance.Status.Schedule.DeepCopy()
+	running, err := r.updateSchedule(ctx, instance)
+	if err != nil {
+		return RequeueWithError(r.Log, "failed to evaluate NifiDataflow schedule", err)
+	}
+
+	// An aborted update stays degraded until the spec changes, only the transitions of the schedule are applied
+	if instance.IsUpdateAborted() && running != isScheduleRunning(previousSchedule) {
+		if running {
+			err = dataflow.ScheduleDataflow(instance, clientConfig)
+		} else {
+			err = dataflow.UnscheduleDataflow(instance, clientConfig)
+		}
+		if err != nil {
+			switch errors.Cause(err).(type) {
+			case errorfactory.NifiFlowControllerServiceScheduling, errorfactory.NifiFlowScheduling:
+			default:
+				return RequeueWithError(r.Log, "failed to apply NifiDataflow schedule after the aborted update", err)
+			}
+		}
+	}
+
+	// Stop the flow outside of its schedule
+	if !running && instance.Status.State != v1alpha1.DataflowStateStopped && !instance.IsUpdateAborted() {
+		if err := r.stopOutsideSchedule(ctx, instance, clientConfig); err != nil {
+			switch errors.Cause(err).(type) {
+			case errorfactory.NifiFlowControllerServiceScheduling, errorfactory.NifiFlowScheduling:
+				return RequeueAfter(interval / 3)
+			default:
+				return RequeueWithError(r.Log, "failed to stop NifiDataflow", err)
+			}
+		}
+	}
+
 	// Schedule the flow
-	if instance.Status.State == v1alpha1.DataflowStateCreated ||
+	if running && (instance.Status.State == v1alpha1.DataflowStateCreated ||
 		instance.Status.State == v1alpha1.DataflowStateStarting ||
 		instance.Status.State == v1alpha1.DataflowStateInSync ||
-		(!instance.Spec.SyncOnce() && instance.Status.State == v1alpha1.DataflowStateRan) {
+		instance.Status.State == v1alpha1.DataflowStateStopped ||
+		(instance.Status.State == v1alpha1.DataflowStateDegraded && !instance.IsUpdateAborted()) ||
+		(!instance.Spec.SyncOnce() && instance.Status.State == v1alpha1.DataflowStateRan)) {
 
 		instance.Status.State = v1alpha1.DataflowStateStarting
 		if err := r.Client.Status().Update(ctx, instance); err != nil {
@@ -435,6 +561,21 @@ func (r *NifiDataflowReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				instance.Spec.FlowId, strconv.FormatInt(int64(*instance.Spec.FlowVersion), 10)))
 	}
 
+	// Report the bulletins emitted by the dataflow components
+	if err := r.reportBulletins(ctx, instance, clientConfig); err != nil {
+		r.Log.Error(err, "failed to report NifiDataflow bulletins")
+	}
+
+	// Report the local changes of the dataflow
+	if err := r.reportLocalChanges(ctx, instance, clientConfig); err != nil {
+		r.Log.Error(err, "failed to report NifiDataflow local changes")
+	}
+
+	// Collect the runtime statistics of the dataflow
+	if err := r.reportFlowStatistics(ctx, instance, clientConfig); err != nil {
+		r.Log.Error(err, "failed to collect NifiDataflow statistics")
+	}
+
 	// Ensure NifiCluster label
 	if instance, err = r.ensureClusterLabel(ctx, clusterConnect, instance); err != nil {
 		return RequeueWithError(r.Log, "failed to ensure NifiCluster label on dataflow", err)
@@ -452,13 +593,240 @@ func (r *NifiDataflowReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			instance.Name, instance.Spec.BucketId,
 			instance.Spec.FlowId, strconv.FormatInt(int64(*instance.Spec.FlowVersion), 10)))
 
-	if instance.Spec.SyncOnce() {
-		return Reconciled()
+	return RequeueAfter(scheduleRequeueInterval(instance, interval/3, time.Now()))
+}
+
+// abortUpdate marks the dataflow as degraded once its update has been aborted after a drain timeout or rolled back,
+// and restarts it with its current version.
+func (r *NifiDataflowReconciler) abortUpdate(ctx context.Context, flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig, interval time.Duration, reason, message string) (reconcile.Result, error) {
+
+	flow.Status.State = v1alpha1.DataflowStateDegraded
+	if err := r.Client.Status().Update(ctx, flow); err != nil {
+		return RequeueWithError(r.Log, "failed to update NifiDataflow status", err)
+	}
+
+	r.Recorder.Event(flow, corev1.EventTypeWarning, reason, message)
+
+	// The dataflow is only restarted within its schedule
+	running, err := r.updateSchedule(ctx, flow)
+	if err != nil {
+		return RequeueWithError(r.Log, "failed to evaluate NifiDataflow schedule", err)
+	}
+	if !running {
+		if err := dataflow.UnscheduleDataflow(flow, config); err != nil {
+			return RequeueWithError(r.Log, "failed to stop NifiDataflow after the aborted update", err)
+		}
+		return RequeueAfter(scheduleRequeueInterval(flow, interval/3, time.Now()))
+	}
+
+	if err := dataflow.ScheduleDataflow(flow, config); err != nil {
+		switch errors.Cause(err).(type) {
+		case errorfactory.NifiFlowControllerServiceScheduling, errorfactory.NifiFlowScheduling:
+		default:
+			return RequeueWithError(r.Log, "failed to restart NifiDataflow after the aborted update", err)
+		}
 	}
 
 	return RequeueAfter(interval / 3)
 }
 
+// reconcileSchedule starts and stops according to its schedule a dataflow which is no longer synchronized.
+func (r *NifiDataflowReconciler) reconcileSchedule(ctx context.Context, flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig, interval time.Duration) (reconcile.Result, error) {
+
+	running, err := r.updateSchedule(ctx, flow)
+	if err != nil {
+		return RequeueWithError(r.Log, "failed to evaluate NifiDataflow schedule", err)
+	}
+
+	if !running && flow.Status.State != v1alpha1.DataflowStateStopped {
+		if err := r.stopOutsideSchedule(ctx, flow, config); err != nil {
+			switch errors.Cause(err).(type) {
+			case errorfactory.NifiFlowControllerServiceScheduling, errorfactory.NifiFlowScheduling:
+				return RequeueAfter(interval / 3)
+			default:
+				return RequeueWithError(r.Log, "failed to stop NifiDataflow", err)
+			}
+		}
+	}
+
+	if running && flow.Status.State == v1alpha1.DataflowStateStopped {
+		r.Recorder.Event(flow, corev1.EventTypeNormal, "Starting",
+			fmt.Sprintf("Starting dataflow %s within its schedule", flow.Name))
+
+		if err := dataflow.ScheduleDataflow(flow, config); err != nil {
+			switch errors.Cause(err).(type) {
+			case errorfactory.NifiFlowControllerServiceScheduling, errorfactory.NifiFlowScheduling:
+				return RequeueAfter(interval / 3)
+			default:
+				r.Recorder.Event(flow, corev1.EventTypeWarning, "StartingFailed",
+					fmt.Sprintf("Starting dataflow %s within its schedule failed", flow.Name))
+				return RequeueWithError(r.Log, "failed to run NifiDataflow", err)
+			}
+		}
+
+		flow.Status.State = v1alpha1.DataflowStateRan
+		if err := r.Client.Status().Update(ctx, flow); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiDataflow status", err)
+		}
+
+		r.Recorder.Event(flow, corev1.EventTypeNormal, "Ran",
+			fmt.Sprintf("Ran dataflow %s within its schedule", flow.Name))
+	}
+
+	return RequeueAfter(scheduleRequeueInterval(flow, interval/3, time.Now()))
+}
+
+// updateSchedule evaluates the schedule of the dataflow, records its state into the status and returns whether the
+// dataflow must run.
+func (r *NifiDataflowReconciler) updateSchedule(ctx context.Context, flow *v1alpha1.NifiDataflow) (bool, error) {
+	currentSchedule := flow.Status.Schedule.DeepCopy()
+	running, err := evaluateSchedule(flow, time.Now())
+	if err != nil {
+		r.Recorder.Event(flow, corev1.EventTypeWarning, "ScheduleError",
+			fmt.Sprintf("Failed to evaluate the schedule of dataflow %s : %s", flow.Name, err))
+		return false, err
+	}
+	if !reflect.DeepEqual(currentSchedule, flow.Status.Schedule) {
+		if err := r.Client.Status().Update(ctx, flow); err != nil {
+			return false, errors.WrapIf(err, "failed to update NifiDataflow status")
+		}
+	}
+	return running, nil
+}
+
+// processGroupOwner returns the other dataflow managing the given process group, if any.
+func (r *NifiDataflowReconciler) processGroupOwner(ctx context.Context, flow *v1alpha1.NifiDataflow,
+	processGroupId string) (*v1alpha1.NifiDataflow, error) {
+
+	dataflows := &v1alpha1.NifiDataflowList{}
+	if err := r.Client.List(ctx, dataflows); err != nil {
+		return nil, errors.WrapIf(err, "failed to list the dataflows")
+	}
+	return findProcessGroupOwner(dataflows.Items, flow, processGroupId), nil
+}
+
+// findProcessGroupOwner returns the dataflow, other than the given one, whose current or blue/green process group is
+// the given one.
+func findProcessGroupOwner(dataflows []v1alpha1.NifiDataflow, flow *v1alpha1.NifiDataflow,
+	processGroupId string) *v1alpha1.NifiDataflow {
+
+	for i := range dataflows {
+		other := &dataflows[i]
+		if other.Namespace == flow.Namespace && other.Name == flow.Name {
+			continue
+		}
+		if other.Status.ProcessGroupID == processGroupId ||
+			(other.Status.BlueGreen != nil && other.Status.BlueGreen.ProcessGroupID == processGroupId) {
+			return other
+		}
+	}
+	return nil
+}
+
+// stopOutsideSchedule stops the dataflow and marks it as stopped outside of its schedule. The scheduling errors
+// returned while the components are still stopping are transient and must be retried.
+func (r *NifiDataflowReconciler) stopOutsideSchedule(ctx context.Context, flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig) error {
+
+	r.Recorder.Event(flow, corev1.EventTypeNormal, "Stopping",
+		fmt.Sprintf("Stopping dataflow %s outside of its schedule", flow.Name))
+
+	if err := dataflow.UnscheduleDataflow(flow, config); err != nil {
+		switch errors.Cause(err).(type) {
+		case errorfactory.NifiFlowControllerServiceScheduling, errorfactory.NifiFlowScheduling:
+			// the components are still stopping
+		default:
+			r.Recorder.Event(flow, corev1.EventTypeWarning, "StoppingFailed",
+				fmt.Sprintf("Stopping dataflow %s outside of its schedule failed", flow.Name))
+		}
+		return err
+	}
+
+	flow.Status.State = v1alpha1.DataflowStateStopped
+	if err := r.Client.Status().Update(ctx, flow); err != nil {
+		return errors.WrapIf(err, "failed to update NifiDataflow status")
+	}
+
+	r.Recorder.Event(flow, corev1.EventTypeNormal, "Stopped",
+		fmt.Sprintf("Stopped dataflow %s outside of its schedule", flow.Name))
+	return nil
+}
+
+// evaluateSchedule returns whether the dataflow must run according to its schedule and its schedule override
+// annotation, and records the state of the schedule into the status.
+func evaluateSchedule(flow *v1alpha1.NifiDataflow, now time.Time) (bool, error) {
+	override := flow.GetAnnotations()[v1alpha1.ScheduleOverrideAnnotation]
+	if flow.Spec.Schedule == nil && override == "" {
+		flow.Status.Schedule = nil
+		return true, nil
+	}
+
+	status := &v1alpha1.DataflowScheduleStatus{InWindow: true, Override: override}
+	if flow.Spec.Schedule != nil {
+		window, err := schedule.NewWindow(flow.Spec.Schedule.Start, flow.Spec.Schedule.Stop, flow.Spec.Schedule.Timezone)
+		if err != nil {
+			return false, err
+		}
+
+		transition := window.NextTransition(now)
+		status.InWindow = !transition.Start
+		status.NextTransitionTime = transition.Time.UTC().Format(time.RFC3339)
+		status.NextTransition = v1alpha1.ScheduleStop
+		if transition.Start {
+			status.NextTransition = v1alpha1.ScheduleStart
+		}
+	}
+
+	switch override {
+	case v1alpha1.ScheduleStart, v1alpha1.ScheduleStop, "":
+	default:
+		return false, fmt.Errorf("invalid %s annotation value %s, expected %s or %s",
+			v1alpha1.ScheduleOverrideAnnotation, override, v1alpha1.ScheduleStart, v1alpha1.ScheduleStop)
+	}
+
+	flow.Status.Schedule = status
+	return isScheduleRunning(status), nil
+}
+
+// isScheduleRunning returns whether the dataflow must run according to the recorded state of its schedule, a dataflow
+// without schedule always runs.
+func isScheduleRunning(status *v1alpha1.DataflowScheduleStatus) bool {
+	if status == nil {
+		return true
+	}
+	switch status.Override {
+	case v1alpha1.ScheduleStart:
+		return true
+	case v1alpha1.ScheduleStop:
+		return false
+	}
+	return status.InWindow
+}
+
+// scheduleRequeueInterval returns the given interval, shortened to requeue the dataflow at its next scheduled transition.
+func scheduleRequeueInterval(flow *v1alpha1.NifiDataflow, interval time.Duration, now time.Time) time.Duration {
+	if flow.Status.Schedule == nil || flow.Status.Schedule.NextTransitionTime == "" {
+		return interval
+	}
+
+	transitionTime, err := time.Parse(time.RFC3339, flow.Status.Schedule.NextTransitionTime)
+	if err != nil {
+		return interval
+	}
+
+	// Requeue right after the transition, to evaluate the schedule once it is passed.
+	untilTransition := transitionTime.Sub(now) + time.Second
+	if untilTransition < time.Second {
+		return time.Second
+	}
+	if untilTransition < interval {
+		return untilTransition
+	}
+	return interval
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *NifiDataflowReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -466,6 +834,111 @@ func (r *NifiDataflowReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// reportBulletins records the new bulletins emitted by the dataflow components as events, and keeps the most recent
+// ones into the status.
+func (r *NifiDataflowReconciler) reportBulletins(ctx context.Context, flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig) error {
+
+	bulletins, lastBulletinId, err := dataflow.GetBulletins(flow, config)
+	if err != nil {
+		return err
+	}
+
+	// The bulletins of the other components are skipped without updating the status, which would trigger a new
+	// reconciliation each time.
+	if len(bulletins) == 0 {
+		return nil
+	}
+
+	for _, bulletin := range bulletins {
+		reason := "BulletinWarning"
+		if bulletin.Level == "ERROR" {
+			reason = "BulletinError"
+		}
+		// The event recorder aggregates the identical events, so the bulletin id is not part of the message.
+		r.Recorder.Event(flow, corev1.EventTypeWarning, reason,
+			fmt.Sprintf("%s (%s) : %s", bulletin.SourceName, bulletin.SourceId, bulletin.Message))
+	}
+
+	recentBulletins := append(flow.Status.RecentBulletins, bulletins...)
+	if len(recentBulletins) > maxRecentBulletins {
+		recentBulletins = recentBulletins[len(recentBulletins)-maxRecentBulletins:]
+	}
+	flow.Status.RecentBulletins = recentBulletins
+	flow.Status.LastBulletinId = lastBulletinId
+
+	return r.Client.Status().Update(ctx, flow)
+}
+
+// reportLocalChanges stores the locally modified components of the dataflow into the status when the local changes
+// are reported, and records an event when they change.
+func (r *NifiDataflowReconciler) reportLocalChanges(ctx context.Context, flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig) error {
+
+	var localChanges []v1alpha1.LocalChange
+	if flow.Spec.GetLocalChangesPolicy() == v1alpha1.ReportLocalChangesPolicy {
+		var err error
+		if localChanges, err = dataflow.GetLocalChanges(flow, config); err != nil {
+			return err
+		}
+	}
+
+	if reflect.DeepEqual(localChanges, flow.Status.LocalChanges) {
+		return nil
+	}
+
+	if len(localChanges) > 0 {
+		r.Recorder.Event(flow, corev1.EventTypeWarning, "LocalChangesDetected",
+			fmt.Sprintf("Detected local changes on %d components of dataflow %s", len(localChanges), flow.Name))
+	}
+
+	flow.Status.LocalChanges = localChanges
+	return r.Client.Status().Update(ctx, flow)
+}
+
+// reportFlowStatistics stores the runtime statistics of the dataflow into the status and exports them as metrics.
+func (r *NifiDataflowReconciler) reportFlowStatistics(ctx context.Context, flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig) error {
+
+	stats, err := dataflow.GetFlowStatistics(flow, config)
+	if err != nil {
+		return err
+	}
+
+	metrics.SetDataflowStatistics(flow, *stats)
+
+	now := time.Now()
+	if !flowStatisticsChanged(flow.Status.FlowStatistics, *stats, now) {
+		return nil
+	}
+
+	stats.UpdateTime = now.UTC().Format(time.RFC3339)
+	flow.Status.FlowStatistics = stats
+	return r.Client.Status().Update(ctx, flow)
+}
+
+// flowStatisticsChanged returns true if the statistics of the dataflow must be stored into the status. As the runtime
+// statistics change continuously and each status update triggers a new reconciliation, they are only stored every
+// flowStatisticsUpdateInterval, unless the component counts change.
+func flowStatisticsChanged(previous *v1alpha1.FlowStatistics, current v1alpha1.FlowStatistics, now time.Time) bool {
+	if previous == nil {
+		return true
+	}
+	if previous.RunningCount != current.RunningCount || previous.StoppedCount != current.StoppedCount ||
+		previous.InvalidCount != current.InvalidCount || previous.DisabledCount != current.DisabledCount {
+		return true
+	}
+
+	current.LastRefreshed = previous.LastRefreshed
+	current.UpdateTime = previous.UpdateTime
+	if reflect.DeepEqual(*previous, current) {
+		return false
+	}
+
+	updateTime, err := time.Parse(time.RFC3339, previous.UpdateTime)
+	return err != nil || now.Sub(updateTime) >= flowStatisticsUpdateInterval
+}
+
 func (r *NifiDataflowReconciler) ensureClusterLabel(ctx context.Context, cluster clientconfig.ClusterConnect,
 	flow *v1alpha1.NifiDataflow) (*v1alpha1.NifiDataflow, error) {
 
@@ -541,5 +1014,7 @@ func (r *NifiDataflowReconciler) finalizeNifiDataflow(flow *v1alpha1.NifiDataflo
 		r.Log.Info("Dataflow deleted")
 	}
 
+	metrics.DeleteDataflowStatistics(flow)
+
 	return nil
 }
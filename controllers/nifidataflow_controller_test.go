@@ -0,0 +1,293 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvaluateSchedule(t *testing.T) {
+	batchWindow := &v1alpha1.DataflowSchedule{Start: "0 20 * * *", Stop: "0 6 * * *"}
+	inWindow := time.Date(2021, 3, 10, 22, 30, 0, 0, time.UTC)
+	outOfWindow := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name             string
+		schedule         *v1alpha1.DataflowSchedule
+		override         string
+		now              time.Time
+		expectedRunning  bool
+		expectedSchedule *v1alpha1.DataflowScheduleStatus
+		expectedError    bool
+	}{
+		{
+			name:            "no schedule",
+			now:             inWindow,
+			expectedRunning: true,
+		},
+		{
+			name:            "within the window",
+			schedule:        batchWindow,
+			now:             inWindow,
+			expectedRunning: true,
+			expectedSchedule: &v1alpha1.DataflowScheduleStatus{
+				InWindow:           true,
+				NextTransitionTime: "2021-03-11T06:00:00Z",
+				NextTransition:     v1alpha1.ScheduleStop,
+			},
+		},
+		{
+			name:            "outside of the window",
+			schedule:        batchWindow,
+			now:             outOfWindow,
+			expectedRunning: false,
+			expectedSchedule: &v1alpha1.DataflowScheduleStatus{
+				NextTransitionTime: "2021-03-10T20:00:00Z",
+				NextTransition:     v1alpha1.ScheduleStart,
+			},
+		},
+		{
+			name:            "started outside of the window",
+			schedule:        batchWindow,
+			override:        v1alpha1.ScheduleStart,
+			now:             outOfWindow,
+			expectedRunning: true,
+			expectedSchedule: &v1alpha1.DataflowScheduleStatus{
+				Override:           v1alpha1.ScheduleStart,
+				NextTransitionTime: "2021-03-10T20:00:00Z",
+				NextTransition:     v1alpha1.ScheduleStart,
+			},
+		},
+		{
+			name:            "stopped within the window",
+			schedule:        batchWindow,
+			override:        v1alpha1.ScheduleStop,
+			now:             inWindow,
+			expectedRunning: false,
+			expectedSchedule: &v1alpha1.DataflowScheduleStatus{
+				InWindow:           true,
+				Override:           v1alpha1.ScheduleStop,
+				NextTransitionTime: "2021-03-11T06:00:00Z",
+				NextTransition:     v1alpha1.ScheduleStop,
+			},
+		},
+		{
+			name:             "stopped without schedule",
+			override:         v1alpha1.ScheduleStop,
+			now:              inWindow,
+			expectedRunning:  false,
+			expectedSchedule: &v1alpha1.DataflowScheduleStatus{InWindow: true, Override: v1alpha1.ScheduleStop},
+		},
+		{
+			name:          "invalid override",
+			schedule:      batchWindow,
+			override:      "pause",
+			now:           inWindow,
+			expectedError: true,
+		},
+		{
+			name:            "window in a timezone",
+			schedule:        &v1alpha1.DataflowSchedule{Start: "0 20 * * *", Stop: "0 6 * * *", Timezone: "Europe/Paris"},
+			now:             time.Date(2021, 3, 10, 19, 30, 0, 0, time.UTC),
+			expectedRunning: true,
+			expectedSchedule: &v1alpha1.DataflowScheduleStatus{
+				InWindow:           true,
+				NextTransitionTime: "2021-03-11T05:00:00Z",
+				NextTransition:     v1alpha1.ScheduleStop,
+			},
+		},
+		{
+			name: "window with CRON_TZ expressions",
+			schedule: &v1alpha1.DataflowSchedule{
+				Start: "CRON_TZ=Europe/Paris 0 20 * * *",
+				Stop:  "CRON_TZ=Europe/Paris 0 6 * * *",
+			},
+			now:             time.Date(2021, 3, 10, 19, 30, 0, 0, time.UTC),
+			expectedRunning: true,
+			expectedSchedule: &v1alpha1.DataflowScheduleStatus{
+				InWindow:           true,
+				NextTransitionTime: "2021-03-11T05:00:00Z",
+				NextTransition:     v1alpha1.ScheduleStop,
+			},
+		},
+		{
+			name:          "invalid cron expression",
+			schedule:      &v1alpha1.DataflowSchedule{Start: "0 20 * *", Stop: "0 6 * * *"},
+			now:           inWindow,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			flow := &v1alpha1.NifiDataflow{}
+			flow.Spec.Schedule = test.schedule
+			if test.override != "" {
+				flow.SetAnnotations(map[string]string{v1alpha1.ScheduleOverrideAnnotation: test.override})
+			}
+			// the status of a removed schedule is reset
+			flow.Status.Schedule = &v1alpha1.DataflowScheduleStatus{Override: v1alpha1.ScheduleStart}
+
+			running, err := evaluateSchedule(flow, test.now)
+			if test.expectedError {
+				if err == nil {
+					t.Error("Expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Expected no error, got:", err)
+			}
+			if running != test.expectedRunning {
+				t.Errorf("Expected running %v, got: %v", test.expectedRunning, running)
+			}
+			if (flow.Status.Schedule == nil) != (test.expectedSchedule == nil) ||
+				(test.expectedSchedule != nil && *flow.Status.Schedule != *test.expectedSchedule) {
+				t.Errorf("Expected schedule status %+v, got: %+v", test.expectedSchedule, flow.Status.Schedule)
+			}
+			if running != isScheduleRunning(flow.Status.Schedule) {
+				t.Error("Expected the recorded schedule to give the same running state")
+			}
+		})
+	}
+}
+
+func TestScheduleRequeueInterval(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	interval := 5 * time.Minute
+
+	testCases := []struct {
+		name               string
+		nextTransitionTime string
+		expected           time.Duration
+	}{
+		{"no transition", "", interval},
+		{"transition before the interval", "2021-03-10T12:01:00Z", time.Minute + time.Second},
+		{"transition after the interval", "2021-03-10T20:00:00Z", interval},
+		{"transition passed", "2021-03-10T11:59:00Z", time.Second},
+		{"invalid transition time", "10/03/2021 20:00", interval},
+	}
+
+	for _, test := range testCases {
+		flow := &v1alpha1.NifiDataflow{}
+		flow.Status.Schedule = &v1alpha1.DataflowScheduleStatus{NextTransitionTime: test.nextTransitionTime}
+		if requeue := scheduleRequeueInterval(flow, interval, now); requeue != test.expected {
+			t.Errorf("%s: expected requeue interval %s, got: %s", test.name, test.expected, requeue)
+		}
+	}
+
+	if requeue := scheduleRequeueInterval(&v1alpha1.NifiDataflow{}, interval, now); requeue != interval {
+		t.Errorf("Expected requeue interval %s without schedule, got: %s", interval, requeue)
+	}
+}
+
+func TestIsScheduleRunning(t *testing.T) {
+	testCases := []struct {
+		status   *v1alpha1.DataflowScheduleStatus
+		expected bool
+	}{
+		{nil, true},
+		{&v1alpha1.DataflowScheduleStatus{InWindow: true}, true},
+		{&v1alpha1.DataflowScheduleStatus{InWindow: false}, false},
+		{&v1alpha1.DataflowScheduleStatus{InWindow: false, Override: v1alpha1.ScheduleStart}, true},
+		{&v1alpha1.DataflowScheduleStatus{InWindow: true, Override: v1alpha1.ScheduleStop}, false},
+	}
+
+	for _, test := range testCases {
+		if running := isScheduleRunning(test.status); running != test.expected {
+			t.Errorf("Expected running %v for %+v, got: %v", test.expected, test.status, running)
+		}
+	}
+}
+
+func TestFlowStatisticsChanged(t *testing.T) {
+	now := time.Now()
+	previous := v1alpha1.FlowStatistics{
+		FlowFilesQueued: 10,
+		RunningCount:    3,
+		LastRefreshed:   "10:00:00 UTC",
+		UpdateTime:      now.Add(-time.Minute).UTC().Format(time.RFC3339),
+	}
+
+	if !flowStatisticsChanged(nil, previous, now) {
+		t.Error("Expected a change for a dataflow without statistics")
+	}
+
+	current := previous
+	current.LastRefreshed = "10:00:05 UTC"
+	current.UpdateTime = ""
+	if flowStatisticsChanged(&previous, current, now) {
+		t.Error("Expected no change when only the refresh time changed")
+	}
+
+	current.FlowFilesQueued = 20
+	if flowStatisticsChanged(&previous, current, now) {
+		t.Error("Expected the runtime statistics not to be stored before the update interval")
+	}
+	if !flowStatisticsChanged(&previous, current, now.Add(flowStatisticsUpdateInterval)) {
+		t.Error("Expected the runtime statistics to be stored after the update interval")
+	}
+
+	current = previous
+	current.RunningCount = 2
+	current.InvalidCount = 1
+	if !flowStatisticsChanged(&previous, current, now) {
+		t.Error("Expected the component counts to be stored immediately")
+	}
+
+	current = previous
+	current.FlowFilesQueued = 20
+	previous.UpdateTime = ""
+	if !flowStatisticsChanged(&previous, current, now) {
+		t.Error("Expected the statistics to be stored without previous update time")
+	}
+}
+
+func TestFindProcessGroupOwner(t *testing.T) {
+	flow := &v1alpha1.NifiDataflow{ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "nifi"}}
+	flow.Status.ProcessGroupID = "adopted"
+
+	owned := v1alpha1.NifiDataflow{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "nifi"}}
+	owned.Status.ProcessGroupID = "owned"
+	green := v1alpha1.NifiDataflow{ObjectMeta: metav1.ObjectMeta{Name: "green", Namespace: "other"}}
+	green.Status.ProcessGroupID = "blue"
+	green.Status.BlueGreen = &v1alpha1.BlueGreenStatus{ProcessGroupID: "green"}
+	dataflows := []v1alpha1.NifiDataflow{*flow, owned, green}
+
+	testCases := []struct {
+		processGroupId string
+		expectedOwner  string
+	}{
+		{processGroupId: "adopted"},
+		{processGroupId: "free"},
+		{processGroupId: "owned", expectedOwner: "owner"},
+		{processGroupId: "green", expectedOwner: "green"},
+	}
+
+	for _, test := range testCases {
+		owner := findProcessGroupOwner(dataflows, flow, test.processGroupId)
+		var ownerName string
+		if owner != nil {
+			ownerName = owner.Name
+		}
+		if ownerName != test.expectedOwner {
+			t.Errorf("Expected process group %s to be owned by %q, got: %q", test.processGroupId, test.expectedOwner, ownerName)
+		}
+	}
+}
@@ -30,9 +30,16 @@ import (
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -45,6 +52,13 @@ import (
 
 var parameterContextFinalizer = "nifiparametercontexts.nifi.orange.com/finalizer"
 
+const (
+	// parameterSourcesIndex indexes the parameter contexts by the ConfigMaps and Secrets sourcing their values
+	parameterSourcesIndex = "spec.parameterSources"
+	configMapSourceKind   = "ConfigMap"
+	secretSourceKind      = "Secret"
+)
+
 // NifiParameterContextReconciler reconciles a NifiParameterContext object
 type NifiParameterContextReconciler struct {
 	client.Client
@@ -126,6 +140,15 @@ func (r *NifiParameterContextReconciler) Reconcile(ctx context.Context, req ctrl
 		parameterSecrets = append(parameterSecrets, secret)
 	}
 
+	// Resolve the parameter values sourced from ConfigMaps and Secrets
+	var resolved *v1alpha1.NifiParameterContext
+	var sourceVersions []string
+	if resolved, sourceVersions, err = r.resolveParameterValues(instance); err != nil && !k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceParameterValueError",
+			fmt.Sprintf("Failed to resolve the parameter values of %s : %s", instance.Name, err.Error()))
+		return RequeueWithError(r.Log, "failed to resolve parameter values", err)
+	}
+
 	// Prepare cluster connection configurations
 	var clientConfig *clientconfig.NifiConfig
 	var clusterConnect clientconfig.ClusterConnect
@@ -209,9 +232,48 @@ func (r *NifiParameterContextReconciler) Reconcile(ctx context.Context, req ctrl
 		return RequeueAfter(interval)
 	}
 
+	// Get the inherited parameter contexts, which must be created on the same cluster first.
+	var inheritedParameterContexts []*v1alpha1.NifiParameterContext
+	for _, inheritedRef := range instance.Spec.InheritedParameterContexts {
+		inheritedNamespace := GetParameterContextRefNamespace(instance.Namespace, inheritedRef)
+		var inherited *v1alpha1.NifiParameterContext
+		if inherited, err = k8sutil.LookupNifiParameterContext(r.Client, inheritedRef.Name, inheritedNamespace); err != nil {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceParameterContextError",
+				fmt.Sprintf("Failed to lookup inherited parameter context : %s in %s",
+					inheritedRef.Name, inheritedNamespace))
+			return RequeueWithError(r.Log, "failed to lookup inherited parameter context", err)
+		}
+
+		inheritedClusterRef := inherited.Spec.ClusterRef
+		inheritedClusterRef.Namespace = GetClusterRefNamespace(inherited.Namespace, inherited.Spec.ClusterRef)
+		if !v1alpha1.ClusterRefsEquals([]v1alpha1.ClusterReference{clusterRef, inheritedClusterRef}) {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceClusterError",
+				fmt.Sprintf("The inherited parameter context %s in %s is not linked to the cluster %s in %s",
+					inheritedRef.Name, inheritedNamespace, clusterRef.Name, clusterRef.Namespace))
+			return RequeueWithError(
+				r.Log,
+				"failed to lookup inherited parameter context, due to inconsistency",
+				errors.New("inconsistent cluster references"))
+		}
+
+		if inherited.Status.Id == "" {
+			r.Log.Info("Inherited parameter context is not ready yet, will wait until it is.")
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "ReferenceParameterContextNotReady",
+				fmt.Sprintf("The inherited parameter context is not ready yet : %s in %s",
+					inheritedRef.Name, inheritedNamespace))
+			return RequeueAfter(interval)
+		}
+		inheritedParameterContexts = append(inheritedParameterContexts, inherited)
+	}
+
 	r.Recorder.Event(instance, corev1.EventTypeNormal, "Reconciling",
 		fmt.Sprintf("Reconciling parameter context %s", instance.Name))
 
+	// The parameter context is created and updated by its parameter provider, only its id is adopted.
+	if instance.Spec.ParameterProvider != nil {
+		return r.reconcileProvidedParameterContext(ctx, clusterConnect, clusterRef, instance, interval)
+	}
+
 	// Check if the NiFi parameter context already exist
 	exist, err := parametercontext.ExistParameterContext(instance, clientConfig)
 	if err != nil {
@@ -223,7 +285,8 @@ func (r *NifiParameterContextReconciler) Reconcile(ctx context.Context, req ctrl
 		r.Recorder.Event(instance, corev1.EventTypeNormal, "Creating",
 			fmt.Sprintf("Creating parameter context %s", instance.Name))
 
-		status, err := parametercontext.CreateParameterContext(instance, parameterSecrets, clientConfig)
+		status, err := parametercontext.CreateParameterContext(resolved, parameterSecrets,
+			sensitiveSourcesVersion(instance, parameterSecrets, sourceVersions), clientConfig)
 		if err != nil {
 			return RequeueWithError(r.Log, "failure creating parameter context", err)
 		}
@@ -240,7 +303,8 @@ func (r *NifiParameterContextReconciler) Reconcile(ctx context.Context, req ctrl
 	// Sync ParameterContext resource with NiFi side component
 	r.Recorder.Event(instance, corev1.EventTypeNormal, "Synchronizing",
 		fmt.Sprintf("Synchronizing parameter context %s", instance.Name))
-	status, err := parametercontext.SyncParameterContext(instance, parameterSecrets, clientConfig)
+	status, err := parametercontext.SyncParameterContext(resolved, parameterSecrets, inheritedParameterContexts,
+		sensitiveSourcesVersion(instance, parameterSecrets, sourceVersions), clientConfig)
 	if status != nil {
 		instance.Status = *status
 		if err := r.Client.Status().Update(ctx, instance); err != nil {
@@ -261,6 +325,14 @@ func (r *NifiParameterContextReconciler) Reconcile(ctx context.Context, req ctrl
 	r.Recorder.Event(instance, corev1.EventTypeNormal, "Synchronized",
 		fmt.Sprintf("Synchronized parameter context %s", instance.Name))
 
+	return r.ensureParameterContext(ctx, clusterConnect, instance, interval)
+}
+
+// ensureParameterContext ensures the cluster label and the finalizer of a parameter context synchronized with NiFi.
+func (r *NifiParameterContextReconciler) ensureParameterContext(ctx context.Context, clusterConnect clientconfig.ClusterConnect,
+	instance *v1alpha1.NifiParameterContext, interval time.Duration) (ctrl.Result, error) {
+	var err error
+
 	// Ensure NifiCluster label
 	if instance, err = r.ensureClusterLabel(ctx, clusterConnect, instance); err != nil {
 		return RequeueWithError(r.Log, "failed to ensure NifiCluster label on parameter context", err)
@@ -285,13 +357,210 @@ func (r *NifiParameterContextReconciler) Reconcile(ctx context.Context, req ctrl
 	return RequeueAfter(interval)
 }
 
+// reconcileProvidedParameterContext adopts the NiFi parameter context created by the referenced parameter provider
+// when applying the parameter group of the parameter context.
+func (r *NifiParameterContextReconciler) reconcileProvidedParameterContext(ctx context.Context,
+	clusterConnect clientconfig.ClusterConnect, clusterRef v1alpha1.ClusterReference,
+	instance *v1alpha1.NifiParameterContext, interval time.Duration) (ctrl.Result, error) {
+
+	providerRef := instance.Spec.ParameterProvider.ProviderRef
+	providerNamespace := GetParameterProviderRefNamespace(instance.Namespace, providerRef)
+	parameterProvider, err := k8sutil.LookupNifiParameterProvider(r.Client, providerRef.Name, providerNamespace)
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceParameterProviderError",
+			fmt.Sprintf("Failed to lookup reference parameter provider : %s in %s",
+				providerRef.Name, providerNamespace))
+		return RequeueWithError(r.Log, "failed to lookup referenced parameter provider", err)
+	}
+
+	providerClusterRef := parameterProvider.Spec.ClusterRef
+	providerClusterRef.Namespace = GetClusterRefNamespace(parameterProvider.Namespace, parameterProvider.Spec.ClusterRef)
+	if !v1alpha1.ClusterRefsEquals([]v1alpha1.ClusterReference{clusterRef, providerClusterRef}) {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceClusterError",
+			fmt.Sprintf("The parameter provider %s in %s is not linked to the cluster %s in %s",
+				providerRef.Name, providerNamespace, clusterRef.Name, clusterRef.Namespace))
+		return RequeueWithError(
+			r.Log,
+			"failed to lookup referenced parameter provider, due to inconsistency",
+			errors.New("inconsistent cluster references"))
+	}
+
+	id := parameterProvider.GetParameterContextId(instance.Name)
+	if id == "" {
+		r.Log.Info("Parameter provider has not provided the parameter context yet, will wait until it is.")
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "ReferenceParameterProviderNotReady",
+			fmt.Sprintf("The parameter provider has not provided the parameter context yet : %s in %s",
+				providerRef.Name, providerNamespace))
+		return RequeueAfter(interval)
+	}
+
+	if instance.Status.Id != id {
+		instance.Status.Id = id
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiParameterContext status", err)
+		}
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "Synchronized",
+			fmt.Sprintf("Parameter context %s provided by the parameter provider %s", instance.Name, providerRef.Name))
+	}
+
+	return r.ensureParameterContext(ctx, clusterConnect, instance, interval)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *NifiParameterContextReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Index the parameter contexts by the ConfigMaps and Secrets sourcing their values, so that a change of one of
+	// them only looks up the parameter contexts referencing it.
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.NifiParameterContext{}, parameterSourcesIndex,
+		func(obj client.Object) []string {
+			return parameterSources(obj.(*v1alpha1.NifiParameterContext))
+		}); err != nil {
+		return err
+	}
+
+	// Only the metadata of the ConfigMaps and Secrets are watched, their content is read on reconcile.
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.NifiParameterContext{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.referencingParameterContexts(configMapSourceKind)),
+			builder.OnlyMetadata, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.referencingParameterContexts(secretSourceKind)),
+			builder.OnlyMetadata, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
 		Complete(r)
 }
 
+// referencingParameterContexts returns a function listing the parameter contexts whose parameter values are sourced
+// from the given ConfigMap or Secret, to re-sync them when it changes.
+func (r *NifiParameterContextReconciler) referencingParameterContexts(kind string) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		parameterContexts := &v1alpha1.NifiParameterContextList{}
+		if err := r.Client.List(context.TODO(), parameterContexts,
+			client.MatchingFields{parameterSourcesIndex: parameterSourceKey(kind, obj.GetNamespace(), obj.GetName())}); err != nil {
+			r.Log.Error(err, "failed to list the parameter contexts")
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, pc := range parameterContexts.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: pc.Name, Namespace: pc.Namespace},
+			})
+		}
+		return requests
+	}
+}
+
+// parameterSources returns the keys of the ConfigMaps and Secrets sourcing the parameter values of the parameter context.
+func parameterSources(parameterContext *v1alpha1.NifiParameterContext) []string {
+	var sources []string
+	for _, secretRef := range parameterContext.Spec.SecretRefs {
+		sources = append(sources,
+			parameterSourceKey(secretSourceKind, GetSecretRefNamespace(parameterContext.Namespace, secretRef), secretRef.Name))
+	}
+
+	for _, parameter := range parameterContext.Spec.Parameters {
+		if parameter.ValueFrom == nil {
+			continue
+		}
+		if ref := parameter.ValueFrom.SecretKeyRef; ref != nil {
+			sources = append(sources, parameterSourceKey(secretSourceKind, parameterContext.Namespace, ref.Name))
+		}
+		if ref := parameter.ValueFrom.ConfigMapKeyRef; ref != nil {
+			sources = append(sources, parameterSourceKey(configMapSourceKind, parameterContext.Namespace, ref.Name))
+		}
+	}
+	return sources
+}
+
+func parameterSourceKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// sensitiveSourcesVersion returns a hash of what the sensitive parameter values are taken from: the inline sensitive
+// values and the resource versions of the ConfigMaps and Secrets sourcing sensitive values. NiFi never returns the
+// sensitive values, their changes are detected from it without storing anything derived from the sourced ones.
+func sensitiveSourcesVersion(parameterContext *v1alpha1.NifiParameterContext, parameterSecrets []*corev1.Secret,
+	sourceVersions []string) string {
+
+	var versions []string
+	for _, parameter := range parameterContext.Spec.Parameters {
+		if parameter.Sensitive && parameter.ValueFrom == nil && parameter.Value != nil {
+			versions = append(versions, fmt.Sprintf("parameter/%s=%s", parameter.Name, *parameter.Value))
+		}
+	}
+	for _, secret := range parameterSecrets {
+		versions = append(versions,
+			fmt.Sprintf("%s=%s", parameterSourceKey(secretSourceKind, secret.Namespace, secret.Name), secret.ResourceVersion))
+	}
+	versions = append(versions, sourceVersions...)
+	sort.Strings(versions)
+	return fmt.Sprintf("%x", util.Hash(strings.Join(versions, "\n")))
+}
+
+// resolveParameterValues returns a copy of the parameter context with the values sourced from ConfigMaps and Secrets,
+// which must never be stored into the resource, along with the versions of the sources of the sensitive parameters.
+func (r *NifiParameterContextReconciler) resolveParameterValues(
+	parameterContext *v1alpha1.NifiParameterContext) (*v1alpha1.NifiParameterContext, []string, error) {
+
+	resolved := parameterContext.DeepCopy()
+	var sourceVersions []string
+	for i, parameter := range resolved.Spec.Parameters {
+		if parameter.ValueFrom == nil {
+			continue
+		}
+		value, version, err := r.parameterSourceValue(parameterContext.Namespace, parameter.ValueFrom)
+		if err != nil {
+			return resolved, nil, errors.WrapIf(err, fmt.Sprintf("parameter %s", parameter.Name))
+		}
+		resolved.Spec.Parameters[i].Value = value
+		if parameter.Sensitive {
+			sourceVersions = append(sourceVersions, fmt.Sprintf("%s=%s", parameter.Name, version))
+		}
+	}
+	return resolved, sourceVersions, nil
+}
+
+// parameterSourceValue returns the value of a parameter sourced from a ConfigMap or a Secret key, along with the
+// resource version of the source, empty when an optional source is missing.
+func (r *NifiParameterContextReconciler) parameterSourceValue(namespace string, source *v1alpha1.ParameterSource) (*string, string, error) {
+	if ref := source.ConfigMapKeyRef; ref != nil {
+		optional := ref.Optional != nil && *ref.Optional
+		configMap, err := k8sutil.LookupConfigMap(r.Client, ref.Name, namespace)
+		if err != nil {
+			if apierrors.IsNotFound(err) && optional {
+				return nil, "", nil
+			}
+			return nil, "", err
+		}
+		if value, ok := configMap.Data[ref.Key]; ok {
+			return &value, configMap.ResourceVersion, nil
+		}
+		if optional {
+			return nil, configMap.ResourceVersion, nil
+		}
+		return nil, "", errors.Errorf("key %s not found in ConfigMap %s/%s", ref.Key, namespace, ref.Name)
+	}
+
+	if ref := source.SecretKeyRef; ref != nil {
+		optional := ref.Optional != nil && *ref.Optional
+		secret, err := k8sutil.LookupSecret(r.Client, ref.Name, namespace)
+		if err != nil {
+			if apierrors.IsNotFound(err) && optional {
+				return nil, "", nil
+			}
+			return nil, "", err
+		}
+		if data, ok := secret.Data[ref.Key]; ok {
+			value := string(data)
+			return &value, secret.ResourceVersion, nil
+		}
+		if optional {
+			return nil, secret.ResourceVersion, nil
+		}
+		return nil, "", errors.Errorf("key %s not found in Secret %s/%s", ref.Key, namespace, ref.Name)
+	}
+
+	return nil, "", nil
+}
+
 func (r *NifiParameterContextReconciler) ensureClusterLabel(ctx context.Context, cluster clientconfig.ClusterConnect,
 	parameterContext *v1alpha1.NifiParameterContext) (*v1alpha1.NifiParameterContext, error) {
 
@@ -324,6 +593,18 @@ func (r *NifiParameterContextReconciler) checkFinalizers(
 	r.Log.Info("NiFi parameter context is marked for deletion")
 	var err error
 	if util.StringSliceContains(parameterContext.GetFinalizers(), parameterContextFinalizer) {
+		// A parameter context can't be removed while others inherit from it.
+		var inheritingParameterContexts []string
+		if inheritingParameterContexts, err = r.inheritingParameterContexts(ctx, parameterContext); err != nil {
+			return RequeueWithError(r.Log, "failed to list the inheriting parameter contexts", err)
+		}
+		if len(inheritingParameterContexts) > 0 {
+			r.Recorder.Event(parameterContext, corev1.EventTypeWarning, "RemoveError",
+				fmt.Sprintf("Parameter context %s is still inherited by : %s",
+					parameterContext.Name, strings.Join(inheritingParameterContexts, ", ")))
+			return RequeueAfter(util.GetRequeueInterval(r.RequeueInterval, r.RequeueOffset))
+		}
+
 		if err = r.finalizeNifiParameterContext(parameterContext, parameterSecrets, config); err != nil {
 			return RequeueWithError(r.Log, "failed to finalize parameter context", err)
 		}
@@ -334,6 +615,28 @@ func (r *NifiParameterContextReconciler) checkFinalizers(
 	return Reconciled()
 }
 
+// inheritingParameterContexts lists the namespaced names of the parameter contexts inheriting from the given one.
+func (r *NifiParameterContextReconciler) inheritingParameterContexts(ctx context.Context,
+	parameterContext *v1alpha1.NifiParameterContext) ([]string, error) {
+
+	parameterContexts := &v1alpha1.NifiParameterContextList{}
+	if err := r.Client.List(ctx, parameterContexts); err != nil {
+		return nil, err
+	}
+
+	var inheriting []string
+	for _, pc := range parameterContexts.Items {
+		for _, inheritedRef := range pc.Spec.InheritedParameterContexts {
+			if inheritedRef.Name == parameterContext.Name &&
+				GetParameterContextRefNamespace(pc.Namespace, inheritedRef) == parameterContext.Namespace {
+				inheriting = append(inheriting, fmt.Sprintf("%s/%s", pc.Namespace, pc.Name))
+				break
+			}
+		}
+	}
+	return inheriting, nil
+}
+
 func (r *NifiParameterContextReconciler) removeFinalizer(ctx context.Context, flow *v1alpha1.NifiParameterContext) error {
 	flow.SetFinalizers(util.StringSliceRemove(flow.GetFinalizers(), parameterContextFinalizer))
 	_, err := r.updateAndFetchLatest(ctx, flow)
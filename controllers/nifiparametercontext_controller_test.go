@@ -0,0 +1,191 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newParameterContextWithSources(parameters ...v1alpha1.Parameter) *v1alpha1.NifiParameterContext {
+	return &v1alpha1.NifiParameterContext{
+		ObjectMeta: metav1.ObjectMeta{Name: "pc", Namespace: "test-namespace"},
+		Spec:       v1alpha1.NifiParameterContextSpec{Parameters: parameters},
+	}
+}
+
+func configMapParameter(name, configMap, key string, optional bool) v1alpha1.Parameter {
+	return v1alpha1.Parameter{
+		Name: name,
+		ValueFrom: &v1alpha1.ParameterSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configMap},
+			Key:                  key,
+			Optional:             &optional,
+		}},
+	}
+}
+
+func secretParameter(name, secret, key string, optional bool) v1alpha1.Parameter {
+	return v1alpha1.Parameter{
+		Name:      name,
+		Sensitive: true,
+		ValueFrom: &v1alpha1.ParameterSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+			Key:                  key,
+			Optional:             &optional,
+		}},
+	}
+}
+
+func TestResolveParameterValues(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "test-namespace"},
+		Data:       map[string]string{"url": "http://test"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "test-namespace"},
+		Data:       map[string][]byte{"password": []byte("secret-password")},
+	}
+	r := &NifiParameterContextReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme, configMap, secret),
+		Log:    log,
+	}
+
+	value := "inline"
+	parameterContext := newParameterContextWithSources(
+		v1alpha1.Parameter{Name: "inline", Value: &value},
+		configMapParameter("url", "cm", "url", false),
+		secretParameter("password", "secret", "password", false),
+		configMapParameter("optional-key", "cm", "missing", true),
+		secretParameter("optional-secret", "missing", "password", true),
+	)
+
+	resolved, sourceVersions, err := r.resolveParameterValues(parameterContext)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+
+	expected := map[string]*string{"inline": &value, "optional-key": nil, "optional-secret": nil}
+	url, password := "http://test", "secret-password"
+	expected["url"] = &url
+	expected["password"] = &password
+	for _, parameter := range resolved.Spec.Parameters {
+		if !reflect.DeepEqual(parameter.Value, expected[parameter.Name]) {
+			t.Errorf("Expected value %v for parameter %s, got: %v", expected[parameter.Name], parameter.Name, parameter.Value)
+		}
+	}
+
+	for _, parameter := range parameterContext.Spec.Parameters {
+		if parameter.ValueFrom != nil && parameter.Value != nil {
+			t.Error("Expected the sourced values to not be stored into the parameter context, got one for:", parameter.Name)
+		}
+	}
+
+	// only the sensitive parameters are versioned, the missing optional secret has no version
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "secret", Namespace: "test-namespace"}, secret); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	expectedVersions := []string{"password=" + secret.ResourceVersion, "optional-secret="}
+	if !reflect.DeepEqual(sourceVersions, expectedVersions) {
+		t.Errorf("Expected source versions %v, got: %v", expectedVersions, sourceVersions)
+	}
+
+	for _, parameter := range []v1alpha1.Parameter{
+		configMapParameter("missing-key", "cm", "missing", false),
+		configMapParameter("missing-configmap", "missing", "url", false),
+		secretParameter("missing-key", "secret", "missing", false),
+		secretParameter("missing-secret", "missing", "password", false),
+	} {
+		if _, _, err := r.resolveParameterValues(newParameterContextWithSources(parameter)); err == nil {
+			t.Errorf("Expected an error for the required parameter %s", parameter.Name)
+		}
+	}
+}
+
+func TestParameterSources(t *testing.T) {
+	parameterContext := newParameterContextWithSources(
+		v1alpha1.Parameter{Name: "inline"},
+		configMapParameter("url", "cm", "url", false),
+		secretParameter("password", "secret", "password", false),
+	)
+	parameterContext.Spec.SecretRefs = []v1alpha1.SecretReference{
+		{Name: "secret-ref"},
+		{Name: "other-secret-ref", Namespace: "other-namespace"},
+	}
+
+	expected := []string{
+		"Secret/test-namespace/secret-ref",
+		"Secret/other-namespace/other-secret-ref",
+		"ConfigMap/test-namespace/cm",
+		"Secret/test-namespace/secret",
+	}
+	if sources := parameterSources(parameterContext); !reflect.DeepEqual(sources, expected) {
+		t.Errorf("Expected sources %v, got: %v", expected, sources)
+	}
+
+	if sources := parameterSources(newParameterContextWithSources()); len(sources) != 0 {
+		t.Error("Expected no source, got:", sources)
+	}
+}
+
+func TestSensitiveSourcesVersion(t *testing.T) {
+	parameterContext := newParameterContextWithSources()
+	parameterContext.Generation = 1
+	secrets := []*corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "test-namespace", ResourceVersion: "10"}}}
+	value := "value"
+
+	version := sensitiveSourcesVersion(parameterContext, secrets, []string{"b=2", "a=1"})
+	if version != sensitiveSourcesVersion(parameterContext, secrets, []string{"a=1", "b=2"}) {
+		t.Error("Expected the version to not depend on the order of the sources")
+	}
+
+	parameterContext.Generation = 2
+	parameterContext.Spec.Description = "updated"
+	parameterContext.Spec.Parameters = append(parameterContext.Spec.Parameters, v1alpha1.Parameter{Name: "plain", Value: &value})
+	if version != sensitiveSourcesVersion(parameterContext, secrets, []string{"a=1", "b=2"}) {
+		t.Error("Expected the version to not change with the non-sensitive spec changes")
+	}
+
+	parameterContext.Spec.Parameters = append(parameterContext.Spec.Parameters,
+		v1alpha1.Parameter{Name: "inline", Value: &value, Sensitive: true})
+	inlineVersion := sensitiveSourcesVersion(parameterContext, secrets, []string{"a=1", "b=2"})
+	if version == inlineVersion {
+		t.Error("Expected the version to change with an inline sensitive value")
+	}
+	updated := "updated"
+	parameterContext.Spec.Parameters[len(parameterContext.Spec.Parameters)-1].Value = &updated
+	if inlineVersion == sensitiveSourcesVersion(parameterContext, secrets, []string{"a=1", "b=2"}) {
+		t.Error("Expected the version to change with the update of an inline sensitive value")
+	}
+	parameterContext.Spec.Parameters = parameterContext.Spec.Parameters[:len(parameterContext.Spec.Parameters)-2]
+
+	secrets[0].ResourceVersion = "11"
+	if version == sensitiveSourcesVersion(parameterContext, secrets, []string{"a=1", "b=2"}) {
+		t.Error("Expected the version to change with a secret reference")
+	}
+	secrets[0].ResourceVersion = "10"
+
+	if version == sensitiveSourcesVersion(parameterContext, secrets, []string{"a=1", "b=3"}) {
+		t.Error("Expected the version to change with a sensitive parameter source")
+	}
+}
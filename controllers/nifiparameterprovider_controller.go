@@ -0,0 +1,385 @@
+/*
+Copyright 2020.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"emperror.dev/errors"
+	"encoding/json"
+	"fmt"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/parameterprovider"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/k8sutil"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient/config"
+	"github.com/Orange-OpenSource/nifikop/pkg/util"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+)
+
+var parameterProviderFinalizer = "nifiparameterproviders.nifi.orange.com/finalizer"
+
+// NifiParameterProviderReconciler reconciles a NifiParameterProvider object
+type NifiParameterProviderReconciler struct {
+	client.Client
+	Log             logr.Logger
+	Scheme          *runtime.Scheme
+	Recorder        record.EventRecorder
+	RequeueInterval int
+	RequeueOffset   int
+}
+
+// +kubebuilder:rbac:groups=nifi.orange.com,resources=nifiparameterproviders,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nifi.orange.com,resources=nifiparameterproviders/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nifi.orange.com,resources=nifiparameterproviders/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// TODO(user): Modify the Reconcile function to compare the state specified by
+// the NifiParameterProvider object against the actual cluster state, and then
+// perform operations to make the cluster state reflect the state specified by
+// the user.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
+func (r *NifiParameterProviderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = r.Log.WithValues("nifiparameterprovider", req.NamespacedName)
+	interval := util.GetRequeueInterval(r.RequeueInterval, r.RequeueOffset)
+	var err error
+
+	// Fetch the NifiParameterProvider instance
+	var instance = &v1alpha1.NifiParameterProvider{}
+	if err = r.Client.Get(ctx, req.NamespacedName, instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			return Reconciled()
+		}
+		// Error reading the object - requeue the request.
+		return RequeueWithError(r.Log, err.Error(), err)
+	}
+
+	// Get the last configuration viewed by the operator.
+	o, err := patch.DefaultAnnotator.GetOriginalConfiguration(instance)
+	// Create it if not exist.
+	if o == nil {
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(instance); err != nil {
+			return RequeueWithError(r.Log, "could not apply last state to annotation", err)
+		}
+		if err := r.Client.Update(ctx, instance); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiParameterProvider", err)
+		}
+		o, err = patch.DefaultAnnotator.GetOriginalConfiguration(instance)
+	}
+
+	// Check if the cluster reference changed.
+	original := &v1alpha1.NifiParameterProvider{}
+	current := instance.DeepCopy()
+	json.Unmarshal(o, original)
+	if !v1alpha1.ClusterRefsEquals([]v1alpha1.ClusterReference{original.Spec.ClusterRef, instance.Spec.ClusterRef}) {
+		instance.Spec.ClusterRef = original.Spec.ClusterRef
+	}
+
+	// Prepare cluster connection configurations
+	var clientConfig *clientconfig.NifiConfig
+	var clusterConnect clientconfig.ClusterConnect
+
+	// Get the client config manager associated to the cluster ref.
+	clusterRef := instance.Spec.ClusterRef
+	clusterRef.Namespace = GetClusterRefNamespace(instance.Namespace, instance.Spec.ClusterRef)
+	configManager := config.GetClientConfigManager(r.Client, clusterRef)
+
+	// Generate the connect object
+	if clusterConnect, err = configManager.BuildConnect(); err != nil {
+		// This shouldn't trigger anymore, but leaving it here as a safetybelt
+		if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+			r.Log.Info("Cluster is already gone, there is nothing we can do")
+			if err = r.removeFinalizer(ctx, instance); err != nil {
+				return RequeueWithError(r.Log, "failed to remove finalizer", err)
+			}
+			return Reconciled()
+		}
+		// If the referenced cluster no more exist, just skip the deletion requirement in cluster ref change case.
+		if !v1alpha1.ClusterRefsEquals([]v1alpha1.ClusterReference{instance.Spec.ClusterRef, current.Spec.ClusterRef}) {
+			if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(current); err != nil {
+				return RequeueWithError(r.Log, "could not apply last state to annotation", err)
+			}
+			if err := r.Client.Update(ctx, current); err != nil {
+				return RequeueWithError(r.Log, "failed to update NifiParameterProvider", err)
+			}
+			return RequeueAfter(time.Duration(15) * time.Second)
+		}
+
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceClusterError",
+			fmt.Sprintf("Failed to lookup reference cluster : %s in %s",
+				instance.Spec.ClusterRef.Name, clusterRef.Namespace))
+		// the cluster does not exist - should have been caught pre-flight
+		return RequeueWithError(r.Log, "failed to lookup referenced cluster", err)
+	}
+
+	// Generate the client configuration.
+	clientConfig, err = configManager.BuildConfig()
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceClusterError",
+			fmt.Sprintf("Failed to create HTTP client for the referenced cluster : %s in %s",
+				instance.Spec.ClusterRef.Name, clusterRef.Namespace))
+		// the cluster does not exist - should have been caught pre-flight
+		return RequeueWithError(r.Log, "failed to create HTTP client the for referenced cluster", err)
+	}
+
+	// Check if marked for deletion and if so run finalizers
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		return r.checkFinalizers(ctx, r.Log, instance, clientConfig)
+	}
+
+	// Ensure the cluster is ready to receive actions
+	if !clusterConnect.IsReady(r.Log) {
+		r.Log.Info("Cluster is not ready yet, will wait until it is.")
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "ReferenceClusterNotReady",
+			fmt.Sprintf("The referenced cluster is not ready yet : %s in %s",
+				instance.Spec.ClusterRef.Name, clusterConnect.Id()))
+		// the cluster does not exist - should have been caught pre-flight
+		return RequeueAfter(interval)
+	}
+
+	// Ìn case of the cluster reference changed.
+	if !v1alpha1.ClusterRefsEquals([]v1alpha1.ClusterReference{instance.Spec.ClusterRef, current.Spec.ClusterRef}) {
+		// Delete the resource on the previous cluster.
+		if err := parameterprovider.RemoveParameterProvider(instance, clientConfig); err != nil {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "RemoveError",
+				fmt.Sprintf("Failed to delete NifiParameterProvider %s from cluster %s before moving in %s",
+					instance.Name, original.Spec.ClusterRef.Name, original.Spec.ClusterRef.Name))
+			return RequeueWithError(r.Log, "Failed to delete NifiParameterProvider before moving", err)
+		}
+		// Update the last view configuration to the current one.
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(current); err != nil {
+			return RequeueWithError(r.Log, "could not apply last state to annotation", err)
+		}
+		if err := r.Client.Update(ctx, current); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiParameterProvider", err)
+		}
+		return RequeueAfter(interval)
+	}
+
+	r.Recorder.Event(instance, corev1.EventTypeNormal, "Reconciling",
+		fmt.Sprintf("Reconciling parameter provider %s", instance.Name))
+
+	// Check if the NiFi parameter provider already exist
+	exist, err := parameterprovider.ExistParameterProvider(instance, clientConfig)
+	if err != nil {
+		return RequeueWithError(r.Log, "failure checking for existing parameter provider", err)
+	}
+
+	if !exist {
+		// Create NiFi parameter provider
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "Creating",
+			fmt.Sprintf("Creating parameter provider %s", instance.Name))
+		status, err := parameterprovider.CreateParameterProvider(instance, clientConfig)
+		if err != nil {
+			return RequeueWithError(r.Log, "failure creating parameter provider", err)
+		}
+
+		instance.Status = *status
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiParameterProvider status", err)
+		}
+
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "Created",
+			fmt.Sprintf("Created parameter provider %s", instance.Name))
+
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(instance); err != nil {
+			return RequeueWithError(r.Log, "could not apply last state to annotation", err)
+		}
+		if err := r.Client.Update(ctx, instance); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiParameterProvider", err)
+		}
+	}
+
+	// List the parameter contexts provided by the parameter provider
+	parameterContexts, err := r.providedParameterContexts(ctx, instance)
+	if err != nil {
+		return RequeueWithError(r.Log, "failed to list the parameter contexts provided by the parameter provider", err)
+	}
+
+	// Sync ParameterProvider resource with NiFi side component
+	r.Recorder.Event(instance, corev1.EventTypeNormal, "Synchronizing",
+		fmt.Sprintf("Synchronizing parameter provider %s", instance.Name))
+	status, err := parameterprovider.SyncParameterProvider(instance, parameterContexts, clientConfig)
+	if status != nil {
+		instance.Status = *status
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return RequeueWithError(r.Log, "failed to update NifiParameterProvider status", err)
+		}
+	}
+	if err != nil {
+		switch errors.Cause(err).(type) {
+		case errorfactory.NifiParameterProviderApplyRequestRunning:
+			return RequeueAfter(interval / 3)
+		default:
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "SynchronizingFailed",
+				fmt.Sprintf("Synchronizing parameter provider %s failed", instance.Name))
+			return RequeueWithError(r.Log, "failed to sync NifiParameterProvider", err)
+		}
+	}
+
+	r.Recorder.Event(instance, corev1.EventTypeNormal, "Synchronized",
+		fmt.Sprintf("Synchronized parameter provider %s", instance.Name))
+	// Ensure NifiCluster label
+	if instance, err = r.ensureClusterLabel(ctx, clusterConnect, instance); err != nil {
+		return RequeueWithError(r.Log, "failed to ensure NifiCluster label on parameter provider", err)
+	}
+
+	// Ensure finalizer for cleanup on deletion
+	if !util.StringSliceContains(instance.GetFinalizers(), parameterProviderFinalizer) {
+		r.Log.Info("Adding Finalizer for NifiParameterProvider")
+		instance.SetFinalizers(append(instance.GetFinalizers(), parameterProviderFinalizer))
+	}
+
+	// Push any changes
+	if instance, err = r.updateAndFetchLatest(ctx, instance); err != nil {
+		return RequeueWithError(r.Log, "failed to update NifiParameterProvider", err)
+	}
+
+	r.Recorder.Event(instance, corev1.EventTypeNormal, "Reconciled",
+		fmt.Sprintf("Reconciling parameter provider %s", instance.Name))
+
+	r.Log.Info("Ensured Parameter Provider")
+
+	return RequeueAfter(interval)
+}
+
+// SetupWithManager sets up the controller with the Manager. Only the spec changes of the parameter contexts are
+// watched, their status is updated on each of their reconciliations.
+func (r *NifiParameterProviderReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.NifiParameterProvider{}).
+		Watches(&source.Kind{Type: &v1alpha1.NifiParameterContext{}}, handler.EnqueueRequestsFromMapFunc(providerOfParameterContext),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+// providerOfParameterContext returns the parameter provider providing the parameters of the given parameter context,
+// to apply its parameter group configuration when it changes.
+func providerOfParameterContext(obj client.Object) []reconcile.Request {
+	parameterContext, ok := obj.(*v1alpha1.NifiParameterContext)
+	if !ok || parameterContext.Spec.ParameterProvider == nil {
+		return nil
+	}
+
+	providerRef := parameterContext.Spec.ParameterProvider.ProviderRef
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Name:      providerRef.Name,
+			Namespace: GetParameterProviderRefNamespace(parameterContext.Namespace, providerRef),
+		},
+	}}
+}
+
+// providedParameterContexts lists the parameter contexts whose parameters are provided by the given parameter provider.
+func (r *NifiParameterProviderReconciler) providedParameterContexts(ctx context.Context,
+	parameterProvider *v1alpha1.NifiParameterProvider) ([]*v1alpha1.NifiParameterContext, error) {
+
+	parameterContextList := &v1alpha1.NifiParameterContextList{}
+	if err := r.Client.List(ctx, parameterContextList); err != nil {
+		return nil, err
+	}
+
+	var parameterContexts []*v1alpha1.NifiParameterContext
+	for i, pc := range parameterContextList.Items {
+		providerSource := pc.Spec.ParameterProvider
+		if providerSource == nil || k8sutil.IsMarkedForDeletion(pc.ObjectMeta) {
+			continue
+		}
+		if providerSource.ProviderRef.Name == parameterProvider.Name &&
+			GetParameterProviderRefNamespace(pc.Namespace, providerSource.ProviderRef) == parameterProvider.Namespace {
+			parameterContexts = append(parameterContexts, &parameterContextList.Items[i])
+		}
+	}
+	return parameterContexts, nil
+}
+
+func (r *NifiParameterProviderReconciler) ensureClusterLabel(ctx context.Context, cluster clientconfig.ClusterConnect,
+	parameterProvider *v1alpha1.NifiParameterProvider) (*v1alpha1.NifiParameterProvider, error) {
+
+	labels := ApplyClusterReferenceLabel(cluster, parameterProvider.GetLabels())
+	if !reflect.DeepEqual(labels, parameterProvider.GetLabels()) {
+		parameterProvider.SetLabels(labels)
+		return r.updateAndFetchLatest(ctx, parameterProvider)
+	}
+	return parameterProvider, nil
+}
+
+func (r *NifiParameterProviderReconciler) updateAndFetchLatest(ctx context.Context,
+	parameterProvider *v1alpha1.NifiParameterProvider) (*v1alpha1.NifiParameterProvider, error) {
+
+	typeMeta := parameterProvider.TypeMeta
+	err := r.Client.Update(ctx, parameterProvider)
+	if err != nil {
+		return nil, err
+	}
+	parameterProvider.TypeMeta = typeMeta
+	return parameterProvider, nil
+}
+
+func (r *NifiParameterProviderReconciler) checkFinalizers(ctx context.Context, reqLogger logr.Logger,
+	parameterProvider *v1alpha1.NifiParameterProvider, config *clientconfig.NifiConfig) (reconcile.Result, error) {
+
+	reqLogger.Info("NiFi parameter provider is marked for deletion")
+	var err error
+	if util.StringSliceContains(parameterProvider.GetFinalizers(), parameterProviderFinalizer) {
+		if err = r.finalizeNifiParameterProvider(reqLogger, parameterProvider, config); err != nil {
+			return RequeueWithError(reqLogger, "failed to finalize nifiparameterprovider", err)
+		}
+		if err = r.removeFinalizer(ctx, parameterProvider); err != nil {
+			return RequeueWithError(reqLogger, "failed to remove finalizer from nifiparameterprovider", err)
+		}
+	}
+	return Reconciled()
+}
+
+func (r *NifiParameterProviderReconciler) removeFinalizer(ctx context.Context, parameterProvider *v1alpha1.NifiParameterProvider) error {
+	parameterProvider.SetFinalizers(util.StringSliceRemove(parameterProvider.GetFinalizers(), parameterProviderFinalizer))
+	_, err := r.updateAndFetchLatest(ctx, parameterProvider)
+	return err
+}
+
+func (r *NifiParameterProviderReconciler) finalizeNifiParameterProvider(reqLogger logr.Logger, parameterProvider *v1alpha1.NifiParameterProvider,
+	config *clientconfig.NifiConfig) error {
+
+	if err := parameterprovider.RemoveParameterProvider(parameterProvider, config); err != nil {
+		return err
+	}
+	reqLogger.Info("Delete Parameter provider")
+
+	return nil
+}
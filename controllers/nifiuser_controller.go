@@ -21,6 +21,7 @@ import (
 	"emperror.dev/errors"
 	"encoding/json"
 	"fmt"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/accesspolicies"
 	usercli "github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/user"
 	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
 	"github.com/Orange-OpenSource/nifikop/pkg/k8sutil"
@@ -34,11 +35,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"time"
 
 	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
@@ -62,6 +66,7 @@ type NifiUserReconciler struct {
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cert-manager.io,resources=issuers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cert-manager.io,resources=clusterissuers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -299,10 +304,34 @@ func (r *NifiUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			fmt.Sprintf("Created user %s", instance.Name))
 	}
 
+	// Resolve the components referenced by the access policies, and add the ones granted by NifiAccessPolicies
+	accessPolicies, err := accesspolicies.ResolveAccessPolicies(r.Client, instance.Spec.AccessPolicies, instance.Namespace)
+	if err == nil {
+		var boundAccessPolicies []v1alpha1.AccessPolicy
+		boundAccessPolicies, err = GetBoundAccessPolicies(r.Client, clusterRef, func(accessPolicy *v1alpha1.NifiAccessPolicy) bool {
+			return IsUserBound(accessPolicy, instance.Name, instance.Namespace)
+		})
+		accessPolicies = append(accessPolicies, boundAccessPolicies...)
+	}
+	if err != nil {
+		switch errors.Cause(err).(type) {
+		case errorfactory.ResourceNotReady:
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "ReferenceComponentNotReady",
+				fmt.Sprintf("The component referenced by an access policy is not deployed yet: %s", err.Error()))
+			return RequeueAfter(interval / 3)
+		default:
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceComponentError",
+				fmt.Sprintf("Failed to lookup the component referenced by an access policy: %s", err.Error()))
+			return RequeueWithError(r.Log, "failed to lookup referenced component", err)
+		}
+	}
+	resolved := instance.DeepCopy()
+	resolved.Spec.AccessPolicies = accessPolicies
+
 	// Sync user resource with NiFi side component
 	r.Recorder.Event(instance, corev1.EventTypeNormal, "Synchronizing",
 		fmt.Sprintf("Synchronizing user %s", instance.Name))
-	status, err := usercli.SyncUser(instance, clientConfig)
+	status, err := usercli.SyncUser(resolved, clientConfig)
 	if err != nil {
 		return RequeueWithError(r.Log, "failed to sync NifiUser", err)
 	}
@@ -355,7 +384,14 @@ func (r *NifiUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 func (r *NifiUserReconciler) SetupWithManager(mgr ctrl.Manager, certManagerEnabled bool) error {
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.NifiUser{}).
-		Owns(&corev1.Secret{})
+		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &v1alpha1.NifiDataflow{}}, handler.EnqueueRequestsFromMapFunc(r.referencingUsers),
+			componentReferenceIdChanged).
+		Watches(&source.Kind{Type: &v1alpha1.NifiParameterContext{}}, handler.EnqueueRequestsFromMapFunc(r.referencingUsers),
+			componentReferenceIdChanged).
+		Watches(&source.Kind{Type: &v1alpha1.NifiRegistryClient{}}, handler.EnqueueRequestsFromMapFunc(r.referencingUsers),
+			componentReferenceIdChanged).
+		Watches(&source.Kind{Type: &v1alpha1.NifiAccessPolicy{}}, handler.EnqueueRequestsFromMapFunc(boundUsers))
 
 	if certManagerEnabled {
 		builder.Owns(&certv1.Certificate{})
@@ -364,6 +400,50 @@ func (r *NifiUserReconciler) SetupWithManager(mgr ctrl.Manager, certManagerEnabl
 	return builder.Complete(r)
 }
 
+// referencingUsers lists the users whose access policies reference the given NifiDataflow, NifiParameterContext or
+// NifiRegistryClient, to re-apply them when its NiFi id changes.
+func (r *NifiUserReconciler) referencingUsers(obj client.Object) []reconcile.Request {
+	kind, ok := componentReferenceKind(obj)
+	if !ok {
+		return nil
+	}
+
+	users := &v1alpha1.NifiUserList{}
+	if err := r.Client.List(context.TODO(), users); err != nil {
+		r.Log.Error(err, "failed to list the users")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, user := range users.Items {
+		if IsComponentReferenced(user.Spec.AccessPolicies, user.Namespace, kind, obj.GetName(), obj.GetNamespace()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: user.Name, Namespace: user.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// boundUsers returns the users granted by the given NifiAccessPolicy, to update their access policies when it changes.
+func boundUsers(obj client.Object) []reconcile.Request {
+	accessPolicy, ok := obj.(*v1alpha1.NifiAccessPolicy)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, userRef := range accessPolicy.Spec.UsersRef {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      userRef.Name,
+				Namespace: GetUserRefNamespace(accessPolicy.Namespace, userRef),
+			},
+		})
+	}
+	return requests
+}
+
 func (r *NifiUserReconciler) ensureClusterLabel(ctx context.Context, cluster clientconfig.ClusterConnect, user *v1alpha1.NifiUser) (*v1alpha1.NifiUser, error) {
 	labels := ApplyClusterReferenceLabel(cluster, user.GetLabels())
 	if !reflect.DeepEqual(labels, user.GetLabels()) {
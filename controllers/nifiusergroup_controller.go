@@ -21,7 +21,9 @@ import (
 	"emperror.dev/errors"
 	"encoding/json"
 	"fmt"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/accesspolicies"
 	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/usergroup"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
 	"github.com/Orange-OpenSource/nifikop/pkg/k8sutil"
 	"github.com/Orange-OpenSource/nifikop/pkg/nificlient/config"
 	"github.com/Orange-OpenSource/nifikop/pkg/util"
@@ -29,9 +31,12 @@ import (
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -143,6 +148,19 @@ func (r *NifiUserGroupReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		users = append(users, user)
 	}
 
+	// Add the users selected by the users selector
+	selectedUsers, err := GetSelectedUsers(r.Client, current)
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "SelectUsersError",
+			fmt.Sprintf("Failed to select the users of the group : %s", err.Error()))
+		return RequeueWithError(r.Log, "failed to select users", err)
+	}
+	for _, selectedUser := range selectedUsers {
+		if !containsUser(users, selectedUser) {
+			users = append(users, selectedUser)
+		}
+	}
+
 	// Prepare cluster connection configurations
 	var clientConfig *clientconfig.NifiConfig
 	var clusterConnect clientconfig.ClusterConnect
@@ -240,7 +258,7 @@ func (r *NifiUserGroupReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			fmt.Sprintf("Creating registry client %s", instance.Name))
 
 		// Create NiFi user group
-		status, err := usergroup.CreateUserGroup(instance, users, clientConfig)
+		status, err := usergroup.CreateUserGroup(instance, users, selectedUsers, clientConfig)
 		if err != nil {
 			return RequeueWithError(r.Log, "failure creating user group", err)
 		}
@@ -254,10 +272,42 @@ func (r *NifiUserGroupReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			fmt.Sprintf("Created user group %s", instance.Name))
 	}
 
+	// Resolve the components referenced by the access policies, and add the ones granted by NifiAccessPolicies
+	accessPolicies, err := accesspolicies.ResolveAccessPolicies(r.Client, instance.Spec.AccessPolicies, instance.Namespace)
+	if err == nil {
+		var boundAccessPolicies []v1alpha1.AccessPolicy
+		boundAccessPolicies, err = GetBoundAccessPolicies(r.Client, clusterRef, func(accessPolicy *v1alpha1.NifiAccessPolicy) bool {
+			return IsUserGroupBound(accessPolicy, instance.Name, instance.Namespace)
+		})
+		accessPolicies = append(accessPolicies, boundAccessPolicies...)
+	}
+	if err != nil {
+		switch errors.Cause(err).(type) {
+		case errorfactory.ResourceNotReady:
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "ReferenceComponentNotReady",
+				fmt.Sprintf("The component referenced by an access policy is not deployed yet: %s", err.Error()))
+			return RequeueAfter(interval / 3)
+		default:
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "ReferenceComponentError",
+				fmt.Sprintf("Failed to lookup the component referenced by an access policy: %s", err.Error()))
+			return RequeueWithError(r.Log, "failed to lookup referenced component", err)
+		}
+	}
+	resolved := instance.DeepCopy()
+	resolved.Spec.AccessPolicies = accessPolicies
+
+	// The users no more selected are always removed from the group, the other members are only removed when the
+	// unmanaged tenants are pruned from the cluster
+	cluster, err := k8sutil.LookupNifiCluster(r.Client, clusterRef.Name, clusterRef.Namespace)
+	if err != nil {
+		return RequeueWithError(r.Log, "failed to lookup referenced cluster", err)
+	}
+	pruneUsers := cluster.Spec.TenantPruning.GetMode() == v1alpha1.TenantPruningEnforce
+
 	// Sync UserGroup resource with NiFi side component
 	r.Recorder.Event(instance, corev1.EventTypeNormal, "Synchronizing",
 		fmt.Sprintf("Synchronizing user group %s", instance.Name))
-	status, err := usergroup.SyncUserGroup(instance, users, clientConfig)
+	status, err := usergroup.SyncUserGroup(resolved, users, selectedUsers, pruneUsers, clientConfig)
 	if err != nil {
 		r.Recorder.Event(instance, corev1.EventTypeNormal, "SynchronizingFailed",
 			fmt.Sprintf("Synchronizing user group %s failed", instance.Name))
@@ -300,9 +350,98 @@ func (r *NifiUserGroupReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 func (r *NifiUserGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.NifiUserGroup{}).
+		Watches(&source.Kind{Type: &v1alpha1.NifiDataflow{}}, handler.EnqueueRequestsFromMapFunc(r.referencingUserGroups),
+			componentReferenceIdChanged).
+		Watches(&source.Kind{Type: &v1alpha1.NifiParameterContext{}}, handler.EnqueueRequestsFromMapFunc(r.referencingUserGroups),
+			componentReferenceIdChanged).
+		Watches(&source.Kind{Type: &v1alpha1.NifiRegistryClient{}}, handler.EnqueueRequestsFromMapFunc(r.referencingUserGroups),
+			componentReferenceIdChanged).
+		Watches(&source.Kind{Type: &v1alpha1.NifiAccessPolicy{}}, handler.EnqueueRequestsFromMapFunc(boundUserGroups)).
+		Watches(&source.Kind{Type: &v1alpha1.NifiUser{}}, handler.EnqueueRequestsFromMapFunc(r.selectingUserGroups)).
 		Complete(r)
 }
 
+// referencingUserGroups lists the user groups whose access policies reference the given NifiDataflow,
+// NifiParameterContext or NifiRegistryClient, to re-apply them when its NiFi id changes.
+func (r *NifiUserGroupReconciler) referencingUserGroups(obj client.Object) []reconcile.Request {
+	kind, ok := componentReferenceKind(obj)
+	if !ok {
+		return nil
+	}
+
+	userGroups := &v1alpha1.NifiUserGroupList{}
+	if err := r.Client.List(context.TODO(), userGroups); err != nil {
+		r.Log.Error(err, "failed to list the user groups")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, userGroup := range userGroups.Items {
+		if IsComponentReferenced(userGroup.Spec.AccessPolicies, userGroup.Namespace, kind, obj.GetName(), obj.GetNamespace()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: userGroup.Name, Namespace: userGroup.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// boundUserGroups returns the user groups granted by the given NifiAccessPolicy, to update their access policies when
+// it changes.
+func boundUserGroups(obj client.Object) []reconcile.Request {
+	accessPolicy, ok := obj.(*v1alpha1.NifiAccessPolicy)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, userGroupRef := range accessPolicy.Spec.UserGroupsRef {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      userGroupRef.Name,
+				Namespace: GetUserGroupRefNamespace(accessPolicy.Namespace, userGroupRef),
+			},
+		})
+	}
+	return requests
+}
+
+// selectingUserGroups lists the user groups whose users selector matches the given NifiUser. As both the previous and
+// the new version of an updated user are mapped, the groups are updated when it is relabelled in or out of them.
+func (r *NifiUserGroupReconciler) selectingUserGroups(obj client.Object) []reconcile.Request {
+	user, ok := obj.(*v1alpha1.NifiUser)
+	if !ok {
+		return nil
+	}
+
+	userGroups := &v1alpha1.NifiUserGroupList{}
+	if err := r.Client.List(context.TODO(), userGroups); err != nil {
+		r.Log.Error(err, "failed to list the user groups")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range userGroups.Items {
+		userGroup := &userGroups.Items[i]
+		if selected, err := IsUserSelected(userGroup, user); err != nil || !selected {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: userGroup.Name, Namespace: userGroup.Namespace},
+		})
+	}
+	return requests
+}
+
+func containsUser(users []*v1alpha1.NifiUser, user *v1alpha1.NifiUser) bool {
+	for _, u := range users {
+		if u.Name == user.Name && u.Namespace == user.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *NifiUserGroupReconciler) ensureClusterLabel(ctx context.Context, cluster clientconfig.ClusterConnect,
 	userGroup *v1alpha1.NifiUserGroup) (*v1alpha1.NifiUserGroup, error) {
 
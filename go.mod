@@ -15,7 +15,10 @@ require (
 	github.com/onsi/ginkgo v1.14.1
 	github.com/onsi/gomega v1.10.2
 	github.com/pavel-v-chernykh/keystore-go v2.1.0+incompatible
+	github.com/prometheus/client_golang v1.7.1
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/crypto v0.0.0-20201002170205-7f63de1d35b0
 	golang.org/x/tools v0.0.0-20201014231627-1610a49f37af // indirect
 	k8s.io/api v0.20.2
 	k8s.io/apimachinery v0.20.2
@@ -1,17 +1,85 @@
 package accesspolicies
 
 import (
+	"reflect"
+
+	"emperror.dev/errors"
 	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
 	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers"
 	"github.com/Orange-OpenSource/nifikop/pkg/common"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/k8sutil"
 	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
 	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
 	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
 	ctrl "sigs.k8s.io/controller-runtime"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var log = ctrl.Log.WithName("accesspolicies-method")
 
+// ResolveAccessPolicies returns the access policies with the id of the component they reference, as known by the
+// referenced resource at reconcile time, so that they are re-applied when this id changes.
+func ResolveAccessPolicies(
+	client runtimeClient.Client,
+	accessPolicies []v1alpha1.AccessPolicy,
+	namespace string) ([]v1alpha1.AccessPolicy, error) {
+
+	var resolved []v1alpha1.AccessPolicy
+	for _, accessPolicy := range accessPolicies {
+		if accessPolicy.Type == v1alpha1.ComponentAccessPolicyType && accessPolicy.ComponentRef != nil {
+			componentId, err := lookupComponentId(client, accessPolicy.ComponentRef, namespace)
+			if err != nil {
+				return nil, err
+			}
+			accessPolicy.ComponentId = componentId
+			if accessPolicy.ComponentType == "" {
+				accessPolicy.ComponentType = accessPolicy.ComponentRef.Kind.GetComponentType()
+			}
+		}
+		resolved = append(resolved, accessPolicy)
+	}
+	return resolved, nil
+}
+
+// lookupComponentId returns the NiFi id of the referenced resource.
+func lookupComponentId(client runtimeClient.Client, ref *v1alpha1.ComponentReference, namespace string) (string, error) {
+	refNamespace := ref.Namespace
+	if refNamespace == "" {
+		refNamespace = namespace
+	}
+
+	var componentId string
+	switch ref.Kind {
+	case v1alpha1.DataflowComponentReferenceKind:
+		flow, err := k8sutil.LookupNifiDataflow(client, ref.Name, refNamespace)
+		if err != nil {
+			return "", err
+		}
+		componentId = flow.Status.ProcessGroupID
+	case v1alpha1.ParameterContextComponentReferenceKind:
+		parameterContext, err := k8sutil.LookupNifiParameterContext(client, ref.Name, refNamespace)
+		if err != nil {
+			return "", err
+		}
+		componentId = parameterContext.Status.Id
+	case v1alpha1.RegistryClientComponentReferenceKind:
+		registryClient, err := k8sutil.LookupNifiRegistryClient(client, ref.Name, refNamespace)
+		if err != nil {
+			return "", err
+		}
+		componentId = registryClient.Status.Id
+	default:
+		return "", errors.Errorf("unsupported component reference kind: %s", ref.Kind)
+	}
+
+	if componentId == "" {
+		return "", errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("component id not found"),
+			"referenced component not deployed yet", "kind", ref.Kind, "name", ref.Name, "namespace", refNamespace)
+	}
+	return componentId, nil
+}
+
 func ExistAccessPolicies(accessPolicy *v1alpha1.AccessPolicy, config *clientconfig.NifiConfig) (bool, error) {
 
 	nClient, err := common.NewClusterConnection(log, config)
@@ -114,6 +182,148 @@ func UpdateAccessPolicyEntity(
 	return clientwrappers.ErrorUpdateOperation(log, err, "Update user")
 }
 
+// SyncAccessPolicyMembers creates the access policy of the NifiAccessPolicy if it doesn't exist, and sets its users
+// and user groups to the given ones, removing the others.
+func SyncAccessPolicyMembers(
+	accessPolicy *v1alpha1.NifiAccessPolicy,
+	users []*v1alpha1.NifiUser,
+	userGroups []*v1alpha1.NifiUserGroup,
+	config *clientconfig.NifiConfig) (*v1alpha1.NifiAccessPolicyStatus, error) {
+
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &accessPolicy.Spec.AccessPolicy
+	exist, err := ExistAccessPolicies(policy, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exist {
+		if _, err := CreateAccessPolicy(policy, config); err != nil {
+			return nil, err
+		}
+	}
+
+	entity, err := nClient.GetAccessPolicy(string(policy.Action), policy.GetResource(config.RootProcessGroupId))
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get access policy"); err != nil {
+		return nil, err
+	}
+
+	if !accessPolicyMembersAreSync(entity, users, userGroups) {
+		entity.Component.Users = []nigoapi.TenantEntity{}
+		entity.Component.UserGroups = []nigoapi.TenantEntity{}
+		addRemoveUsersFromAccessPolicyEntity(users, []*v1alpha1.NifiUser{}, entity)
+		addRemoveUserGroupsFromAccessPolicyEntity(userGroups, []*v1alpha1.NifiUserGroup{}, entity)
+
+		entity, err = nClient.UpdateAccessPolicy(*entity)
+		if err := clientwrappers.ErrorUpdateOperation(log, err, "Update access policy members"); err != nil {
+			return nil, err
+		}
+	}
+
+	status := accessPolicy.Status
+	status.Id = entity.Id
+	status.Version = *entity.Revision.Version
+	status.Action = policy.Action
+	status.Resource = entity.Component.Resource
+	status.Users = nil
+	for _, user := range users {
+		status.Users = append(status.Users,
+			v1alpha1.AccessPolicyMember{Name: user.Name, Namespace: user.Namespace, Id: user.Status.Id})
+	}
+	status.UserGroups = nil
+	for _, userGroup := range userGroups {
+		status.UserGroups = append(status.UserGroups,
+			v1alpha1.AccessPolicyMember{Name: userGroup.Name, Namespace: userGroup.Namespace, Id: userGroup.Status.Id})
+	}
+
+	return &status, nil
+}
+
+// RemoveAccessPolicyMembers removes the users and user groups granted by the NifiAccessPolicy from its access policy.
+func RemoveAccessPolicyMembers(accessPolicy *v1alpha1.NifiAccessPolicy, config *clientconfig.NifiConfig) error {
+	// The access policy never managed its members.
+	if accessPolicy.Status.Resource == "" {
+		return nil
+	}
+
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return err
+	}
+
+	entity, err := nClient.GetAccessPolicy(string(statusAction(accessPolicy)), accessPolicy.Status.Resource)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get access policy"); err != nil {
+		if err == nificlient.ErrNifiClusterReturned404 {
+			return nil
+		}
+		return err
+	}
+
+	var removeUsers []*v1alpha1.NifiUser
+	for _, member := range accessPolicy.Status.Users {
+		removeUsers = append(removeUsers, &v1alpha1.NifiUser{Status: v1alpha1.NifiUserStatus{Id: member.Id}})
+	}
+	var removeUserGroups []*v1alpha1.NifiUserGroup
+	for _, member := range accessPolicy.Status.UserGroups {
+		removeUserGroups = append(removeUserGroups, &v1alpha1.NifiUserGroup{Status: v1alpha1.NifiUserGroupStatus{Id: member.Id}})
+	}
+
+	addRemoveUsersFromAccessPolicyEntity([]*v1alpha1.NifiUser{}, removeUsers, entity)
+	addRemoveUserGroupsFromAccessPolicyEntity([]*v1alpha1.NifiUserGroup{}, removeUserGroups, entity)
+
+	_, err = nClient.UpdateAccessPolicy(*entity)
+	return clientwrappers.ErrorUpdateOperation(log, err, "Update access policy members")
+}
+
+// IsAccessPolicyMoved returns whether the members managed by the NifiAccessPolicy were granted another access policy,
+// because its action or the component it references changed.
+func IsAccessPolicyMoved(accessPolicy *v1alpha1.NifiAccessPolicy, config *clientconfig.NifiConfig) bool {
+	if accessPolicy.Status.Resource == "" {
+		return false
+	}
+	policy := &accessPolicy.Spec.AccessPolicy
+	return statusAction(accessPolicy) != policy.Action ||
+		accessPolicy.Status.Resource != policy.GetResource(config.RootProcessGroupId)
+}
+
+// statusAction returns the action of the access policy whose members are managed by the NifiAccessPolicy, falling back
+// to the spec one for the statuses written before the action was recorded.
+func statusAction(accessPolicy *v1alpha1.NifiAccessPolicy) v1alpha1.AccessPolicyAction {
+	if accessPolicy.Status.Action == "" {
+		return accessPolicy.Spec.AccessPolicy.Action
+	}
+	return accessPolicy.Status.Action
+}
+
+// accessPolicyMembersAreSync returns whether the users and user groups of the access policy are exactly the given ones.
+func accessPolicyMembersAreSync(
+	entity *nigoapi.AccessPolicyEntity,
+	users []*v1alpha1.NifiUser,
+	userGroups []*v1alpha1.NifiUserGroup) bool {
+
+	tenantIds := make(map[string]bool)
+	for _, tenant := range entity.Component.Users {
+		tenantIds[tenant.Id] = true
+	}
+	for _, tenant := range entity.Component.UserGroups {
+		tenantIds[tenant.Id] = true
+	}
+
+	expectedIds := make(map[string]bool)
+	for _, user := range users {
+		expectedIds[user.Status.Id] = true
+	}
+	for _, userGroup := range userGroups {
+		expectedIds[userGroup.Status.Id] = true
+	}
+
+	return reflect.DeepEqual(tenantIds, expectedIds)
+}
+
 func updateAccessPolicyEntity(
 	accessPolicy *v1alpha1.AccessPolicy,
 	addUsers []*v1alpha1.NifiUser,
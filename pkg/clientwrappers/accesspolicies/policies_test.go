@@ -0,0 +1,199 @@
+package accesspolicies
+
+import (
+	"reflect"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newMockClient() runtimeClient.Client {
+	v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	flow := &v1alpha1.NifiDataflow{ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "default"}}
+	flow.Status.ProcessGroupID = "flow-id"
+	otherFlow := &v1alpha1.NifiDataflow{ObjectMeta: metav1.ObjectMeta{Name: "flow", Namespace: "other"}}
+	otherFlow.Status.ProcessGroupID = "other-flow-id"
+	undeployedFlow := &v1alpha1.NifiDataflow{ObjectMeta: metav1.ObjectMeta{Name: "undeployed", Namespace: "default"}}
+	parameterContext := &v1alpha1.NifiParameterContext{ObjectMeta: metav1.ObjectMeta{Name: "context", Namespace: "default"}}
+	parameterContext.Status.Id = "context-id"
+	registryClient := &v1alpha1.NifiRegistryClient{ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "default"}}
+	registryClient.Status.Id = "registry-id"
+
+	return fake.NewFakeClientWithScheme(scheme.Scheme, flow, otherFlow, undeployedFlow, parameterContext, registryClient)
+}
+
+func TestLookupComponentId(t *testing.T) {
+	client := newMockClient()
+
+	testCases := []struct {
+		name        string
+		ref         v1alpha1.ComponentReference
+		expectedId  string
+		expectedErr func(error) bool
+	}{
+		{
+			name:       "dataflow",
+			ref:        v1alpha1.ComponentReference{Kind: v1alpha1.DataflowComponentReferenceKind, Name: "flow"},
+			expectedId: "flow-id",
+		},
+		{
+			name: "dataflow in another namespace",
+			ref: v1alpha1.ComponentReference{
+				Kind: v1alpha1.DataflowComponentReferenceKind, Name: "flow", Namespace: "other"},
+			expectedId: "other-flow-id",
+		},
+		{
+			name:       "parameter context",
+			ref:        v1alpha1.ComponentReference{Kind: v1alpha1.ParameterContextComponentReferenceKind, Name: "context"},
+			expectedId: "context-id",
+		},
+		{
+			name:       "registry client",
+			ref:        v1alpha1.ComponentReference{Kind: v1alpha1.RegistryClientComponentReferenceKind, Name: "registry"},
+			expectedId: "registry-id",
+		},
+		{
+			name: "component not deployed yet",
+			ref:  v1alpha1.ComponentReference{Kind: v1alpha1.DataflowComponentReferenceKind, Name: "undeployed"},
+			expectedErr: func(err error) bool {
+				_, ok := errors.Cause(err).(errorfactory.ResourceNotReady)
+				return ok
+			},
+		},
+		{
+			name:        "resource not found",
+			ref:         v1alpha1.ComponentReference{Kind: v1alpha1.ParameterContextComponentReferenceKind, Name: "missing"},
+			expectedErr: apierrors.IsNotFound,
+		},
+		{
+			name:        "unsupported kind",
+			ref:         v1alpha1.ComponentReference{Kind: "NifiCluster", Name: "flow"},
+			expectedErr: func(err error) bool { return err != nil },
+		},
+	}
+
+	for _, test := range testCases {
+		componentId, err := lookupComponentId(client, &test.ref, "default")
+		if test.expectedErr != nil {
+			if err == nil || !test.expectedErr(err) {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if componentId != test.expectedId {
+			t.Errorf("%s: expected component id %s, got: %s", test.name, test.expectedId, componentId)
+		}
+	}
+}
+
+func TestResolveAccessPolicies(t *testing.T) {
+	client := newMockClient()
+
+	accessPolicies := []v1alpha1.AccessPolicy{
+		{
+			Type:     v1alpha1.GlobalAccessPolicyType,
+			Action:   v1alpha1.ReadAccessPolicyAction,
+			Resource: v1alpha1.FlowAccessPolicyResource,
+		},
+		{
+			Type:        v1alpha1.ComponentAccessPolicyType,
+			Action:      v1alpha1.WriteAccessPolicyAction,
+			Resource:    v1alpha1.ComponentsAccessPolicyResource,
+			ComponentId: "stale-id",
+			ComponentRef: &v1alpha1.ComponentReference{
+				Kind: v1alpha1.DataflowComponentReferenceKind, Name: "flow"},
+		},
+		{
+			Type:     v1alpha1.ComponentAccessPolicyType,
+			Action:   v1alpha1.ReadAccessPolicyAction,
+			Resource: v1alpha1.DataAccessPolicyResource,
+			ComponentRef: &v1alpha1.ComponentReference{
+				Kind: v1alpha1.ParameterContextComponentReferenceKind, Name: "context"},
+		},
+		{
+			Type:          v1alpha1.ComponentAccessPolicyType,
+			Action:        v1alpha1.ReadAccessPolicyAction,
+			Resource:      v1alpha1.ComponentsAccessPolicyResource,
+			ComponentType: "process-groups",
+			ComponentId:   "explicit-id",
+		},
+	}
+
+	resolved, err := ResolveAccessPolicies(client, accessPolicies, "default")
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if len(resolved) != len(accessPolicies) {
+		t.Fatalf("Expected %d access policies, got: %d", len(accessPolicies), len(resolved))
+	}
+	if !reflect.DeepEqual(resolved[0], accessPolicies[0]) {
+		t.Errorf("Expected the global access policy to be unchanged, got: %+v", resolved[0])
+	}
+	if resolved[1].ComponentId != "flow-id" || resolved[1].ComponentType != v1alpha1.ProcessGroupType {
+		t.Errorf("Expected the dataflow process group to be resolved, got: %s/%s",
+			resolved[1].ComponentType, resolved[1].ComponentId)
+	}
+	if resolved[2].ComponentId != "context-id" || resolved[2].ComponentType != v1alpha1.ParameterContextType {
+		t.Errorf("Expected the parameter context to be resolved, got: %s/%s",
+			resolved[2].ComponentType, resolved[2].ComponentId)
+	}
+	if !reflect.DeepEqual(resolved[3], accessPolicies[3]) {
+		t.Errorf("Expected the access policy without reference to be unchanged, got: %+v", resolved[3])
+	}
+	if accessPolicies[1].ComponentId != "stale-id" {
+		t.Error("Expected the given access policies to be left untouched")
+	}
+
+	accessPolicies[2].ComponentRef.Name = "missing"
+	if _, err := ResolveAccessPolicies(client, accessPolicies, "default"); !apierrors.IsNotFound(err) {
+		t.Error("Expected a not found error, got:", err)
+	}
+}
+
+func TestIsAccessPolicyMoved(t *testing.T) {
+	config := &clientconfig.NifiConfig{RootProcessGroupId: "root"}
+
+	testCases := []struct {
+		name           string
+		action         v1alpha1.AccessPolicyAction
+		componentId    string
+		statusAction   v1alpha1.AccessPolicyAction
+		statusResource string
+		expected       bool
+	}{
+		{"never synced", v1alpha1.ReadAccessPolicyAction, "flow-id", "", "", false},
+		{"in sync", v1alpha1.ReadAccessPolicyAction, "flow-id", v1alpha1.ReadAccessPolicyAction, "/process-groups/flow-id", false},
+		{"status without action", v1alpha1.ReadAccessPolicyAction, "flow-id", "", "/process-groups/flow-id", false},
+		{"action changed", v1alpha1.WriteAccessPolicyAction, "flow-id", v1alpha1.ReadAccessPolicyAction, "/process-groups/flow-id", true},
+		{"component changed", v1alpha1.ReadAccessPolicyAction, "new-flow-id", v1alpha1.ReadAccessPolicyAction, "/process-groups/flow-id", true},
+	}
+
+	for _, test := range testCases {
+		accessPolicy := &v1alpha1.NifiAccessPolicy{}
+		accessPolicy.Spec.AccessPolicy = v1alpha1.AccessPolicy{
+			Type:          v1alpha1.ComponentAccessPolicyType,
+			Action:        test.action,
+			Resource:      v1alpha1.ComponentsAccessPolicyResource,
+			ComponentType: v1alpha1.ProcessGroupType,
+			ComponentId:   test.componentId,
+		}
+		accessPolicy.Status.Action = test.statusAction
+		accessPolicy.Status.Resource = test.statusResource
+
+		if moved := IsAccessPolicyMoved(accessPolicy, config); moved != test.expected {
+			t.Errorf("%s: expected %v, got: %v", test.name, test.expected, moved)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers"
+	"github.com/Orange-OpenSource/nifikop/pkg/common"
+	"github.com/Orange-OpenSource/nifikop/pkg/util"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var log = ctrl.Log.WithName("cluster-method")
+
+// heartbeatLayout defines the date format used by NiFi for the node heartbeats.
+const heartbeatLayout = "01/02/2006 15:04:05 MST"
+
+// DiscoverNodes lists the nodes of the cluster through the NiFi cluster API reachable on the config NifiURI,
+// and associates each of them to a node id, keeping the ids already assigned into the given nodes state.
+func DiscoverNodes(config *clientconfig.NifiConfig, nodesState map[string]v1alpha1.NodeState) (map[string]v1alpha1.DiscoveredNode, error) {
+	// Only rely on the NifiURI, the previously discovered nodes may no longer be part of the cluster.
+	discoveryConfig := *config
+	discoveryConfig.NodesURI = make(map[int32]clientconfig.NodeUri)
+
+	nClient, err := common.NewClusterConnection(log, &discoveryConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterEntity, err := nClient.DescribeCluster()
+	if err := clientwrappers.ErrorGetOperation(log, err, "Describe cluster"); err != nil {
+		return nil, err
+	}
+
+	return discoveredNodesState(clusterEntity.Cluster.Nodes, nodesState), nil
+}
+
+// GetNodesHealth returns the health of the nodes of the cluster, as reported by the NiFi cluster API,
+// indexed by their node id.
+func GetNodesHealth(config *clientconfig.NifiConfig) (map[string]v1alpha1.NodeHealthState, error) {
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterEntity, err := nClient.DescribeCluster()
+	if err := clientwrappers.ErrorGetOperation(log, err, "Describe cluster"); err != nil {
+		return nil, err
+	}
+
+	return nodesHealthState(clusterEntity.Cluster.Nodes, config.NodesURI, time.Now()), nil
+}
+
+func nodesHealthState(nodes []nigoapi.NodeDto, nodesURI map[int32]clientconfig.NodeUri, now time.Time) map[string]v1alpha1.NodeHealthState {
+	nodesHealth := make(map[string]v1alpha1.NodeHealthState)
+
+	// Index the node ids by the address used by NiFi to identify the node
+	nodeIds := make(map[string]int32)
+	for nId, uri := range nodesURI {
+		nodeIds[uri.HostListener] = nId
+	}
+
+	for _, node := range nodes {
+		nId, ok := nodeIds[nodeAddress(node)]
+		if !ok {
+			continue
+		}
+
+		health := v1alpha1.NodeHealthState{
+			ConnectionStatus:  node.Status,
+			LastHeartbeat:     node.Heartbeat,
+			Roles:             node.Roles,
+			ActiveThreadCount: node.ActiveThreadCount,
+		}
+		if heartbeat, err := time.Parse(heartbeatLayout, node.Heartbeat); err == nil {
+			health.HeartbeatAgeSeconds = int64(now.Sub(heartbeat).Seconds())
+		}
+		nodesHealth[fmt.Sprint(nId)] = health
+	}
+
+	return nodesHealth
+}
+
+func discoveredNodesState(nodes []nigoapi.NodeDto, nodesState map[string]v1alpha1.NodeState) map[string]v1alpha1.DiscoveredNode {
+	discoveredNodes := make(map[string]v1alpha1.DiscoveredNode)
+
+	// Index the already known node ids by their address
+	knownIds := make(map[string]string)
+	maxId := int32(-1)
+	for nId, state := range nodesState {
+		if id := util.ConvertStringToInt32(nId); id > maxId {
+			maxId = id
+		}
+		if state.DiscoveredNode != nil {
+			knownIds[state.DiscoveredNode.Address] = nId
+		}
+	}
+
+	// Sort the new nodes to assign ids in a deterministic way
+	sortedNodes := make([]nigoapi.NodeDto, len(nodes))
+	copy(sortedNodes, nodes)
+	sort.Slice(sortedNodes, func(i, j int) bool {
+		return nodeAddress(sortedNodes[i]) < nodeAddress(sortedNodes[j])
+	})
+
+	for _, node := range sortedNodes {
+		address := nodeAddress(node)
+		nId, ok := knownIds[address]
+		if !ok {
+			maxId++
+			nId = fmt.Sprint(maxId)
+		}
+		discoveredNodes[nId] = v1alpha1.DiscoveredNode{
+			ClusterNodeId: node.NodeId,
+			Address:       address,
+		}
+	}
+
+	return discoveredNodes
+}
+
+func nodeAddress(node nigoapi.NodeDto) string {
+	return fmt.Sprintf("%s:%d", node.Address, node.ApiPort)
+}
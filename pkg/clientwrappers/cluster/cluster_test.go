@@ -0,0 +1,35 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+)
+
+func TestNodesHealthState(t *testing.T) {
+	now, _ := time.Parse(heartbeatLayout, "01/01/2021 10:00:30 UTC")
+	nodesURI := map[int32]clientconfig.NodeUri{
+		0: {HostListener: "node-0:8443"},
+		1: {HostListener: "node-1:8443"},
+	}
+	nodes := []nigoapi.NodeDto{
+		{Address: "node-0", ApiPort: 8443, Status: "CONNECTED", Heartbeat: "01/01/2021 10:00:20 UTC",
+			Roles: []string{"Primary Node"}, ActiveThreadCount: 3},
+		{Address: "node-1", ApiPort: 8443, Status: "DISCONNECTED", Heartbeat: "unknown"},
+		{Address: "unknown-node", ApiPort: 8443, Status: "CONNECTED"},
+	}
+
+	health := nodesHealthState(nodes, nodesURI, now)
+	if len(health) != 2 {
+		t.Fatal("Expected the health of the 2 known nodes, got:", len(health))
+	}
+	if h := health["0"]; h.ConnectionStatus != "CONNECTED" || h.HeartbeatAgeSeconds != 10 || h.ActiveThreadCount != 3 ||
+		len(h.Roles) != 1 {
+		t.Error("Unexpected health for node 0:", h)
+	}
+	if h := health["1"]; h.ConnectionStatus != "DISCONNECTED" || h.HeartbeatAgeSeconds != 0 {
+		t.Error("Unexpected health for node 1:", h)
+	}
+}
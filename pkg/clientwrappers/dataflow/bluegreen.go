@@ -0,0 +1,304 @@
+package dataflow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+)
+
+// greenPositionOffset is the vertical offset of the process group the new flow version is imported into.
+const greenPositionOffset = 250
+
+// isBlueGreenUpdate returns whether the dataflow version has to be updated, or is being updated, with the blue/green
+// update strategy.
+func isBlueGreenUpdate(flow *v1alpha1.NifiDataflow, pGEntity *nigoapi.ProcessGroupEntity) bool {
+	if flow.Spec.UpdateStrategy != v1alpha1.BlueGreenStrategy {
+		return false
+	}
+
+	if blueGreen := flow.Status.BlueGreen; blueGreen != nil && blueGreen.Phase != v1alpha1.BlueGreenPhaseRolledBack {
+		return true
+	}
+	return !isVersionSync(flow, pGEntity)
+}
+
+// syncBlueGreen imports the new flow version into a sibling process group, validates and starts it, then monitors it
+// during the soak period before draining and removing the previous process group. The new process group is removed if
+// it reports invalid components or error bulletins.
+func syncBlueGreen(
+	nClient nificlient.NifiClient,
+	flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig,
+	registry *v1alpha1.NifiRegistryClient,
+	parameterContext *v1alpha1.NifiParameterContext,
+	pGEntity *nigoapi.ProcessGroupEntity) (*v1alpha1.NifiDataflowStatus, error) {
+
+	blueGreen := flow.Status.BlueGreen
+	if blueGreen == nil || blueGreen.Phase == v1alpha1.BlueGreenPhaseRolledBack {
+		return createGreenProcessGroup(nClient, flow, config, registry, pGEntity)
+	}
+
+	green := greenDataflow(flow)
+	switch blueGreen.Phase {
+	case v1alpha1.BlueGreenPhaseValidating:
+		reason, validating, err := validateGreenProcessGroup(nClient, green, parameterContext, config)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			return rollbackBlueGreen(flow, config, reason)
+		}
+		if !validating {
+			// The new process group is started with the component overrides.
+			if err := applyComponentOverrides(nClient, green, config); err != nil {
+				return nil, err
+			}
+			blueGreen.Phase = v1alpha1.BlueGreenPhaseStarting
+		}
+		return &flow.Status, errorfactory.NifiFlowSyncing{}
+
+	case v1alpha1.BlueGreenPhaseStarting:
+		if err := ScheduleDataflow(green, config); err != nil {
+			switch err.(type) {
+			case errorfactory.NifiFlowControllerServiceScheduling, errorfactory.NifiFlowScheduling:
+				return &flow.Status, errorfactory.NifiFlowSyncing{}
+			default:
+				return nil, err
+			}
+		}
+		blueGreen.Phase = v1alpha1.BlueGreenPhaseSoaking
+		blueGreen.SoakStartTime = time.Now().UTC().Format(time.RFC3339)
+		return &flow.Status, errorfactory.NifiFlowSyncing{}
+
+	case v1alpha1.BlueGreenPhaseSoaking:
+		reason, err := soakGreenProcessGroup(nClient, green, config)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			return rollbackBlueGreen(flow, config, reason)
+		}
+
+		soakStartTime, err := time.Parse(time.RFC3339, blueGreen.SoakStartTime)
+		if err != nil {
+			// The soak period is restarted when its start time can't be read.
+			blueGreen.SoakStartTime = time.Now().UTC().Format(time.RFC3339)
+		} else if time.Since(soakStartTime) >= flow.Spec.GetSoakPeriod() {
+			blueGreen.Phase = v1alpha1.BlueGreenPhasePromoting
+		}
+		return &flow.Status, errorfactory.NifiFlowSyncing{}
+
+	case v1alpha1.BlueGreenPhasePromoting:
+		return promoteGreenProcessGroup(flow, config)
+
+	case v1alpha1.BlueGreenPhaseRollingBack:
+		return rollbackBlueGreen(flow, config, blueGreen.FailureReason)
+	}
+
+	return &flow.Status, nil
+}
+
+// createGreenProcessGroup imports the new flow version into a sibling process group of the deployed one.
+func createGreenProcessGroup(
+	nClient nificlient.NifiClient,
+	flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig,
+	registry *v1alpha1.NifiRegistryClient,
+	pGEntity *nigoapi.ProcessGroupEntity) (*v1alpha1.NifiDataflowStatus, error) {
+
+	version := flow.GetDesiredFlowVersion()
+	scratchEntity := nigoapi.ProcessGroupEntity{}
+	updateProcessGroupEntity(flow, registry, config, &scratchEntity)
+	scratchEntity.Component.Name = fmt.Sprintf("%s (v%d)", flow.Name, version)
+	scratchEntity.Component.VersionControlInformation.Version = version
+	if pGEntity.Component != nil && pGEntity.Component.Position != nil {
+		scratchEntity.Component.Position = &nigoapi.PositionDto{
+			X: pGEntity.Component.Position.X,
+			Y: pGEntity.Component.Position.Y + greenPositionOffset,
+		}
+	}
+
+	entity, err := nClient.CreateProcessGroup(scratchEntity, flow.Spec.GetParentProcessGroupID(config.RootProcessGroupId))
+	if err := clientwrappers.ErrorCreateOperation(log, err, "Create green process-group"); err != nil {
+		return nil, err
+	}
+
+	flow.Status.BlueGreen = &v1alpha1.BlueGreenStatus{
+		ProcessGroupID: entity.Id,
+		FlowVersion:    version,
+		Generation:     flow.Generation,
+		Phase:          v1alpha1.BlueGreenPhaseValidating,
+	}
+	return &flow.Status, errorfactory.NifiFlowSyncing{}
+}
+
+// validateGreenProcessGroup binds the parameter context to the new process group, and returns the reason why it is
+// invalid, if any, and whether its components are still validating.
+func validateGreenProcessGroup(
+	nClient nificlient.NifiClient,
+	green *v1alpha1.NifiDataflow,
+	parameterContext *v1alpha1.NifiParameterContext,
+	config *clientconfig.NifiConfig) (string, bool, error) {
+
+	pGEntity, err := nClient.GetProcessGroup(green.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get green process group"); err != nil {
+		return "", false, err
+	}
+
+	processGroups, processors, _, _, err := listComponents(config, green.Status.ProcessGroupID)
+	if err != nil {
+		return "", false, err
+	}
+
+	processGroups = append(processGroups, *pGEntity)
+	if isParameterContextChanged(parameterContext, processGroups) {
+		for _, pg := range processGroups {
+			pg.Component.ParameterContext = &nigoapi.ParameterContextReferenceEntity{}
+			if parameterContext != nil {
+				pg.Component.ParameterContext.Id = parameterContext.Status.Id
+			}
+			_, err := nClient.UpdateProcessGroup(pg)
+			if err := clientwrappers.ErrorUpdateOperation(log, err, "Set green parameter-context"); err != nil {
+				return "", false, err
+			}
+		}
+		return "", true, nil
+	}
+
+	validating := false
+	for _, processor := range processors {
+		if processor.Component == nil {
+			continue
+		}
+		switch processor.Component.ValidationStatus {
+		case "VALIDATING":
+			validating = true
+		case "INVALID":
+			if !green.Spec.SkipInvalidComponent {
+				return fmt.Sprintf("processor %s is invalid", processor.Component.Name), false, nil
+			}
+		}
+	}
+
+	csEntities, err := nClient.GetFlowControllerServices(green.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get green flow controller services"); err != nil {
+		return "", false, err
+	}
+	for _, csEntity := range csEntities.ControllerServices {
+		switch csEntity.Status.ValidationStatus {
+		case "VALIDATING":
+			validating = true
+		case "INVALID":
+			if !green.Spec.SkipInvalidControllerService {
+				return fmt.Sprintf("controller service %s is invalid", csEntity.Id), false, nil
+			}
+		}
+	}
+
+	return "", validating, nil
+}
+
+// soakGreenProcessGroup returns the reason why the running new process group is unhealthy, if any.
+func soakGreenProcessGroup(
+	nClient nificlient.NifiClient,
+	green *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig) (string, error) {
+
+	processGroups, _, _, _, err := listComponents(config, green.Status.ProcessGroupID)
+	if err != nil {
+		return "", err
+	}
+
+	pGEntity, err := nClient.GetProcessGroup(green.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get green process group"); err != nil {
+		return "", err
+	}
+	processGroups = append(processGroups, *pGEntity)
+
+	groupIds := make(map[string]bool)
+	for _, pg := range processGroups {
+		groupIds[pg.Id] = true
+		if !green.Spec.SkipInvalidComponent && pg.InvalidCount > 0 {
+			return fmt.Sprintf("%d components are invalid", pg.InvalidCount), nil
+		}
+	}
+
+	bulletinBoard, err := nClient.GetBulletinBoard(0)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get bulletin board"); err != nil {
+		return "", err
+	}
+	if bulletinBoard == nil || bulletinBoard.BulletinBoard == nil {
+		return "", nil
+	}
+	for _, entity := range bulletinBoard.BulletinBoard.Bulletins {
+		if entity.Bulletin != nil && groupIds[entity.GroupId] && entity.Bulletin.Level == "ERROR" {
+			return fmt.Sprintf("%s reported the error bulletin: %s", entity.Bulletin.SourceName, entity.Bulletin.Message), nil
+		}
+	}
+
+	return "", nil
+}
+
+// promoteGreenProcessGroup drains and removes the previous process group, then replaces it with the new one.
+func promoteGreenProcessGroup(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) (*v1alpha1.NifiDataflowStatus, error) {
+	blue := flow.DeepCopy()
+	blue.Status.BlueGreen = nil
+	// The new process group is already running, the promotion can't be aborted.
+	if blue.Spec.GetDrainFallback() == v1alpha1.AbortDrainFallback {
+		blue.Spec.DrainFallback = v1alpha1.DropDrainFallback
+	}
+
+	status, err := RemoveDataflow(blue, config)
+	if status != nil {
+		flow.Status.LatestDropRequest = status.LatestDropRequest
+		flow.Status.Drain = status.Drain
+	}
+	if err != nil {
+		return &flow.Status, err
+	}
+
+	flow.Status.ProcessGroupID = flow.Status.BlueGreen.ProcessGroupID
+	flow.Status.BlueGreen = nil
+	flow.Status.Drain = nil
+	return &flow.Status, errorfactory.NifiFlowSyncing{}
+}
+
+// rollbackBlueGreen stops and removes the new process group, dropping its flowfiles, the previous one is kept running.
+func rollbackBlueGreen(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig, reason string) (*v1alpha1.NifiDataflowStatus, error) {
+	flow.Status.BlueGreen.Phase = v1alpha1.BlueGreenPhaseRollingBack
+	flow.Status.BlueGreen.FailureReason = reason
+
+	if err := removeGreenProcessGroup(flow, config); err != nil {
+		return &flow.Status, err
+	}
+
+	flow.Status.BlueGreen.Phase = v1alpha1.BlueGreenPhaseRolledBack
+	return &flow.Status, errorfactory.NifiFlowRolledBack{}
+}
+
+// removeGreenProcessGroup stops and removes the new process group, dropping its flowfiles.
+func removeGreenProcessGroup(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) error {
+	green := greenDataflow(flow)
+	green.Spec.UpdateStrategy = v1alpha1.DropStrategy
+
+	status, err := RemoveDataflow(green, config)
+	if status != nil {
+		flow.Status.LatestDropRequest = status.LatestDropRequest
+	}
+	return err
+}
+
+// greenDataflow returns a copy of the dataflow targeting the process group the new flow version is imported into.
+func greenDataflow(flow *v1alpha1.NifiDataflow) *v1alpha1.NifiDataflow {
+	green := flow.DeepCopy()
+	green.Status.ProcessGroupID = flow.Status.BlueGreen.ProcessGroupID
+	green.Status.BlueGreen = nil
+	green.Status.Drain = nil
+	return green
+}
@@ -0,0 +1,262 @@
+package dataflow
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/common"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+)
+
+func TestSyncBlueGreen(t *testing.T) {
+	recentSoak := time.Now().UTC().Add(-time.Minute).Format(time.RFC3339)
+	elapsedSoak := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+
+	testCases := []struct {
+		name                   string
+		phase                  v1alpha1.BlueGreenPhase
+		soakStartTime          string
+		greenValidationStatus  string
+		greenInvalidCount      int32
+		bulletins              []nigoapi.BulletinEntity
+		noBulletinBoard        bool
+		expectedPhase          v1alpha1.BlueGreenPhase
+		expectedFailureReason  string
+		expectedProcessGroupID string
+		expectedErr            error
+		expectedRemoved        []string
+	}{
+		{
+			name:                   "validating the green process group",
+			phase:                  v1alpha1.BlueGreenPhaseValidating,
+			greenValidationStatus:  "VALIDATING",
+			expectedPhase:          v1alpha1.BlueGreenPhaseValidating,
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowSyncing{},
+		},
+		{
+			name:                   "valid green process group",
+			phase:                  v1alpha1.BlueGreenPhaseValidating,
+			greenValidationStatus:  "VALID",
+			expectedPhase:          v1alpha1.BlueGreenPhaseStarting,
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowSyncing{},
+		},
+		{
+			name:                   "invalid green process group",
+			phase:                  v1alpha1.BlueGreenPhaseValidating,
+			greenValidationStatus:  "INVALID",
+			expectedPhase:          v1alpha1.BlueGreenPhaseRolledBack,
+			expectedFailureReason:  "processor processor is invalid",
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowRolledBack{},
+			expectedRemoved:        []string{"green"},
+		},
+		{
+			name:                   "green process group started",
+			phase:                  v1alpha1.BlueGreenPhaseStarting,
+			expectedPhase:          v1alpha1.BlueGreenPhaseSoaking,
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowSyncing{},
+		},
+		{
+			name:                   "soaking",
+			phase:                  v1alpha1.BlueGreenPhaseSoaking,
+			soakStartTime:          recentSoak,
+			expectedPhase:          v1alpha1.BlueGreenPhaseSoaking,
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowSyncing{},
+		},
+		{
+			name:                   "soak period elapsed",
+			phase:                  v1alpha1.BlueGreenPhaseSoaking,
+			soakStartTime:          elapsedSoak,
+			expectedPhase:          v1alpha1.BlueGreenPhasePromoting,
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowSyncing{},
+		},
+		{
+			name:                   "soak restarted on an invalid start time",
+			phase:                  v1alpha1.BlueGreenPhaseSoaking,
+			soakStartTime:          "10/03/2021 12:00",
+			expectedPhase:          v1alpha1.BlueGreenPhaseSoaking,
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowSyncing{},
+		},
+		{
+			name:                   "soak restarted without start time",
+			phase:                  v1alpha1.BlueGreenPhaseSoaking,
+			expectedPhase:          v1alpha1.BlueGreenPhaseSoaking,
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowSyncing{},
+		},
+		{
+			name:                   "invalid components while soaking",
+			phase:                  v1alpha1.BlueGreenPhaseSoaking,
+			soakStartTime:          elapsedSoak,
+			greenInvalidCount:      2,
+			expectedPhase:          v1alpha1.BlueGreenPhaseRolledBack,
+			expectedFailureReason:  "2 components are invalid",
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowRolledBack{},
+			expectedRemoved:        []string{"green"},
+		},
+		{
+			name:          "error bulletin while soaking",
+			phase:         v1alpha1.BlueGreenPhaseSoaking,
+			soakStartTime: recentSoak,
+			bulletins: []nigoapi.BulletinEntity{
+				{GroupId: "other", Bulletin: &nigoapi.BulletinDto{Level: "ERROR", SourceName: "other", Message: "failure"}},
+				{GroupId: "green", Bulletin: &nigoapi.BulletinDto{Level: "WARN", SourceName: "processor", Message: "warning"}},
+				{GroupId: "green", Bulletin: &nigoapi.BulletinDto{Level: "ERROR", SourceName: "processor", Message: "failure"}},
+			},
+			expectedPhase:          v1alpha1.BlueGreenPhaseRolledBack,
+			expectedFailureReason:  "processor reported the error bulletin: failure",
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowRolledBack{},
+			expectedRemoved:        []string{"green"},
+		},
+		{
+			name:                   "empty bulletin board while soaking",
+			phase:                  v1alpha1.BlueGreenPhaseSoaking,
+			soakStartTime:          recentSoak,
+			noBulletinBoard:        true,
+			expectedPhase:          v1alpha1.BlueGreenPhaseSoaking,
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowSyncing{},
+		},
+		{
+			name:                   "rollback resumed",
+			phase:                  v1alpha1.BlueGreenPhaseRollingBack,
+			expectedPhase:          v1alpha1.BlueGreenPhaseRolledBack,
+			expectedProcessGroupID: "blue",
+			expectedErr:            errorfactory.NifiFlowRolledBack{},
+			expectedRemoved:        []string{"green"},
+		},
+		{
+			name:                   "green process group promoted",
+			phase:                  v1alpha1.BlueGreenPhasePromoting,
+			expectedProcessGroupID: "green",
+			expectedErr:            errorfactory.NifiFlowSyncing{},
+			expectedRemoved:        []string{"blue"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			blue := nigoapi.ProcessGroupEntity{
+				Id:     "blue",
+				Status: &nigoapi.ProcessGroupStatusDto{AggregateSnapshot: &nigoapi.ProcessGroupStatusSnapshotDto{}},
+			}
+			green := nigoapi.ProcessGroupEntity{
+				Id:           "green",
+				InvalidCount: test.greenInvalidCount,
+				Component:    &nigoapi.ProcessGroupDto{Id: "green"},
+				Status:       &nigoapi.ProcessGroupStatusDto{AggregateSnapshot: &nigoapi.ProcessGroupStatusSnapshotDto{}},
+			}
+			greenProcessor := nigoapi.ProcessorEntity{
+				Id:        "processor",
+				Component: &nigoapi.ProcessorDto{Name: "processor", ValidationStatus: test.greenValidationStatus},
+			}
+			client := &fakeNifiClient{
+				processGroups: map[string]*nigoapi.ProcessGroupEntity{"blue": &blue, "green": &green},
+				flows: map[string]nigoapi.FlowDto{
+					"root":  {ProcessGroups: []nigoapi.ProcessGroupEntity{blue, green}},
+					"green": {Processors: []nigoapi.ProcessorEntity{greenProcessor}},
+				},
+				bulletins:       test.bulletins,
+				noBulletinBoard: test.noBulletinBoard,
+				updates:         make(map[string]string),
+			}
+			newNifiFromConfig := common.NewNifiFromConfig
+			common.NewNifiFromConfig = func(*clientconfig.NifiConfig) (nificlient.NifiClient, error) {
+				return client, nil
+			}
+			defer func() { common.NewNifiFromConfig = newNifiFromConfig }()
+
+			flow := &v1alpha1.NifiDataflow{}
+			flow.Spec.UpdateStrategy = v1alpha1.BlueGreenStrategy
+			flow.Status.ProcessGroupID = "blue"
+			flow.Status.BlueGreen = &v1alpha1.BlueGreenStatus{
+				ProcessGroupID: "green",
+				FlowVersion:    2,
+				Phase:          test.phase,
+				SoakStartTime:  test.soakStartTime,
+			}
+			config := &clientconfig.NifiConfig{RootProcessGroupId: "root"}
+
+			status, err := syncBlueGreen(client, flow, config, nil, nil, &blue)
+			if reflect.TypeOf(err) != reflect.TypeOf(test.expectedErr) {
+				t.Fatalf("Expected error %T, got: %v", test.expectedErr, err)
+			}
+			if status.ProcessGroupID != test.expectedProcessGroupID {
+				t.Errorf("Expected process group %s, got: %s", test.expectedProcessGroupID, status.ProcessGroupID)
+			}
+			if !reflect.DeepEqual(client.removed, test.expectedRemoved) {
+				t.Errorf("Expected removed process groups %v, got: %v", test.expectedRemoved, client.removed)
+			}
+
+			if test.expectedPhase == "" {
+				if status.BlueGreen != nil {
+					t.Errorf("Expected the blue/green update to be completed, got: %+v", status.BlueGreen)
+				}
+				return
+			}
+			if status.BlueGreen.Phase != test.expectedPhase {
+				t.Errorf("Expected phase %s, got: %s", test.expectedPhase, status.BlueGreen.Phase)
+			}
+			if status.BlueGreen.FailureReason != test.expectedFailureReason {
+				t.Errorf("Expected failure reason %q, got: %q", test.expectedFailureReason, status.BlueGreen.FailureReason)
+			}
+			if test.expectedPhase == v1alpha1.BlueGreenPhaseSoaking {
+				soakStartTime, err := time.Parse(time.RFC3339, status.BlueGreen.SoakStartTime)
+				if err != nil {
+					t.Fatal("Expected a valid soak start time, got:", status.BlueGreen.SoakStartTime)
+				}
+				if test.soakStartTime != recentSoak && time.Since(soakStartTime) > time.Minute {
+					t.Error("Expected the soak period to be restarted, got:", status.BlueGreen.SoakStartTime)
+				}
+			}
+		})
+	}
+}
+
+func TestIsBlueGreenUpdate(t *testing.T) {
+	version := int32(2)
+	pGEntity := &nigoapi.ProcessGroupEntity{Component: &nigoapi.ProcessGroupDto{
+		VersionControlInformation: &nigoapi.VersionControlInformationDto{Version: 2},
+	}}
+
+	testCases := []struct {
+		name     string
+		strategy v1alpha1.DataflowUpdateStrategy
+		phase    v1alpha1.BlueGreenPhase
+		version  int32
+		expected bool
+	}{
+		{"drain strategy", v1alpha1.DrainStrategy, "", 3, false},
+		{"version in sync", v1alpha1.BlueGreenStrategy, "", 2, false},
+		{"new version", v1alpha1.BlueGreenStrategy, "", 3, true},
+		{"update in progress", v1alpha1.BlueGreenStrategy, v1alpha1.BlueGreenPhaseSoaking, 2, true},
+		{"rolled back update of the same version", v1alpha1.BlueGreenStrategy, v1alpha1.BlueGreenPhaseRolledBack, 2, false},
+		{"rolled back update of a new version", v1alpha1.BlueGreenStrategy, v1alpha1.BlueGreenPhaseRolledBack, 3, true},
+	}
+
+	for _, test := range testCases {
+		flow := &v1alpha1.NifiDataflow{}
+		flow.Spec.UpdateStrategy = test.strategy
+		version = test.version
+		flow.Spec.FlowVersion = &version
+		if test.phase != "" {
+			flow.Status.BlueGreen = &v1alpha1.BlueGreenStatus{Phase: test.phase}
+		}
+		if blueGreen := isBlueGreenUpdate(flow, pGEntity); blueGreen != test.expected {
+			t.Errorf("%s: expected %v, got: %v", test.name, test.expected, blueGreen)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package dataflow
+
+import (
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+)
+
+// stateComponent is a processor or a port of the dataflow, which can be kept stopped or disabled.
+type stateComponent struct {
+	componentType v1alpha1.ComponentType
+	id            string
+	name          string
+	parentGroupId string
+	runStatus     string
+	revision      *nigoapi.RevisionDto
+}
+
+// applyComponentStates stops or disables the components matched by the component states of the dataflow, and returns
+// the states they are kept in, by component id.
+func applyComponentStates(
+	nClient nificlient.NifiClient,
+	flow *v1alpha1.NifiDataflow) (map[string]v1alpha1.ComponentRunState, error) {
+
+	if len(flow.Spec.ComponentStates) == 0 {
+		return nil, nil
+	}
+
+	processGroups, components, err := listStateComponents(nClient, flow.Status.ProcessGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	states := resolveComponentStates(flow, processGroups, components)
+	for _, component := range components {
+		state, ok := states[component.id]
+		if !ok {
+			continue
+		}
+
+		switch {
+		// A running component has to be stopped before being disabled.
+		case component.runStatus == "Running":
+			err = updateComponentRunStatus(nClient, component, "STOPPED")
+		case state == v1alpha1.DisabledComponentRunState && component.runStatus != "Disabled":
+			err = updateComponentRunStatus(nClient, component, "DISABLED")
+		case state == v1alpha1.StoppedComponentRunState && component.runStatus == "Disabled":
+			err = updateComponentRunStatus(nClient, component, "STOPPED")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return states, nil
+}
+
+// schedulableComponents returns the revisions of the stopped components of the dataflow which are not kept stopped.
+func schedulableComponents(
+	nClient nificlient.NifiClient,
+	flow *v1alpha1.NifiDataflow,
+	states map[string]v1alpha1.ComponentRunState) (map[string]nigoapi.RevisionDto, error) {
+
+	_, components, err := listStateComponents(nClient, flow.Status.ProcessGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make(map[string]nigoapi.RevisionDto)
+	for _, component := range components {
+		if _, kept := states[component.id]; !kept && component.runStatus == "Stopped" && component.revision != nil {
+			revisions[component.id] = *component.revision
+		}
+	}
+	return revisions, nil
+}
+
+// isStartedWithComponentStates returns whether all the components of the dataflow not kept stopped are started.
+func isStartedWithComponentStates(
+	nClient nificlient.NifiClient,
+	flow *v1alpha1.NifiDataflow,
+	states map[string]v1alpha1.ComponentRunState) (bool, error) {
+
+	_, components, err := listStateComponents(nClient, flow.Status.ProcessGroupID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, component := range components {
+		if _, kept := states[component.id]; !kept && component.runStatus == "Stopped" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// resolveComponentStates returns the states the dataflow components are kept in, by component id.
+func resolveComponentStates(
+	flow *v1alpha1.NifiDataflow,
+	processGroups []nigoapi.ProcessGroupEntity,
+	components []stateComponent) map[string]v1alpha1.ComponentRunState {
+
+	groups := make(map[string]nigoapi.ProcessGroupEntity)
+	for _, pg := range processGroups {
+		groups[pg.Id] = pg
+	}
+
+	states := make(map[string]v1alpha1.ComponentRunState)
+	for _, component := range components {
+		namePath := componentNamePath(flow.Status.ProcessGroupID, component.name, component.parentGroupId, groups)
+		for _, componentState := range flow.Spec.ComponentStates {
+			if componentState.Type != component.componentType {
+				continue
+			}
+			if (componentState.Id != "" && componentState.Id == component.id) ||
+				(componentState.Id == "" && componentState.NamePath == namePath) {
+				states[component.id] = componentState.State
+				break
+			}
+		}
+	}
+	return states
+}
+
+func updateComponentRunStatus(nClient nificlient.NifiClient, component stateComponent, state string) error {
+	var err error
+	switch component.componentType {
+	case v1alpha1.ProcessorComponentType:
+		_, err = nClient.UpdateProcessorRunStatus(component.id, nigoapi.ProcessorRunStatusEntity{
+			Revision: component.revision,
+			State:    state,
+		})
+	case v1alpha1.InputPortComponentType:
+		_, err = nClient.UpdateInputPortRunStatus(component.id, nigoapi.PortRunStatusEntity{
+			Revision: component.revision,
+			State:    state,
+		})
+	case v1alpha1.OutputPortComponentType:
+		_, err = nClient.UpdateOutputPortRunStatus(component.id, nigoapi.PortRunStatusEntity{
+			Revision: component.revision,
+			State:    state,
+		})
+	}
+	return clientwrappers.ErrorUpdateOperation(log, err, "Update component run status")
+}
+
+// listStateComponents will get all ProcessGroups, Processors and Ports recursively
+func listStateComponents(
+	nClient nificlient.NifiClient,
+	processGroupID string) ([]nigoapi.ProcessGroupEntity, []stateComponent, error) {
+
+	flowEntity, err := nClient.GetFlow(processGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get flow"); err != nil {
+		return nil, nil, err
+	}
+	flow := flowEntity.ProcessGroupFlow.Flow
+
+	processGroups := flow.ProcessGroups
+	var components []stateComponent
+	for _, processor := range flow.Processors {
+		if processor.Component == nil {
+			continue
+		}
+		component := stateComponent{
+			componentType: v1alpha1.ProcessorComponentType,
+			id:            processor.Id,
+			name:          processor.Component.Name,
+			parentGroupId: processor.Component.ParentGroupId,
+			revision:      processor.Revision,
+		}
+		if processor.Status != nil {
+			component.runStatus = processor.Status.RunStatus
+		}
+		components = append(components, component)
+	}
+	components = append(components, portStateComponents(v1alpha1.InputPortComponentType, flow.InputPorts)...)
+	components = append(components, portStateComponents(v1alpha1.OutputPortComponentType, flow.OutputPorts)...)
+
+	for _, pg := range flow.ProcessGroups {
+		childPG, childComponents, err := listStateComponents(nClient, pg.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		processGroups = append(processGroups, childPG...)
+		components = append(components, childComponents...)
+	}
+
+	return processGroups, components, nil
+}
+
+func portStateComponents(componentType v1alpha1.ComponentType, ports []nigoapi.PortEntity) []stateComponent {
+	var components []stateComponent
+	for _, port := range ports {
+		if port.Component == nil {
+			continue
+		}
+		component := stateComponent{
+			componentType: componentType,
+			id:            port.Id,
+			name:          port.Component.Name,
+			parentGroupId: port.Component.ParentGroupId,
+			revision:      port.Revision,
+		}
+		if port.Status != nil {
+			component.runStatus = port.Status.RunStatus
+		}
+		components = append(components, component)
+	}
+	return components
+}
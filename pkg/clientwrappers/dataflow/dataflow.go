@@ -1,10 +1,15 @@
 package dataflow
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
 
+	"emperror.dev/errors"
 	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
 	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers"
 	"github.com/Orange-OpenSource/nifikop/pkg/common"
@@ -16,6 +21,14 @@ import (
 
 var log = ctrl.Log.WithName("dataflow-method")
 
+const (
+	processorComponentType         = "PROCESSOR"
+	controllerServiceComponentType = "CONTROLLER_SERVICE"
+)
+
+// parameterReferenceRegex matches the parameter references of a property value, e.g #{name} or #{'my name'}.
+var parameterReferenceRegex = regexp.MustCompile(`#\{'?([^}']+)'?\}`)
+
 // DataflowExist check if the NifiDataflow exist on NiFi Cluster
 func DataflowExist(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) (bool, error) {
 
@@ -78,6 +91,57 @@ func CreateDataflow(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig
 	return &flow.Status, nil
 }
 
+// AdoptDataflow will take ownership of the existing process group matching the adoption of the NifiDataflow, once
+// its version control information has been validated.
+func AdoptDataflow(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig,
+	registry *v1alpha1.NifiRegistryClient) (*v1alpha1.NifiDataflowStatus, error) {
+
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var pGEntity *nigoapi.ProcessGroupEntity
+	if flow.Spec.AdoptProcessGroupId != "" {
+		pGEntity, err = nClient.GetProcessGroup(flow.Spec.AdoptProcessGroupId)
+		if err := clientwrappers.ErrorGetOperation(log, err, "Get process group to adopt"); err != nil {
+			return nil, err
+		}
+	} else {
+		parentGroupId := flow.Spec.GetParentProcessGroupID(config.RootProcessGroupId)
+		flowEntity, err := nClient.GetFlow(parentGroupId)
+		if err := clientwrappers.ErrorGetOperation(log, err, "Get flow"); err != nil {
+			return nil, err
+		}
+
+		for _, entity := range flowEntity.ProcessGroupFlow.Flow.ProcessGroups {
+			if entity.Component == nil || entity.Component.Name != flow.Spec.AdoptProcessGroupName {
+				continue
+			}
+			if pGEntity != nil {
+				return nil, errors.Errorf("several process groups named %s to adopt in process group %s",
+					flow.Spec.AdoptProcessGroupName, parentGroupId)
+			}
+			entity := entity
+			pGEntity = &entity
+		}
+		if pGEntity == nil {
+			return nil, errors.Errorf("no process group named %s to adopt in process group %s",
+				flow.Spec.AdoptProcessGroupName, parentGroupId)
+		}
+	}
+
+	vInfo := pGEntity.Component.VersionControlInformation
+	if vInfo == nil || vInfo.RegistryId != registry.Status.Id ||
+		vInfo.BucketId != flow.Spec.BucketId || vInfo.FlowId != flow.Spec.FlowId {
+		return nil, errors.Errorf("process group %s to adopt is not versioned with flow {registryId: %s, bucketId: %s, flowId: %s}",
+			pGEntity.Id, registry.Status.Id, flow.Spec.BucketId, flow.Spec.FlowId)
+	}
+
+	flow.Status.ProcessGroupID = pGEntity.Id
+	return &flow.Status, nil
+}
+
 // ScheduleDataflow will schedule the controller services and components of the NifiDataflow.
 func ScheduleDataflow(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) error {
 	nClient, err := common.NewClusterConnection(log, config)
@@ -106,13 +170,28 @@ func ScheduleDataflow(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConf
 		}
 	}
 
-	// Schedule flow
-	_, err = nClient.UpdateFlowProcessGroup(nigoapi.ScheduleComponentsEntity{
+	// Keep the components stopped or disabled
+	componentStates, err := applyComponentStates(nClient, flow)
+	if err != nil {
+		return err
+	}
+
+	// Schedule flow, only the components which are not kept stopped are scheduled
+	scheduleEntity := nigoapi.ScheduleComponentsEntity{
 		Id:    flow.Status.ProcessGroupID,
 		State: "RUNNING",
-	})
-	if err := clientwrappers.ErrorUpdateOperation(log, err, "Schedule flow"); err != nil {
-		return err
+	}
+	if len(componentStates) > 0 {
+		scheduleEntity.Components, err = schedulableComponents(nClient, flow, componentStates)
+		if err != nil {
+			return err
+		}
+	}
+	if scheduleEntity.Components == nil || len(scheduleEntity.Components) > 0 {
+		_, err = nClient.UpdateFlowProcessGroup(scheduleEntity)
+		if err := clientwrappers.ErrorUpdateOperation(log, err, "Schedule flow"); err != nil {
+			return err
+		}
 	}
 
 	// Check all components are ok
@@ -124,7 +203,19 @@ func ScheduleDataflow(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConf
 	processGroups = append(processGroups, *pGEntity)
 
 	for _, pgEntity := range processGroups {
-		if pgEntity.StoppedCount > 0 || (!flow.Spec.SkipInvalidComponent && pgEntity.InvalidCount > 0) {
+		if (len(componentStates) == 0 && pgEntity.StoppedCount > 0) ||
+			(!flow.Spec.SkipInvalidComponent && pgEntity.InvalidCount > 0) {
+			return errorfactory.NifiFlowScheduling{}
+		}
+	}
+
+	// The stopped count of the process groups includes the components kept stopped
+	if len(componentStates) > 0 {
+		started, err := isStartedWithComponentStates(nClient, flow, componentStates)
+		if err != nil {
+			return err
+		}
+		if !started {
 			return errorfactory.NifiFlowScheduling{}
 		}
 	}
@@ -149,15 +240,40 @@ func IsOutOfSyncDataflow(
 		return false, err
 	}
 
-	processGroups, _, _, _, err := listComponents(config, flow.Status.ProcessGroupID)
+	updateRequired, localChanges, err := outOfSyncDataflow(nClient, flow, config, registry, parameterContext, pGEntity)
 	if err != nil {
 		return false, err
 	}
+
+	return updateRequired || isLocalChangesOutOfSync(flow, localChanges), nil
+}
+
+// outOfSyncDataflow returns whether the deployed process group has to be stopped to be updated, and whether it has
+// local changes. The local changes to commit don't require to stop the process group.
+func outOfSyncDataflow(
+	nClient nificlient.NifiClient,
+	flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig,
+	registry *v1alpha1.NifiRegistryClient,
+	parameterContext *v1alpha1.NifiParameterContext,
+	pGEntity *nigoapi.ProcessGroupEntity) (bool, bool, error) {
+
+	processGroups, _, _, _, err := listComponents(config, flow.Status.ProcessGroupID)
+	if err != nil {
+		return false, false, err
+	}
 	processGroups = append(processGroups, *pGEntity)
 
-	return isParameterContextChanged(parameterContext, processGroups) ||
-		isVersioningChanged(flow, registry, pGEntity) || !isVersionSync(flow, pGEntity) || localChanged(pGEntity) ||
-		isParentProcessGroupChanged(flow, config, pGEntity) || isNameChanged(flow, pGEntity) || isPostionChanged(flow, pGEntity), nil
+	localChanges, err := hasLocalChanges(nClient, flow, config, pGEntity)
+	if err != nil {
+		return false, false, err
+	}
+
+	updateRequired := isParameterContextChanged(parameterContext, processGroups) ||
+		isVersioningChanged(flow, registry, pGEntity) || !isVersionSync(flow, pGEntity) ||
+		(localChanges && flow.Spec.GetLocalChangesPolicy() == v1alpha1.RevertLocalChangesPolicy) ||
+		isParentProcessGroupChanged(flow, config, pGEntity) || isNameChanged(flow, pGEntity) || isPostionChanged(flow, pGEntity)
+	return updateRequired, localChanges, nil
 }
 
 func isParameterContextChanged(
@@ -193,13 +309,18 @@ func isNameChanged(flow *v1alpha1.NifiDataflow, pgFlowEntity *nigoapi.ProcessGro
 
 // isVersionSync check if the flow version is out of sync.
 func isVersionSync(flow *v1alpha1.NifiDataflow, pgFlowEntity *nigoapi.ProcessGroupEntity) bool {
-	return *flow.Spec.FlowVersion == pgFlowEntity.Component.VersionControlInformation.Version
+	return flow.GetDesiredFlowVersion() == pgFlowEntity.Component.VersionControlInformation.Version
 }
 
 func localChanged(pgFlowEntity *nigoapi.ProcessGroupEntity) bool {
 	return strings.Contains(pgFlowEntity.Component.VersionControlInformation.State, "LOCALLY_MODIFIED")
 }
 
+// isLocalChangesOutOfSync check if the local changes have to be reverted or committed, the report policy keeps them.
+func isLocalChangesOutOfSync(flow *v1alpha1.NifiDataflow, localChanges bool) bool {
+	return localChanges && flow.Spec.GetLocalChangesPolicy() != v1alpha1.ReportLocalChangesPolicy
+}
+
 // isVersioningChanged check if the versioning configuration is out of sync on process group.
 func isVersioningChanged(
 	flow *v1alpha1.NifiDataflow,
@@ -236,21 +357,22 @@ func SyncDataflow(
 		return nil, err
 	}
 
-	processGroups, _, _, _, err := listComponents(config, flow.Status.ProcessGroupID)
+	processGroups, processors, _, _, err := listComponents(config, flow.Status.ProcessGroupID)
 	if err != nil {
 		return nil, err
 	}
 
 	processGroups = append(processGroups, *pGEntity)
 	if isParameterContextChanged(parameterContext, processGroups) {
-		// unschedule processors
-		_, err := nClient.UpdateFlowProcessGroup(nigoapi.ScheduleComponentsEntity{
-			Id:    flow.Status.ProcessGroupID,
-			State: "STOPPED",
-		})
-		if err := clientwrappers.ErrorUpdateOperation(log, err, "Stop flow"); err != nil {
+		// unschedule the components referencing the changed parameters
+		impactedComponents, active, err := stopImpactedComponents(nClient, flow, parameterContext, processGroups, processors)
+		if err != nil {
 			return nil, err
 		}
+		flow.Status.ImpactedComponents = impactedComponents
+		if active {
+			return &flow.Status, errorfactory.NifiFlowSyncing{}
+		}
 
 		for _, pg := range processGroups {
 			if parameterContext == nil {
@@ -268,6 +390,15 @@ func SyncDataflow(
 		return &flow.Status, errorfactory.NifiFlowSyncing{}
 	}
 
+	// restore the components stopped during the latest parameter context change
+	pending, err := restoreImpactedComponents(nClient, flow, processors)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return &flow.Status, errorfactory.NifiFlowSyncing{}
+	}
+
 	if isVersioningChanged(flow, registry, pGEntity) {
 		return RemoveDataflow(flow, config)
 	}
@@ -350,11 +481,30 @@ func SyncDataflow(
 		}
 	}
 
-	isOutOfSink, err := IsOutOfSyncDataflow(flow, config, registry, parameterContext)
+	// The new flow version is deployed side by side with the blueGreen strategy
+	if isBlueGreenUpdate(flow, pGEntity) {
+		localChanges, err := hasLocalChanges(nClient, flow, config, pGEntity)
+		if err != nil {
+			return nil, err
+		}
+		if localChanges && flow.Spec.GetLocalChangesPolicy() == v1alpha1.CommitLocalChangesPolicy {
+			return commitLocalChanges(flow, config, pGEntity)
+		}
+		return syncBlueGreen(nClient, flow, config, registry, parameterContext, pGEntity)
+	}
+
+	updateRequired, localChanges, err := outOfSyncDataflow(nClient, flow, config, registry, parameterContext, pGEntity)
 	if err != nil {
 		return &flow.Status, err
 	}
-	if isOutOfSink {
+
+	// The local changes are committed before the process group is stopped, committing them doesn't require it.
+	// The component overrides are not considered as local changes to revert or commit.
+	if localChanges && flow.Spec.GetLocalChangesPolicy() == v1alpha1.CommitLocalChangesPolicy {
+		return commitLocalChanges(flow, config, pGEntity)
+	}
+
+	if updateRequired {
 		status, err := prepareUpdatePG(flow, config)
 		if err != nil {
 			return status, err
@@ -367,7 +517,8 @@ func SyncDataflow(
 		return nil, err
 	}
 
-	if localChanged(pGEntity) {
+	// The local changes are only reverted with the report policy when the version has to be updated.
+	if isLocalChangesOutOfSync(flow, localChanges) || (localChanged(pGEntity) && !isVersionSync(flow, pGEntity)) {
 		vInfo := pGEntity.Component.VersionControlInformation
 		updateRequest, err := nClient.CreateVersionRevertRequest(
 			flow.Status.ProcessGroupID,
@@ -401,7 +552,7 @@ func SyncDataflow(
 					RegistryId: registry.Status.Id,
 					BucketId:   flow.Spec.BucketId,
 					FlowId:     flow.Spec.FlowId,
-					Version:    *flow.Spec.FlowVersion,
+					Version:    flow.GetDesiredFlowVersion(),
 				},
 			},
 		)
@@ -414,9 +565,92 @@ func SyncDataflow(
 		return &flow.Status, errorfactory.NifiFlowUpdateRequestRunning{}
 	}
 
+	version := pGEntity.Component.VersionControlInformation.Version
+	flow.Status.FlowVersion = &version
 	return &flow.Status, nil
 }
 
+// commitLocalChanges saves the local changes of the dataflow as a new flow version into the registry.
+func commitLocalChanges(
+	flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig,
+	pGEntity *nigoapi.ProcessGroupEntity) (*v1alpha1.NifiDataflowStatus, error) {
+
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// If a new version has been requested, the committed one must not be kept as the deployed version.
+	var committedFromVersion *int32
+	if isVersionSync(flow, pGEntity) {
+		specVersion := *flow.Spec.FlowVersion
+		committedFromVersion = &specVersion
+	}
+
+	vInfo := pGEntity.Component.VersionControlInformation
+	entity, err := nClient.CreateFlowVersion(
+		flow.Status.ProcessGroupID,
+		nigoapi.StartVersionControlRequestEntity{
+			ProcessGroupRevision: pGEntity.Revision,
+			VersionedFlow: &nigoapi.VersionedFlowDto{
+				RegistryId: vInfo.RegistryId,
+				BucketId:   vInfo.BucketId,
+				FlowId:     vInfo.FlowId,
+				Comments: fmt.Sprintf("Local changes of the NifiDataflow %s/%s committed by NiFiKop on top of version %d",
+					flow.Namespace, flow.Name, vInfo.Version),
+				Action: "COMMIT",
+			},
+		},
+	)
+	if err := clientwrappers.ErrorUpdateOperation(log, err, "Commit local changes"); err != nil {
+		return nil, err
+	}
+
+	version := entity.VersionControlInformation.Version
+	flow.Status.FlowVersion = &version
+	flow.Status.CommittedFromVersion = committedFromVersion
+	return &flow.Status, errorfactory.NifiFlowLocalChangesCommitted{}
+}
+
+// GetLocalChanges returns the components of the dataflow locally modified since the deployed flow version.
+func GetLocalChanges(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) ([]v1alpha1.LocalChange, error) {
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, err
+	}
+
+	pGEntity, err := nClient.GetProcessGroup(flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get process group"); err != nil {
+		return nil, err
+	}
+
+	if pGEntity.Component.VersionControlInformation == nil || !localChanged(pGEntity) {
+		return nil, nil
+	}
+
+	comparison, err := nClient.GetLocalModifications(flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get local modifications"); err != nil {
+		return nil, err
+	}
+
+	components, err := filterOverriddenDifferences(flow, config, comparison.ComponentDifferences)
+	if err != nil {
+		return nil, err
+	}
+
+	var localChanges []v1alpha1.LocalChange
+	for _, component := range components {
+		localChanges = append(localChanges, localChange2Status(component))
+	}
+
+	sort.Slice(localChanges, func(i, j int) bool {
+		return localChanges[i].ComponentId < localChanges[j].ComponentId
+	})
+
+	return localChanges, nil
+}
+
 // prepareUpdatePG ensure drain or drop logic
 func prepareUpdatePG(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) (*v1alpha1.NifiDataflowStatus, error) {
 
@@ -426,110 +660,185 @@ func prepareUpdatePG(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfi
 	}
 
 	if flow.Spec.UpdateStrategy == v1alpha1.DropStrategy {
-		// unschedule processors
-		_, err := nClient.UpdateFlowProcessGroup(nigoapi.ScheduleComponentsEntity{
-			Id:    flow.Status.ProcessGroupID,
-			State: "STOPPED",
-		})
-		if err := clientwrappers.ErrorUpdateOperation(log, err, "Stop flow"); err != nil {
-			return nil, err
-		}
+		return dropFlowFiles(nClient, flow, config)
+	}
 
-		//
-		if flow.Status.LatestDropRequest != nil && !flow.Status.LatestDropRequest.Finished {
+	// Check all components are ok
+	flowEntity, err := nClient.GetFlow(flow.Spec.GetParentProcessGroupID(config.RootProcessGroupId))
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get flow"); err != nil {
+		return nil, err
+	}
 
-			dropRequest, err :=
-				nClient.GetDropRequest(flow.Status.LatestDropRequest.ConnectionId, flow.Status.LatestDropRequest.Id)
-			if err := clientwrappers.ErrorGetOperation(log, err, "Get drop-request"); err != nificlient.ErrNifiClusterReturned404 {
-				if err != nil {
-					return nil, err
-				}
+	pgEntity := processGroupFromFlow(flowEntity, flow)
+	if pgEntity == nil {
+		return nil, errorfactory.NifiFlowDraining{}
+	}
 
-				flow.Status.LatestDropRequest =
-					dropRequest2Status(flow.Status.LatestDropRequest.ConnectionId, dropRequest)
-				if !dropRequest.DropRequest.Finished {
-					return &flow.Status, errorfactory.NifiConnectionDropping{}
-				}
-			}
-		}
+	// If flow is fully drained
+	if pgEntity.Status.AggregateSnapshot.FlowFilesQueued == 0 {
+		flow.Status.Drain = nil
+		return &flow.Status, nil
+	}
 
-		// Drop all events in connections
-		_, _, connections, _, err := listComponents(config, flow.Status.ProcessGroupID)
-		if err := clientwrappers.ErrorGetOperation(log, err, "Get recursively flow components"); err != nil {
-			return nil, err
+	// Track the drain progress
+	updateDrainStatus(flow, pgEntity.Status.AggregateSnapshot.FlowFilesQueued, time.Now())
+
+	if flow.Status.Drain.TimedOut {
+		switch drainFallback(flow) {
+		case v1alpha1.DropDrainFallback:
+			return dropFlowFiles(nClient, flow, config)
+		case v1alpha1.ForceDrainFallback:
+			flow.Status.Drain = nil
+			return &flow.Status, nil
+		default:
+			return &flow.Status, errorfactory.NifiFlowDrainAborted{}
 		}
-		for _, connection := range connections {
-			if connection.Status.AggregateSnapshot.FlowFilesQueued != 0 {
-				dropRequest, err := nClient.CreateDropRequest(connection.Id)
-				if err := clientwrappers.ErrorCreateOperation(log, err, "Create drop-request"); err != nil {
-					return nil, err
-				}
+	}
+
+	_, processors, connections, inputPorts, err := listComponents(config, flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get recursively flow components"); err != nil {
+		return nil, err
+	}
 
-				flow.Status.LatestDropRequest =
-					dropRequest2Status(flow.Status.LatestDropRequest.ConnectionId, dropRequest)
+	// list input port
+	for _, connection := range connections {
+		processors = removeProcessor(processors, connection.DestinationId)
+	}
 
-				return &flow.Status, errorfactory.NifiConnectionDropping{}
+	// Stop all input processor
+	for _, processor := range processors {
+		if processor.Status.RunStatus == "Running" {
+			_, err := nClient.UpdateProcessorRunStatus(processor.Id, nigoapi.ProcessorRunStatusEntity{
+				Revision: processor.Revision,
+				State:    "STOPPED",
+			})
+			if err := clientwrappers.ErrorUpdateOperation(log, err, "Stop processor"); err != nil {
+				return nil, err
 			}
 		}
-	} else {
+	}
 
-		// Check all components are ok
-		flowEntity, err := nClient.GetFlow(flow.Spec.GetParentProcessGroupID(config.RootProcessGroupId))
-		if err := clientwrappers.ErrorGetOperation(log, err, "Get flow"); err != nil {
-			return nil, err
+	// Stop all input remote
+	for _, inputPort := range inputPorts {
+		if inputPort.AllowRemoteAccess && inputPort.Status.RunStatus == "Running" {
+			_, err := nClient.UpdateInputPortRunStatus(inputPort.Id, nigoapi.PortRunStatusEntity{
+				Revision: inputPort.Revision,
+				State:    "STOPPED",
+			})
+			if err := clientwrappers.ErrorUpdateOperation(log, err, "Stop remote input-port"); err != nil {
+				return nil, err
+			}
 		}
+	}
+	return &flow.Status, errorfactory.NifiFlowDraining{}
+}
 
-		pgEntity := processGroupFromFlow(flowEntity, flow)
-		if pgEntity == nil {
-			return nil, errorfactory.NifiFlowDraining{}
-		}
+// dropFlowFiles stops the dataflow and drops the flowfiles remaining in its connections.
+func dropFlowFiles(nClient nificlient.NifiClient, flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig) (*v1alpha1.NifiDataflowStatus, error) {
+
+	// unschedule processors
+	_, err := nClient.UpdateFlowProcessGroup(nigoapi.ScheduleComponentsEntity{
+		Id:    flow.Status.ProcessGroupID,
+		State: "STOPPED",
+	})
+	if err := clientwrappers.ErrorUpdateOperation(log, err, "Stop flow"); err != nil {
+		return nil, err
+	}
+
+	//
+	if flow.Status.LatestDropRequest != nil && !flow.Status.LatestDropRequest.Finished {
 
-		// If flow is not fully drained
-		if pgEntity.Status.AggregateSnapshot.FlowFilesQueued != 0 {
-			_, processors, connections, inputPorts, err := listComponents(config, flow.Status.ProcessGroupID)
-			if err := clientwrappers.ErrorGetOperation(log, err, "Get recursively flow components"); err != nil {
+		dropRequest, err :=
+			nClient.GetDropRequest(flow.Status.LatestDropRequest.ConnectionId, flow.Status.LatestDropRequest.Id)
+		if err := clientwrappers.ErrorGetOperation(log, err, "Get drop-request"); err != nificlient.ErrNifiClusterReturned404 {
+			if err != nil {
 				return nil, err
 			}
 
-			// list input port
-			for _, connection := range connections {
-				processors = removeProcessor(processors, connection.DestinationId)
+			flow.Status.LatestDropRequest =
+				dropRequest2Status(flow.Status.LatestDropRequest.ConnectionId, dropRequest)
+			if !dropRequest.DropRequest.Finished {
+				return &flow.Status, errorfactory.NifiConnectionDropping{}
 			}
+		}
+	}
 
-			// Stop all input processor
-			for _, processor := range processors {
-				if processor.Status.RunStatus == "Running" {
-					_, err := nClient.UpdateProcessorRunStatus(processor.Id, nigoapi.ProcessorRunStatusEntity{
-						Revision: processor.Revision,
-						State:    "STOPPED",
-					})
-					if err := clientwrappers.ErrorUpdateOperation(log, err, "Stop processor"); err != nil {
-						return nil, err
-					}
-				}
+	// Drop all events in connections
+	_, _, connections, _, err := listComponents(config, flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get recursively flow components"); err != nil {
+		return nil, err
+	}
+	for _, connection := range connections {
+		if connection.Status.AggregateSnapshot.FlowFilesQueued != 0 {
+			dropRequest, err := nClient.CreateDropRequest(connection.Id)
+			if err := clientwrappers.ErrorCreateOperation(log, err, "Create drop-request"); err != nil {
+				return nil, err
 			}
 
-			// Stop all input remote
-			for _, inputPort := range inputPorts {
-				if inputPort.AllowRemoteAccess && inputPort.Status.RunStatus == "Running" {
-					_, err := nClient.UpdateInputPortRunStatus(inputPort.Id, nigoapi.PortRunStatusEntity{
-						Revision: inputPort.Revision,
-						State:    "STOPPED",
-					})
-					if err := clientwrappers.ErrorUpdateOperation(log, err, "Stop remote input-port"); err != nil {
-						return nil, err
-					}
-				}
-			}
-			return nil, errorfactory.NifiFlowDraining{}
+			flow.Status.LatestDropRequest = dropRequest2Status(connection.Id, dropRequest)
+
+			return &flow.Status, errorfactory.NifiConnectionDropping{}
 		}
 	}
 
+	flow.Status.Drain = nil
 	return &flow.Status, nil
 }
 
+// updateDrainStatus records the flowfiles remaining in the dataflow into its drain status, restarted when the dataflow
+// spec changes or when its start time can't be read, and whether the drain has timed out.
+func updateDrainStatus(flow *v1alpha1.NifiDataflow, flowFilesQueued int32, now time.Time) {
+	if flow.Status.Drain != nil {
+		if _, err := time.Parse(time.RFC3339, flow.Status.Drain.StartTime); err != nil {
+			flow.Status.Drain = nil
+		}
+	}
+	if flow.Status.Drain == nil || flow.Status.Drain.Generation != flow.Generation {
+		flow.Status.Drain = &v1alpha1.DrainStatus{
+			StartTime:  now.UTC().Format(time.RFC3339),
+			Generation: flow.Generation,
+		}
+	}
+	flow.Status.Drain.RemainingFlowFiles = flowFilesQueued
+	if !flow.Status.Drain.TimedOut && isDrainTimedOut(flow, now) {
+		flow.Status.Drain.TimedOut = true
+	}
+}
+
+// drainFallback returns the fallback applied once the drain has timed out, the removal of the dataflow can't be
+// aborted so its remaining flowfiles are dropped instead.
+func drainFallback(flow *v1alpha1.NifiDataflow) v1alpha1.DrainFallback {
+	fallback := flow.Spec.GetDrainFallback()
+	if fallback == v1alpha1.AbortDrainFallback && flow.GetDeletionTimestamp() != nil {
+		return v1alpha1.DropDrainFallback
+	}
+	return fallback
+}
+
+// isDrainTimedOut returns whether the drain of the dataflow lasts longer than its drain timeout.
+func isDrainTimedOut(flow *v1alpha1.NifiDataflow, now time.Time) bool {
+	if flow.Spec.DrainTimeout == nil || flow.Status.Drain == nil {
+		return false
+	}
+
+	startTime, err := time.Parse(time.RFC3339, flow.Status.Drain.StartTime)
+	if err != nil {
+		return false
+	}
+	return now.Sub(startTime) > flow.Spec.DrainTimeout.Duration
+}
+
 func RemoveDataflow(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) (*v1alpha1.NifiDataflowStatus, error) {
 
+	// Remove the process group of the blue/green update in progress
+	if blueGreen := flow.Status.BlueGreen; blueGreen != nil && blueGreen.Phase != v1alpha1.BlueGreenPhaseRolledBack {
+		if err := removeGreenProcessGroup(flow, config); err != nil {
+			return &flow.Status, err
+		}
+		flow.Status.BlueGreen = nil
+	}
+
 	// Prepare Dataflow
 	status, err := prepareUpdatePG(flow, config)
 	if err != nil {
@@ -616,6 +925,437 @@ func UnscheduleDataflow(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiCo
 	return nil
 }
 
+// GetBulletins returns the bulletins emitted by the dataflow components since the last handled one, whose level
+// matches the dataflow bulletin level, and the id of the latest bulletin handled.
+func GetBulletins(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) ([]v1alpha1.Bulletin, int64, error) {
+	lastBulletinId := flow.Status.LastBulletinId
+	if flow.Spec.GetBulletinLevel() == v1alpha1.NoneBulletinLevel {
+		return nil, lastBulletinId, nil
+	}
+
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, lastBulletinId, err
+	}
+
+	processGroups, _, _, _, err := listComponents(config, flow.Status.ProcessGroupID)
+	if err != nil {
+		return nil, lastBulletinId, err
+	}
+
+	groupIds := map[string]bool{flow.Status.ProcessGroupID: true}
+	for _, pg := range processGroups {
+		groupIds[pg.Id] = true
+	}
+
+	bulletinBoard, err := nClient.GetBulletinBoard(lastBulletinId)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get bulletin board"); err != nil {
+		return nil, lastBulletinId, err
+	}
+	if bulletinBoard == nil || bulletinBoard.BulletinBoard == nil {
+		return nil, lastBulletinId, nil
+	}
+
+	var bulletins []v1alpha1.Bulletin
+	for _, entity := range bulletinBoard.BulletinBoard.Bulletins {
+		if entity.Id > lastBulletinId {
+			lastBulletinId = entity.Id
+		}
+		if entity.Bulletin == nil || !groupIds[entity.GroupId] ||
+			!isBulletinLevelReported(entity.Bulletin.Level, flow.Spec.GetBulletinLevel()) {
+			continue
+		}
+		bulletins = append(bulletins, bulletin2Status(entity.Bulletin))
+	}
+
+	sort.Slice(bulletins, func(i, j int) bool {
+		return bulletins[i].Id < bulletins[j].Id
+	})
+
+	return bulletins, lastBulletinId, nil
+}
+
+// GetFlowStatistics returns the runtime statistics of the dataflow process group.
+func GetFlowStatistics(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) (*v1alpha1.FlowStatistics, error) {
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, err
+	}
+
+	pgStatusEntity, err := nClient.GetProcessGroupStatus(flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get process group status"); err != nil {
+		return nil, err
+	}
+
+	pGEntity, err := nClient.GetProcessGroup(flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get process group"); err != nil {
+		return nil, err
+	}
+
+	stats := &v1alpha1.FlowStatistics{
+		RunningCount:  pGEntity.RunningCount,
+		StoppedCount:  pGEntity.StoppedCount,
+		InvalidCount:  pGEntity.InvalidCount,
+		DisabledCount: pGEntity.DisabledCount,
+	}
+
+	if pgStatus := pgStatusEntity.ProcessGroupStatus; pgStatus != nil && pgStatus.AggregateSnapshot != nil {
+		snapshot := pgStatus.AggregateSnapshot
+		stats.FlowFilesQueued = snapshot.FlowFilesQueued
+		stats.BytesQueued = snapshot.BytesQueued
+		stats.BytesIn = snapshot.BytesIn
+		stats.BytesOut = snapshot.BytesOut
+		stats.ActiveThreadCount = snapshot.ActiveThreadCount
+		stats.LastRefreshed = pgStatus.StatsLastRefreshed
+	}
+
+	return stats, nil
+}
+
+// isBulletinLevelReported returns true if the bulletin is at least as severe as the given level.
+func isBulletinLevelReported(bulletinLevel string, level v1alpha1.BulletinLevel) bool {
+	switch strings.ToUpper(bulletinLevel) {
+	case "ERROR":
+		return level == v1alpha1.WarnBulletinLevel || level == v1alpha1.ErrorBulletinLevel
+	case "WARN", "WARNING":
+		return level == v1alpha1.WarnBulletinLevel
+	}
+	return false
+}
+
+// stopImpactedComponents stops the processors and disables the controller services referencing the parameters changed
+// by the parameter context change, with the components referencing these controller services. It returns the impacted
+// components and whether some of them are still active.
+func stopImpactedComponents(
+	nClient nificlient.NifiClient,
+	flow *v1alpha1.NifiDataflow,
+	parameterContext *v1alpha1.NifiParameterContext,
+	processGroups []nigoapi.ProcessGroupEntity,
+	processors []nigoapi.ProcessorEntity) ([]v1alpha1.ImpactedComponent, bool, error) {
+
+	// Get the parameters of the current and expected parameter contexts
+	parameters := make(map[string]map[string]*nigoapi.ParameterDto)
+	parameterContextIds := []string{""}
+	if parameterContext != nil {
+		parameterContextIds = append(parameterContextIds, parameterContext.Status.Id)
+	}
+	for _, pg := range processGroups {
+		if pg.ParameterContext != nil {
+			parameterContextIds = append(parameterContextIds, pg.ParameterContext.Id)
+		}
+	}
+	for _, id := range parameterContextIds {
+		if _, ok := parameters[id]; ok {
+			continue
+		}
+		parameters[id] = make(map[string]*nigoapi.ParameterDto)
+		if id == "" {
+			continue
+		}
+		pcEntity, err := nClient.GetParameterContext(id)
+		if err := clientwrappers.ErrorGetOperation(log, err, "Get parameter-context"); err != nil {
+			return nil, false, err
+		}
+		for _, parameter := range pcEntity.Component.Parameters {
+			parameters[id][parameter.Parameter.Name] = parameter.Parameter
+		}
+	}
+
+	expectedId := ""
+	if parameterContext != nil {
+		expectedId = parameterContext.Status.Id
+	}
+	changedParameters := make(map[string]map[string]bool)
+	for _, pg := range processGroups {
+		currentId := ""
+		if pg.ParameterContext != nil {
+			currentId = pg.ParameterContext.Id
+		}
+		changedParameters[pg.Id] = changedParameterNames(parameters[currentId], parameters[expectedId])
+	}
+
+	csEntities, err := nClient.GetFlowControllerServices(flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get flow controller services"); err != nil {
+		return nil, false, err
+	}
+	services := csEntities.ControllerServices
+
+	// Find the components referencing the changed parameters
+	impacted := make(map[string]*v1alpha1.ImpactedComponent)
+	for _, processor := range processors {
+		if names := referencedParameters(processor.Component.Config.Properties, changedParameters[processor.Component.ParentGroupId]); len(names) > 0 {
+			impacted[processor.Id] = &v1alpha1.ImpactedComponent{
+				ComponentType:  processorComponentType,
+				ComponentId:    processor.Id,
+				ComponentName:  processor.Component.Name,
+				ProcessGroupId: processor.Component.ParentGroupId,
+				Parameters:     names,
+			}
+		}
+	}
+	for _, service := range services {
+		if names := referencedParameters(service.Component.Properties, changedParameters[service.Component.ParentGroupId]); len(names) > 0 {
+			impacted[service.Id] = &v1alpha1.ImpactedComponent{
+				ComponentType:  controllerServiceComponentType,
+				ComponentId:    service.Id,
+				ComponentName:  service.Component.Name,
+				ProcessGroupId: service.Component.ParentGroupId,
+				Parameters:     names,
+			}
+		}
+	}
+
+	// The components referencing an impacted controller service are impacted too
+	for found := true; found; {
+		found = false
+		for _, service := range services {
+			if _, ok := impacted[service.Id]; !ok {
+				continue
+			}
+			for _, processor := range processors {
+				if _, ok := impacted[processor.Id]; !ok && referencesComponent(processor.Component.Config.Properties, service.Id) {
+					impacted[processor.Id] = &v1alpha1.ImpactedComponent{
+						ComponentType:  processorComponentType,
+						ComponentId:    processor.Id,
+						ComponentName:  processor.Component.Name,
+						ProcessGroupId: processor.Component.ParentGroupId,
+					}
+				}
+			}
+			for _, referencing := range services {
+				if _, ok := impacted[referencing.Id]; !ok && referencesComponent(referencing.Component.Properties, service.Id) {
+					impacted[referencing.Id] = &v1alpha1.ImpactedComponent{
+						ComponentType:  controllerServiceComponentType,
+						ComponentId:    referencing.Id,
+						ComponentName:  referencing.Component.Name,
+						ProcessGroupId: referencing.Component.ParentGroupId,
+					}
+					found = true
+				}
+			}
+		}
+	}
+
+	// Keep the states recorded by the previous stop attempts
+	for _, previous := range flow.Status.ImpactedComponents {
+		if component, ok := impacted[previous.ComponentId]; ok && !previous.Restored {
+			component.State = previous.State
+		}
+	}
+
+	// Stop the impacted processors
+	active := false
+	for _, processor := range processors {
+		component, ok := impacted[processor.Id]
+		if !ok {
+			continue
+		}
+		if processor.Component.State == "RUNNING" {
+			component.State = processor.Component.State
+			_, err := nClient.UpdateProcessorRunStatus(processor.Id, nigoapi.ProcessorRunStatusEntity{
+				Revision: processor.Revision,
+				State:    "STOPPED",
+			})
+			if err := clientwrappers.ErrorUpdateOperation(log, err, "Stop processor"); err != nil {
+				return nil, false, err
+			}
+			active = true
+		}
+		if processor.Status != nil && processor.Status.AggregateSnapshot != nil &&
+			processor.Status.AggregateSnapshot.ActiveThreadCount > 0 {
+			active = true
+		}
+	}
+
+	// Disable the impacted controller services, once the components referencing them are no longer active
+	processorsActive := active
+	for _, service := range services {
+		component, ok := impacted[service.Id]
+		if !ok {
+			continue
+		}
+		switch service.Component.State {
+		case "ENABLED", "ENABLING":
+			component.State = "ENABLED"
+			if processorsActive || isReferencedByEnabledService(service.Id, services, impacted) {
+				active = true
+				continue
+			}
+			_, err := nClient.UpdateControllerServiceRunStatus(service.Id, nigoapi.ControllerServiceRunStatusEntity{
+				Revision: service.Revision,
+				State:    "DISABLED",
+			})
+			if err := clientwrappers.ErrorUpdateOperation(log, err, "Disable controller service"); err != nil {
+				return nil, false, err
+			}
+			active = true
+		case "DISABLING":
+			active = true
+		}
+	}
+
+	var impactedComponents []v1alpha1.ImpactedComponent
+	for _, component := range impacted {
+		impactedComponents = append(impactedComponents, *component)
+	}
+	sort.Slice(impactedComponents, func(i, j int) bool {
+		return impactedComponents[i].ComponentId < impactedComponents[j].ComponentId
+	})
+
+	return impactedComponents, active, nil
+}
+
+// restoreImpactedComponents enables the controller services and starts the processors stopped during the latest
+// parameter context change, and returns whether some of them are still being restored.
+func restoreImpactedComponents(
+	nClient nificlient.NifiClient,
+	flow *v1alpha1.NifiDataflow,
+	processors []nigoapi.ProcessorEntity) (bool, error) {
+
+	toRestore := false
+	for _, component := range flow.Status.ImpactedComponents {
+		toRestore = toRestore || !component.Restored
+	}
+	if !toRestore {
+		return false, nil
+	}
+
+	csEntities, err := nClient.GetFlowControllerServices(flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get flow controller services"); err != nil {
+		return false, err
+	}
+	services := make(map[string]nigoapi.ControllerServiceEntity)
+	for _, service := range csEntities.ControllerServices {
+		services[service.Id] = service
+	}
+
+	// Enable the controller services first, starting with the ones not referencing other impacted controller services
+	pending := false
+	for i, component := range flow.Status.ImpactedComponents {
+		if component.Restored || component.ComponentType != controllerServiceComponentType {
+			continue
+		}
+		service, ok := services[component.ComponentId]
+		if !ok || component.State != "ENABLED" || service.Component.State == "ENABLED" {
+			flow.Status.ImpactedComponents[i].Restored = true
+			continue
+		}
+
+		pending = true
+		if service.Component.State != "DISABLED" || referencesDisabledService(service, services, flow.Status.ImpactedComponents) {
+			continue
+		}
+		_, err := nClient.UpdateControllerServiceRunStatus(service.Id, nigoapi.ControllerServiceRunStatusEntity{
+			Revision: service.Revision,
+			State:    "ENABLED",
+		})
+		if err := clientwrappers.ErrorUpdateOperation(log, err, "Enable controller service"); err != nil {
+			return false, err
+		}
+	}
+	if pending {
+		return true, nil
+	}
+
+	// Then start the processors
+	for i, component := range flow.Status.ImpactedComponents {
+		if component.Restored || component.ComponentType != processorComponentType {
+			continue
+		}
+		for _, processor := range processors {
+			if processor.Id == component.ComponentId && component.State == "RUNNING" && processor.Component.State == "STOPPED" {
+				_, err := nClient.UpdateProcessorRunStatus(processor.Id, nigoapi.ProcessorRunStatusEntity{
+					Revision: processor.Revision,
+					State:    "RUNNING",
+				})
+				if err := clientwrappers.ErrorUpdateOperation(log, err, "Start processor"); err != nil {
+					return false, err
+				}
+			}
+		}
+		flow.Status.ImpactedComponents[i].Restored = true
+	}
+
+	return false, nil
+}
+
+// changedParameterNames returns the names of the parameters whose value differs between the two parameter contexts.
+// The sensitive values are never returned by NiFi, so the sensitive parameters are always considered as changed.
+func changedParameterNames(current, expected map[string]*nigoapi.ParameterDto) map[string]bool {
+	changed := make(map[string]bool)
+	for name, parameter := range current {
+		expectedParameter, ok := expected[name]
+		if !ok || parameter.Sensitive || expectedParameter.Sensitive ||
+			!((parameter.Value == nil && expectedParameter.Value == nil) ||
+				(parameter.Value != nil && expectedParameter.Value != nil && *parameter.Value == *expectedParameter.Value)) {
+			changed[name] = true
+		}
+	}
+	for name := range expected {
+		if _, ok := current[name]; !ok {
+			changed[name] = true
+		}
+	}
+	return changed
+}
+
+// referencedParameters returns the sorted names of the given parameters referenced by the property values.
+func referencedParameters(properties map[string]string, parameters map[string]bool) []string {
+	referenced := make(map[string]bool)
+	for _, value := range properties {
+		for _, match := range parameterReferenceRegex.FindAllStringSubmatch(value, -1) {
+			if parameters[match[1]] {
+				referenced[match[1]] = true
+			}
+		}
+	}
+
+	var names []string
+	for name := range referenced {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// referencesComponent returns whether one of the property values references the component with the given id.
+func referencesComponent(properties map[string]string, id string) bool {
+	for _, value := range properties {
+		if value == id {
+			return true
+		}
+	}
+	return false
+}
+
+// isReferencedByEnabledService returns whether an impacted controller service referencing the given one is still enabled.
+func isReferencedByEnabledService(id string, services []nigoapi.ControllerServiceEntity,
+	impacted map[string]*v1alpha1.ImpactedComponent) bool {
+
+	for _, service := range services {
+		if _, ok := impacted[service.Id]; ok && service.Component.State != "DISABLED" &&
+			referencesComponent(service.Component.Properties, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesDisabledService returns whether the controller service references an impacted controller service
+// which is not enabled yet.
+func referencesDisabledService(service nigoapi.ControllerServiceEntity, services map[string]nigoapi.ControllerServiceEntity,
+	impactedComponents []v1alpha1.ImpactedComponent) bool {
+
+	for _, component := range impactedComponents {
+		referenced, ok := services[component.ComponentId]
+		if ok && component.ComponentType == controllerServiceComponentType && component.State == "ENABLED" &&
+			referenced.Component.State != "ENABLED" && referencesComponent(service.Component.Properties, referenced.Id) {
+			return true
+		}
+	}
+	return false
+}
+
 // processGroupFromFlow convert a ProcessGroupFlowEntity to NifiDataflow
 func processGroupFromFlow(
 	flowEntity *nigoapi.ProcessGroupFlowEntity,
@@ -689,6 +1429,33 @@ func dropRequest2Status(connectionId string, dropRequest *nigoapi.DropRequestEnt
 	}
 }
 
+func bulletin2Status(bulletin *nigoapi.BulletinDto) v1alpha1.Bulletin {
+	return v1alpha1.Bulletin{
+		Id:          bulletin.Id,
+		Level:       bulletin.Level,
+		SourceId:    bulletin.SourceId,
+		SourceName:  bulletin.SourceName,
+		GroupId:     bulletin.GroupId,
+		Message:     bulletin.Message,
+		Timestamp:   bulletin.Timestamp,
+		NodeAddress: bulletin.NodeAddress,
+	}
+}
+
+func localChange2Status(component nigoapi.ComponentDifferenceDto) v1alpha1.LocalChange {
+	var differences []string
+	for _, difference := range component.Differences {
+		differences = append(differences, difference.Difference)
+	}
+	return v1alpha1.LocalChange{
+		ComponentType:  component.ComponentType,
+		ComponentId:    component.ComponentId,
+		ComponentName:  component.ComponentName,
+		ProcessGroupId: component.ProcessGroupId,
+		Differences:    differences,
+	}
+}
+
 func updateRequest2Status(updateRequest *nigoapi.VersionedFlowUpdateRequestEntity,
 	updateType v1alpha1.DataflowUpdateRequestType) *v1alpha1.UpdateRequest {
 	ur := updateRequest.Request
@@ -0,0 +1,834 @@
+package dataflow
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/common"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeNifiClient implements the NiFi client calls used to manage the dataflow components, recording the run status
+// updates and the removed process groups.
+type fakeNifiClient struct {
+	nificlient.NifiClient
+	parameterContexts  map[string]*nigoapi.ParameterContextEntity
+	controllerServices []nigoapi.ControllerServiceEntity
+	processGroups      map[string]*nigoapi.ProcessGroupEntity
+	flows              map[string]nigoapi.FlowDto
+	bulletins          []nigoapi.BulletinEntity
+	noBulletinBoard    bool
+	updates            map[string]string
+	removed            []string
+}
+
+func (f *fakeNifiClient) GetFlow(id string) (*nigoapi.ProcessGroupFlowEntity, error) {
+	flow := f.flows[id]
+	return &nigoapi.ProcessGroupFlowEntity{ProcessGroupFlow: &nigoapi.ProcessGroupFlowDto{Id: id, Flow: &flow}}, nil
+}
+
+func (f *fakeNifiClient) GetProcessGroup(id string) (*nigoapi.ProcessGroupEntity, error) {
+	if pg, ok := f.processGroups[id]; ok {
+		return pg, nil
+	}
+	return nil, nificlient.ErrNifiClusterReturned404
+}
+
+func (f *fakeNifiClient) RemoveProcessGroup(entity nigoapi.ProcessGroupEntity) error {
+	f.removed = append(f.removed, entity.Id)
+	return nil
+}
+
+func (f *fakeNifiClient) GetBulletinBoard(after int64) (*nigoapi.BulletinBoardEntity, error) {
+	if f.noBulletinBoard {
+		return &nigoapi.BulletinBoardEntity{}, nil
+	}
+	return &nigoapi.BulletinBoardEntity{BulletinBoard: &nigoapi.BulletinBoardDto{Bulletins: f.bulletins}}, nil
+}
+
+func (f *fakeNifiClient) UpdateFlowProcessGroup(entity nigoapi.ScheduleComponentsEntity) (*nigoapi.ScheduleComponentsEntity, error) {
+	f.updates[entity.Id] = entity.State
+	return &entity, nil
+}
+
+func (f *fakeNifiClient) UpdateFlowControllerServices(entity nigoapi.ActivateControllerServicesEntity) (*nigoapi.ActivateControllerServicesEntity, error) {
+	return &entity, nil
+}
+
+func (f *fakeNifiClient) GetParameterContext(id string) (*nigoapi.ParameterContextEntity, error) {
+	return f.parameterContexts[id], nil
+}
+
+func (f *fakeNifiClient) GetFlowControllerServices(id string) (*nigoapi.ControllerServicesEntity, error) {
+	return &nigoapi.ControllerServicesEntity{ControllerServices: f.controllerServices}, nil
+}
+
+func (f *fakeNifiClient) UpdateProcessorRunStatus(id string, entity nigoapi.ProcessorRunStatusEntity) (*nigoapi.ProcessorEntity, error) {
+	f.updates[id] = entity.State
+	return &nigoapi.ProcessorEntity{Id: id}, nil
+}
+
+func (f *fakeNifiClient) UpdateControllerServiceRunStatus(id string, entity nigoapi.ControllerServiceRunStatusEntity) (*nigoapi.ControllerServiceEntity, error) {
+	f.updates[id] = entity.State
+	return &nigoapi.ControllerServiceEntity{Id: id}, nil
+}
+
+func parameterContextEntity(id string, parameters ...*nigoapi.ParameterDto) *nigoapi.ParameterContextEntity {
+	entity := &nigoapi.ParameterContextEntity{Id: id, Component: &nigoapi.ParameterContextDto{Id: id}}
+	for _, parameter := range parameters {
+		entity.Component.Parameters = append(entity.Component.Parameters, nigoapi.ParameterEntity{Parameter: parameter})
+	}
+	return entity
+}
+
+func parameter(name string, value string, sensitive bool) *nigoapi.ParameterDto {
+	if sensitive {
+		return &nigoapi.ParameterDto{Name: name, Sensitive: true}
+	}
+	return &nigoapi.ParameterDto{Name: name, Value: &value}
+}
+
+func processor(id, state string, activeThreads int32, properties map[string]string) nigoapi.ProcessorEntity {
+	return nigoapi.ProcessorEntity{
+		Id:       id,
+		Revision: &nigoapi.RevisionDto{},
+		Component: &nigoapi.ProcessorDto{
+			Id:            id,
+			Name:          id,
+			ParentGroupId: "pg",
+			State:         state,
+			Config:        &nigoapi.ProcessorConfigDto{Properties: properties},
+		},
+		Status: &nigoapi.ProcessorStatusDto{
+			AggregateSnapshot: &nigoapi.ProcessorStatusSnapshotDto{ActiveThreadCount: activeThreads},
+		},
+	}
+}
+
+func controllerService(id, state string, properties map[string]string) nigoapi.ControllerServiceEntity {
+	return nigoapi.ControllerServiceEntity{
+		Id:       id,
+		Revision: &nigoapi.RevisionDto{},
+		Component: &nigoapi.ControllerServiceDto{
+			Id:            id,
+			Name:          id,
+			ParentGroupId: "pg",
+			State:         state,
+			Properties:    properties,
+		},
+	}
+}
+
+func TestStopImpactedComponents(t *testing.T) {
+	parameterContexts := map[string]*nigoapi.ParameterContextEntity{
+		"current": parameterContextEntity("current",
+			parameter("unchanged", "1", false), parameter("changed", "2", false), parameter("secret", "", true)),
+		"expected": parameterContextEntity("expected",
+			parameter("unchanged", "1", false), parameter("changed", "3", false), parameter("secret", "", true)),
+	}
+
+	impacted := func(componentType, id, state string, parameters ...string) v1alpha1.ImpactedComponent {
+		return v1alpha1.ImpactedComponent{
+			ComponentType:  componentType,
+			ComponentId:    id,
+			ComponentName:  id,
+			ProcessGroupId: "pg",
+			Parameters:     parameters,
+			State:          state,
+		}
+	}
+
+	testCases := []struct {
+		name                      string
+		currentParameterContextId string
+		previous                  []v1alpha1.ImpactedComponent
+		processors                []nigoapi.ProcessorEntity
+		services                  []nigoapi.ControllerServiceEntity
+		expectedImpacted          []v1alpha1.ImpactedComponent
+		expectedActive            bool
+		expectedUpdates           map[string]string
+	}{
+		{
+			name:                      "stop the processors referencing a changed parameter or an impacted service",
+			currentParameterContextId: "current",
+			processors: []nigoapi.ProcessorEntity{
+				processor("p-unchanged", "RUNNING", 1, map[string]string{"url": "#{unchanged}"}),
+				processor("p-changed", "RUNNING", 1, map[string]string{"url": "#{changed}"}),
+				processor("p-service", "RUNNING", 0, map[string]string{"service": "cs-secret"}),
+			},
+			services: []nigoapi.ControllerServiceEntity{
+				controllerService("cs-secret", "ENABLED", map[string]string{"password": "#{'secret'}"}),
+				controllerService("cs-referencing", "ENABLED", map[string]string{"service": "cs-secret"}),
+				controllerService("cs-other", "ENABLED", map[string]string{"url": "#{unchanged}"}),
+			},
+			expectedImpacted: []v1alpha1.ImpactedComponent{
+				impacted(controllerServiceComponentType, "cs-referencing", "ENABLED"),
+				impacted(controllerServiceComponentType, "cs-secret", "ENABLED", "secret"),
+				impacted(processorComponentType, "p-changed", "RUNNING", "changed"),
+				impacted(processorComponentType, "p-service", "RUNNING"),
+			},
+			expectedActive:  true,
+			expectedUpdates: map[string]string{"p-changed": "STOPPED", "p-service": "STOPPED"},
+		},
+		{
+			name:                      "disable the services not referenced by an enabled impacted service",
+			currentParameterContextId: "current",
+			previous: []v1alpha1.ImpactedComponent{
+				impacted(processorComponentType, "p-service", "RUNNING"),
+			},
+			processors: []nigoapi.ProcessorEntity{
+				processor("p-service", "STOPPED", 0, map[string]string{"service": "cs-secret"}),
+			},
+			services: []nigoapi.ControllerServiceEntity{
+				controllerService("cs-secret", "ENABLED", map[string]string{"password": "#{secret}"}),
+				controllerService("cs-referencing", "ENABLED", map[string]string{"service": "cs-secret"}),
+			},
+			expectedImpacted: []v1alpha1.ImpactedComponent{
+				impacted(controllerServiceComponentType, "cs-referencing", "ENABLED"),
+				impacted(controllerServiceComponentType, "cs-secret", "ENABLED", "secret"),
+				impacted(processorComponentType, "p-service", "RUNNING"),
+			},
+			expectedActive:  true,
+			expectedUpdates: map[string]string{"cs-referencing": "DISABLED"},
+		},
+		{
+			name:                      "wait for the active threads of the stopped processors",
+			currentParameterContextId: "current",
+			processors: []nigoapi.ProcessorEntity{
+				processor("p-changed", "STOPPED", 2, map[string]string{"url": "#{changed}"}),
+			},
+			expectedImpacted: []v1alpha1.ImpactedComponent{
+				impacted(processorComponentType, "p-changed", "", "changed"),
+			},
+			expectedActive:  true,
+			expectedUpdates: map[string]string{},
+		},
+		{
+			name:                      "nothing to stop once the components are stopped and disabled",
+			currentParameterContextId: "current",
+			previous: []v1alpha1.ImpactedComponent{
+				impacted(processorComponentType, "p-changed", "RUNNING", "changed"),
+				impacted(controllerServiceComponentType, "cs-secret", "ENABLED", "secret"),
+			},
+			processors: []nigoapi.ProcessorEntity{
+				processor("p-changed", "STOPPED", 0, map[string]string{"url": "#{changed}"}),
+			},
+			services: []nigoapi.ControllerServiceEntity{
+				controllerService("cs-secret", "DISABLED", map[string]string{"password": "#{secret}"}),
+			},
+			expectedImpacted: []v1alpha1.ImpactedComponent{
+				impacted(controllerServiceComponentType, "cs-secret", "ENABLED", "secret"),
+				impacted(processorComponentType, "p-changed", "RUNNING", "changed"),
+			},
+			expectedActive:  false,
+			expectedUpdates: map[string]string{},
+		},
+		{
+			name:                      "only the sensitive parameters are changed within the same parameter context",
+			currentParameterContextId: "expected",
+			processors: []nigoapi.ProcessorEntity{
+				processor("p-changed", "RUNNING", 0, map[string]string{"url": "#{changed}"}),
+				processor("p-secret", "RUNNING", 0, map[string]string{"password": "#{secret}"}),
+			},
+			expectedImpacted: []v1alpha1.ImpactedComponent{
+				impacted(processorComponentType, "p-secret", "RUNNING", "secret"),
+			},
+			expectedActive:  true,
+			expectedUpdates: map[string]string{"p-secret": "STOPPED"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			client := &fakeNifiClient{
+				parameterContexts:  parameterContexts,
+				controllerServices: test.services,
+				updates:            make(map[string]string),
+			}
+			flow := &v1alpha1.NifiDataflow{}
+			flow.Status.ProcessGroupID = "pg"
+			flow.Status.ImpactedComponents = test.previous
+			parameterContext := &v1alpha1.NifiParameterContext{}
+			parameterContext.Status.Id = "expected"
+			processGroups := []nigoapi.ProcessGroupEntity{{
+				Id:               "pg",
+				ParameterContext: &nigoapi.ParameterContextReferenceEntity{Id: test.currentParameterContextId},
+			}}
+
+			impactedComponents, active, err := stopImpactedComponents(client, flow, parameterContext, processGroups, test.processors)
+			if err != nil {
+				t.Fatal("Expected no error, got:", err)
+			}
+			if !reflect.DeepEqual(impactedComponents, test.expectedImpacted) {
+				t.Errorf("Expected impacted components %+v, got: %+v", test.expectedImpacted, impactedComponents)
+			}
+			if active != test.expectedActive {
+				t.Errorf("Expected active %v, got: %v", test.expectedActive, active)
+			}
+			if !reflect.DeepEqual(client.updates, test.expectedUpdates) {
+				t.Errorf("Expected run status updates %v, got: %v", test.expectedUpdates, client.updates)
+			}
+		})
+	}
+}
+
+func TestRestoreImpactedComponents(t *testing.T) {
+	flow := &v1alpha1.NifiDataflow{}
+	flow.Status.ProcessGroupID = "pg"
+	flow.Status.ImpactedComponents = []v1alpha1.ImpactedComponent{
+		{ComponentType: controllerServiceComponentType, ComponentId: "cs-secret", State: "ENABLED"},
+		{ComponentType: controllerServiceComponentType, ComponentId: "cs-referencing", State: "ENABLED"},
+		{ComponentType: processorComponentType, ComponentId: "p-running", State: "RUNNING"},
+		{ComponentType: processorComponentType, ComponentId: "p-stopped", State: "STOPPED"},
+	}
+	processors := []nigoapi.ProcessorEntity{
+		processor("p-running", "STOPPED", 0, nil),
+		processor("p-stopped", "STOPPED", 0, nil),
+	}
+	client := &fakeNifiClient{
+		controllerServices: []nigoapi.ControllerServiceEntity{
+			controllerService("cs-secret", "DISABLED", nil),
+			controllerService("cs-referencing", "DISABLED", map[string]string{"service": "cs-secret"}),
+		},
+		updates: make(map[string]string),
+	}
+
+	// the referenced service is enabled first
+	pending, err := restoreImpactedComponents(client, flow, processors)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if !pending || !reflect.DeepEqual(client.updates, map[string]string{"cs-secret": "ENABLED"}) {
+		t.Errorf("Expected only the referenced service to be enabled, got pending %v and updates %v", pending, client.updates)
+	}
+
+	// then the referencing one
+	client.controllerServices[0].Component.State = "ENABLED"
+	client.updates = make(map[string]string)
+	if pending, err = restoreImpactedComponents(client, flow, processors); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if !pending || !reflect.DeepEqual(client.updates, map[string]string{"cs-referencing": "ENABLED"}) {
+		t.Errorf("Expected the referencing service to be enabled, got pending %v and updates %v", pending, client.updates)
+	}
+
+	// then the processors which were running
+	client.controllerServices[1].Component.State = "ENABLED"
+	client.updates = make(map[string]string)
+	if pending, err = restoreImpactedComponents(client, flow, processors); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if pending || !reflect.DeepEqual(client.updates, map[string]string{"p-running": "RUNNING"}) {
+		t.Errorf("Expected the running processor to be started, got pending %v and updates %v", pending, client.updates)
+	}
+	for _, component := range flow.Status.ImpactedComponents {
+		if !component.Restored {
+			t.Error("Expected the component to be restored:", component.ComponentId)
+		}
+	}
+}
+
+func TestChangedParameterNames(t *testing.T) {
+	testCases := []struct {
+		name     string
+		current  map[string]*nigoapi.ParameterDto
+		expected map[string]*nigoapi.ParameterDto
+		changed  map[string]bool
+	}{
+		{
+			name:     "same values",
+			current:  map[string]*nigoapi.ParameterDto{"a": parameter("a", "1", false)},
+			expected: map[string]*nigoapi.ParameterDto{"a": parameter("a", "1", false)},
+			changed:  map[string]bool{},
+		},
+		{
+			name:     "changed value",
+			current:  map[string]*nigoapi.ParameterDto{"a": parameter("a", "1", false)},
+			expected: map[string]*nigoapi.ParameterDto{"a": parameter("a", "2", false)},
+			changed:  map[string]bool{"a": true},
+		},
+		{
+			name:     "unset value",
+			current:  map[string]*nigoapi.ParameterDto{"a": parameter("a", "1", false)},
+			expected: map[string]*nigoapi.ParameterDto{"a": {Name: "a"}},
+			changed:  map[string]bool{"a": true},
+		},
+		{
+			name:     "sensitive value",
+			current:  map[string]*nigoapi.ParameterDto{"a": parameter("a", "", true)},
+			expected: map[string]*nigoapi.ParameterDto{"a": parameter("a", "", true)},
+			changed:  map[string]bool{"a": true},
+		},
+		{
+			name:     "added and removed parameters",
+			current:  map[string]*nigoapi.ParameterDto{"removed": parameter("removed", "1", false)},
+			expected: map[string]*nigoapi.ParameterDto{"added": parameter("added", "1", false)},
+			changed:  map[string]bool{"removed": true, "added": true},
+		},
+		{
+			name:     "no parameter context",
+			current:  map[string]*nigoapi.ParameterDto{},
+			expected: map[string]*nigoapi.ParameterDto{"a": parameter("a", "1", false)},
+			changed:  map[string]bool{"a": true},
+		},
+	}
+
+	for _, test := range testCases {
+		if changed := changedParameterNames(test.current, test.expected); !reflect.DeepEqual(changed, test.changed) {
+			t.Errorf("%s: expected changed parameters %v, got: %v", test.name, test.changed, changed)
+		}
+	}
+}
+
+func TestReferencedParameters(t *testing.T) {
+	parameters := map[string]bool{"a": true, "my name": true}
+	testCases := []struct {
+		properties map[string]string
+		expected   []string
+	}{
+		{map[string]string{"p": "#{a}"}, []string{"a"}},
+		{map[string]string{"p": "prefix-#{'my name'}-#{a}", "q": "#{a}"}, []string{"a", "my name"}},
+		{map[string]string{"p": "#{unchanged}"}, nil},
+		{map[string]string{"p": "a"}, nil},
+		{nil, nil},
+	}
+
+	for _, test := range testCases {
+		if names := referencedParameters(test.properties, parameters); !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("Expected referenced parameters %v for %v, got: %v", test.expected, test.properties, names)
+		}
+	}
+}
+
+func TestReferencesComponent(t *testing.T) {
+	if !referencesComponent(map[string]string{"service": "cs", "other": "value"}, "cs") {
+		t.Error("Expected the component to be referenced")
+	}
+	if referencesComponent(map[string]string{"service": "prefix-cs"}, "cs") {
+		t.Error("Expected the component to not be referenced by a partial value")
+	}
+	if referencesComponent(nil, "cs") {
+		t.Error("Expected the component to not be referenced without properties")
+	}
+}
+
+func TestIsReferencedByEnabledService(t *testing.T) {
+	services := []nigoapi.ControllerServiceEntity{
+		controllerService("cs", "ENABLED", nil),
+		controllerService("cs-enabled", "ENABLED", map[string]string{"service": "cs"}),
+		controllerService("cs-disabled", "DISABLED", map[string]string{"service": "cs"}),
+	}
+
+	testCases := []struct {
+		name     string
+		impacted []string
+		expected bool
+	}{
+		{"referenced by an enabled impacted service", []string{"cs", "cs-enabled"}, true},
+		{"referenced by a disabled impacted service", []string{"cs", "cs-disabled"}, false},
+		{"referenced by an enabled service not impacted", []string{"cs"}, false},
+	}
+
+	for _, test := range testCases {
+		impacted := make(map[string]*v1alpha1.ImpactedComponent)
+		for _, id := range test.impacted {
+			impacted[id] = &v1alpha1.ImpactedComponent{ComponentId: id}
+		}
+		if referenced := isReferencedByEnabledService("cs", services, impacted); referenced != test.expected {
+			t.Errorf("%s: expected %v, got: %v", test.name, test.expected, referenced)
+		}
+	}
+}
+
+func TestReferencesDisabledService(t *testing.T) {
+	service := controllerService("cs-referencing", "DISABLED", map[string]string{"service": "cs"})
+
+	testCases := []struct {
+		name     string
+		state    string
+		previous string
+		expected bool
+	}{
+		{"referenced service to enable", "DISABLED", "ENABLED", true},
+		{"referenced service enabled", "ENABLED", "ENABLED", false},
+		{"referenced service disabled before the change", "DISABLED", "", false},
+	}
+
+	for _, test := range testCases {
+		services := map[string]nigoapi.ControllerServiceEntity{"cs": controllerService("cs", test.state, nil)}
+		impactedComponents := []v1alpha1.ImpactedComponent{
+			{ComponentType: controllerServiceComponentType, ComponentId: "cs", State: test.previous},
+		}
+		if referenced := referencesDisabledService(service, services, impactedComponents); referenced != test.expected {
+			t.Errorf("%s: expected %v, got: %v", test.name, test.expected, referenced)
+		}
+	}
+}
+
+func TestIsDrainTimedOut(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	drainTimeout := &metav1.Duration{Duration: 10 * time.Minute}
+
+	testCases := []struct {
+		name         string
+		drainTimeout *metav1.Duration
+		drain        *v1alpha1.DrainStatus
+		expected     bool
+	}{
+		{"no drain timeout", nil, &v1alpha1.DrainStatus{StartTime: "2021-03-10T11:00:00Z"}, false},
+		{"no drain", drainTimeout, nil, false},
+		{"drain in progress", drainTimeout, &v1alpha1.DrainStatus{StartTime: "2021-03-10T11:55:00Z"}, false},
+		{"drain timed out", drainTimeout, &v1alpha1.DrainStatus{StartTime: "2021-03-10T11:45:00Z"}, true},
+		{"absent start time", drainTimeout, &v1alpha1.DrainStatus{}, false},
+		{"bad start time", drainTimeout, &v1alpha1.DrainStatus{StartTime: "10/03/2021 11:45"}, false},
+	}
+
+	for _, test := range testCases {
+		flow := &v1alpha1.NifiDataflow{}
+		flow.Spec.DrainTimeout = test.drainTimeout
+		flow.Status.Drain = test.drain
+		if timedOut := isDrainTimedOut(flow, now); timedOut != test.expected {
+			t.Errorf("%s: expected timed out %v, got: %v", test.name, test.expected, timedOut)
+		}
+	}
+}
+
+func TestUpdateDrainStatus(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		drain    *v1alpha1.DrainStatus
+		expected v1alpha1.DrainStatus
+	}{
+		{
+			name:     "drain started",
+			expected: v1alpha1.DrainStatus{StartTime: "2021-03-10T12:00:00Z", Generation: 2, RemainingFlowFiles: 5},
+		},
+		{
+			name:     "drain in progress",
+			drain:    &v1alpha1.DrainStatus{StartTime: "2021-03-10T11:55:00Z", Generation: 2, RemainingFlowFiles: 10},
+			expected: v1alpha1.DrainStatus{StartTime: "2021-03-10T11:55:00Z", Generation: 2, RemainingFlowFiles: 5},
+		},
+		{
+			name:     "drain timed out",
+			drain:    &v1alpha1.DrainStatus{StartTime: "2021-03-10T11:45:00Z", Generation: 2, RemainingFlowFiles: 10},
+			expected: v1alpha1.DrainStatus{StartTime: "2021-03-10T11:45:00Z", Generation: 2, RemainingFlowFiles: 5, TimedOut: true},
+		},
+		{
+			name:     "drain restarted for a new generation",
+			drain:    &v1alpha1.DrainStatus{StartTime: "2021-03-10T11:45:00Z", Generation: 1, TimedOut: true},
+			expected: v1alpha1.DrainStatus{StartTime: "2021-03-10T12:00:00Z", Generation: 2, RemainingFlowFiles: 5},
+		},
+		{
+			name:     "drain restarted without start time",
+			drain:    &v1alpha1.DrainStatus{Generation: 2},
+			expected: v1alpha1.DrainStatus{StartTime: "2021-03-10T12:00:00Z", Generation: 2, RemainingFlowFiles: 5},
+		},
+		{
+			name:     "drain restarted with a bad start time",
+			drain:    &v1alpha1.DrainStatus{StartTime: "10/03/2021 11:45", Generation: 2},
+			expected: v1alpha1.DrainStatus{StartTime: "2021-03-10T12:00:00Z", Generation: 2, RemainingFlowFiles: 5},
+		},
+	}
+
+	for _, test := range testCases {
+		flow := &v1alpha1.NifiDataflow{}
+		flow.Generation = 2
+		flow.Spec.DrainTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+		flow.Status.Drain = test.drain
+		updateDrainStatus(flow, 5, now)
+		if *flow.Status.Drain != test.expected {
+			t.Errorf("%s: expected drain status %+v, got: %+v", test.name, test.expected, *flow.Status.Drain)
+		}
+	}
+}
+
+func TestDrainFallback(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+
+	testCases := []struct {
+		name     string
+		fallback v1alpha1.DrainFallback
+		deleted  bool
+		expected v1alpha1.DrainFallback
+	}{
+		{"abort by default", "", false, v1alpha1.AbortDrainFallback},
+		{"abort", v1alpha1.AbortDrainFallback, false, v1alpha1.AbortDrainFallback},
+		{"drop", v1alpha1.DropDrainFallback, false, v1alpha1.DropDrainFallback},
+		{"force", v1alpha1.ForceDrainFallback, false, v1alpha1.ForceDrainFallback},
+		{"drop instead of aborting a removal", v1alpha1.AbortDrainFallback, true, v1alpha1.DropDrainFallback},
+		{"drop instead of aborting a removal by default", "", true, v1alpha1.DropDrainFallback},
+		{"force a removal", v1alpha1.ForceDrainFallback, true, v1alpha1.ForceDrainFallback},
+	}
+
+	for _, test := range testCases {
+		flow := &v1alpha1.NifiDataflow{}
+		flow.Spec.DrainFallback = test.fallback
+		if test.deleted {
+			flow.SetDeletionTimestamp(&deletionTimestamp)
+		}
+		if fallback := drainFallback(flow); fallback != test.expected {
+			t.Errorf("%s: expected fallback %s, got: %s", test.name, test.expected, fallback)
+		}
+	}
+}
+
+func TestOutOfSyncDataflow(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		policy                 v1alpha1.LocalChangesPolicy
+		state                  string
+		flowVersion            int32
+		expectedUpdateRequired bool
+		expectedLocalChanges   bool
+		expectedOutOfSync      bool
+	}{
+		{
+			name:        "in sync",
+			policy:      v1alpha1.CommitLocalChangesPolicy,
+			state:       "UP_TO_DATE",
+			flowVersion: 1,
+		},
+		{
+			name:                 "local changes to commit",
+			policy:               v1alpha1.CommitLocalChangesPolicy,
+			state:                "LOCALLY_MODIFIED",
+			flowVersion:          1,
+			expectedLocalChanges: true,
+			expectedOutOfSync:    true,
+		},
+		{
+			name:                   "local changes to revert",
+			policy:                 v1alpha1.RevertLocalChangesPolicy,
+			state:                  "LOCALLY_MODIFIED",
+			flowVersion:            1,
+			expectedUpdateRequired: true,
+			expectedLocalChanges:   true,
+			expectedOutOfSync:      true,
+		},
+		{
+			name:                 "local changes reported",
+			policy:               v1alpha1.ReportLocalChangesPolicy,
+			state:                "LOCALLY_MODIFIED",
+			flowVersion:          1,
+			expectedLocalChanges: true,
+		},
+		{
+			name:                   "new version",
+			policy:                 v1alpha1.CommitLocalChangesPolicy,
+			state:                  "STALE",
+			flowVersion:            2,
+			expectedUpdateRequired: true,
+			expectedOutOfSync:      true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			pg := nigoapi.ProcessGroupEntity{
+				Id: "pg",
+				Component: &nigoapi.ProcessGroupDto{
+					Id:            "pg",
+					Name:          "flow",
+					ParentGroupId: "root",
+					Position:      &nigoapi.PositionDto{},
+					VersionControlInformation: &nigoapi.VersionControlInformationDto{
+						RegistryId: "registry",
+						BucketId:   "bucket",
+						FlowId:     "flow",
+						Version:    1,
+						State:      test.state,
+					},
+				},
+			}
+			client := &fakeNifiClient{processGroups: map[string]*nigoapi.ProcessGroupEntity{"pg": &pg}}
+			newNifiFromConfig := common.NewNifiFromConfig
+			common.NewNifiFromConfig = func(*clientconfig.NifiConfig) (nificlient.NifiClient, error) {
+				return client, nil
+			}
+			defer func() { common.NewNifiFromConfig = newNifiFromConfig }()
+
+			flow := &v1alpha1.NifiDataflow{ObjectMeta: metav1.ObjectMeta{Name: "flow"}}
+			flow.Spec.BucketId = "bucket"
+			flow.Spec.FlowId = "flow"
+			flow.Spec.FlowVersion = &test.flowVersion
+			flow.Spec.LocalChangesPolicy = test.policy
+			flow.Status.ProcessGroupID = "pg"
+			registry := &v1alpha1.NifiRegistryClient{}
+			registry.Status.Id = "registry"
+			config := &clientconfig.NifiConfig{RootProcessGroupId: "root"}
+
+			updateRequired, localChanges, err := outOfSyncDataflow(client, flow, config, registry, nil, &pg)
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if updateRequired != test.expectedUpdateRequired {
+				t.Errorf("Expected update required %t, got: %t", test.expectedUpdateRequired, updateRequired)
+			}
+			if localChanges != test.expectedLocalChanges {
+				t.Errorf("Expected local changes %t, got: %t", test.expectedLocalChanges, localChanges)
+			}
+
+			outOfSync, err := IsOutOfSyncDataflow(flow, config, registry, nil)
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if outOfSync != test.expectedOutOfSync {
+				t.Errorf("Expected out of sync %t, got: %t", test.expectedOutOfSync, outOfSync)
+			}
+		})
+	}
+}
+
+func TestAdoptDataflow(t *testing.T) {
+	versioned := func(id, name, flowId string) nigoapi.ProcessGroupEntity {
+		return nigoapi.ProcessGroupEntity{
+			Id: id,
+			Component: &nigoapi.ProcessGroupDto{
+				Id:   id,
+				Name: name,
+				VersionControlInformation: &nigoapi.VersionControlInformationDto{
+					RegistryId: "registry",
+					BucketId:   "bucket",
+					FlowId:     flowId,
+				},
+			},
+		}
+	}
+	unversioned := nigoapi.ProcessGroupEntity{Id: "unversioned", Component: &nigoapi.ProcessGroupDto{Name: "unversioned"}}
+
+	testCases := []struct {
+		name                 string
+		adoptId              string
+		adoptName            string
+		processGroups        []nigoapi.ProcessGroupEntity
+		expectedProcessGroup string
+	}{
+		{
+			name:                 "adopted by id",
+			adoptId:              "pg",
+			processGroups:        []nigoapi.ProcessGroupEntity{versioned("pg", "flow", "flow")},
+			expectedProcessGroup: "pg",
+		},
+		{
+			name:          "id not found",
+			adoptId:       "missing",
+			processGroups: []nigoapi.ProcessGroupEntity{versioned("pg", "flow", "flow")},
+		},
+		{
+			name:      "adopted by name",
+			adoptName: "flow",
+			processGroups: []nigoapi.ProcessGroupEntity{
+				versioned("other", "other", "flow"),
+				versioned("pg", "flow", "flow"),
+			},
+			expectedProcessGroup: "pg",
+		},
+		{
+			name:      "ambiguous name",
+			adoptName: "flow",
+			processGroups: []nigoapi.ProcessGroupEntity{
+				versioned("pg", "flow", "flow"),
+				versioned("duplicate", "flow", "flow"),
+			},
+		},
+		{
+			name:          "name not found",
+			adoptName:     "missing",
+			processGroups: []nigoapi.ProcessGroupEntity{versioned("pg", "flow", "flow")},
+		},
+		{
+			name:          "versioned with another flow",
+			adoptName:     "flow",
+			processGroups: []nigoapi.ProcessGroupEntity{versioned("pg", "flow", "other-flow")},
+		},
+		{
+			name:          "not versioned",
+			adoptName:     "unversioned",
+			processGroups: []nigoapi.ProcessGroupEntity{unversioned},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			client := &fakeNifiClient{
+				processGroups: make(map[string]*nigoapi.ProcessGroupEntity),
+				flows:         map[string]nigoapi.FlowDto{"root": {ProcessGroups: test.processGroups}},
+			}
+			for i := range test.processGroups {
+				client.processGroups[test.processGroups[i].Id] = &test.processGroups[i]
+			}
+			newNifiFromConfig := common.NewNifiFromConfig
+			common.NewNifiFromConfig = func(*clientconfig.NifiConfig) (nificlient.NifiClient, error) {
+				return client, nil
+			}
+			defer func() { common.NewNifiFromConfig = newNifiFromConfig }()
+
+			flow := &v1alpha1.NifiDataflow{}
+			flow.Spec.BucketId = "bucket"
+			flow.Spec.FlowId = "flow"
+			flow.Spec.AdoptProcessGroupId = test.adoptId
+			flow.Spec.AdoptProcessGroupName = test.adoptName
+			registry := &v1alpha1.NifiRegistryClient{}
+			registry.Status.Id = "registry"
+
+			status, err := AdoptDataflow(flow, &clientconfig.NifiConfig{RootProcessGroupId: "root"}, registry)
+			if test.expectedProcessGroup == "" {
+				if err == nil {
+					t.Errorf("Expected an error, adopted: %s", status.ProcessGroupID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Expected no error, got:", err)
+			}
+			if status.ProcessGroupID != test.expectedProcessGroup {
+				t.Errorf("Expected process group %s to be adopted, got: %s", test.expectedProcessGroup, status.ProcessGroupID)
+			}
+		})
+	}
+}
+
+func TestGetBulletins(t *testing.T) {
+	bulletin := func(id int64, groupId, level string) nigoapi.BulletinEntity {
+		return nigoapi.BulletinEntity{Id: id, GroupId: groupId,
+			Bulletin: &nigoapi.BulletinDto{Id: id, GroupId: groupId, Level: level}}
+	}
+	client := &fakeNifiClient{
+		flows: map[string]nigoapi.FlowDto{"pg": {}},
+		bulletins: []nigoapi.BulletinEntity{
+			bulletin(5, "pg", "ERROR"),
+			bulletin(6, "pg", "INFO"),
+			bulletin(7, "other", "ERROR"),
+		},
+	}
+	newNifiFromConfig := common.NewNifiFromConfig
+	common.NewNifiFromConfig = func(*clientconfig.NifiConfig) (nificlient.NifiClient, error) {
+		return client, nil
+	}
+	defer func() { common.NewNifiFromConfig = newNifiFromConfig }()
+
+	flow := &v1alpha1.NifiDataflow{}
+	flow.Status.ProcessGroupID = "pg"
+	flow.Status.LastBulletinId = 4
+
+	bulletins, lastBulletinId, err := GetBulletins(flow, &clientconfig.NifiConfig{})
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if len(bulletins) != 1 || bulletins[0].Id != 5 || lastBulletinId != 7 {
+		t.Errorf("Expected the bulletin 5 up to the bulletin 7, got: %+v up to %d", bulletins, lastBulletinId)
+	}
+
+	client.noBulletinBoard = true
+	bulletins, lastBulletinId, err = GetBulletins(flow, &clientconfig.NifiConfig{})
+	if err != nil || bulletins != nil || lastBulletinId != 4 {
+		t.Errorf("Expected no bulletin from an empty bulletin board, got: %+v up to %d, %v", bulletins, lastBulletinId, err)
+	}
+}
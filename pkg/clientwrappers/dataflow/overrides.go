@@ -0,0 +1,240 @@
+package dataflow
+
+import (
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers"
+	"github.com/Orange-OpenSource/nifikop/pkg/common"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+)
+
+// The types of the local differences of a processor configuration which can be overridden.
+const (
+	concurrentTasksChanged    = "Concurrent Tasks Changed"
+	runScheduleChanged        = "Run Schedule Changed"
+	schedulingStrategyChanged = "Scheduling Strategy Changed"
+	executionModeChanged      = "Execution Mode Changed"
+)
+
+// SyncComponentOverrides applies the component overrides of the dataflow on its processors. The running processors
+// are stopped first, as NiFi only updates the configuration of stopped processors, and restarted with the dataflow.
+func SyncComponentOverrides(flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) error {
+	if len(flow.Spec.ComponentOverrides) == 0 {
+		return nil
+	}
+
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return err
+	}
+
+	return applyComponentOverrides(nClient, flow, config)
+}
+
+func applyComponentOverrides(nClient nificlient.NifiClient, flow *v1alpha1.NifiDataflow, config *clientconfig.NifiConfig) error {
+	processGroups, processors, _, _, err := listComponents(config, flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get recursively flow components"); err != nil {
+		return err
+	}
+
+	overrides := resolveComponentOverrides(flow, processGroups, processors)
+	pending := false
+	for _, processor := range processors {
+		override, ok := overrides[processor.Id]
+		if !ok || isOverrideApplied(processor, override) {
+			continue
+		}
+
+		if processor.Status != nil && processor.Status.RunStatus == "Running" {
+			_, err := nClient.UpdateProcessorRunStatus(processor.Id, nigoapi.ProcessorRunStatusEntity{
+				Revision: processor.Revision,
+				State:    "STOPPED",
+			})
+			if err := clientwrappers.ErrorUpdateOperation(log, err, "Stop processor"); err != nil {
+				return err
+			}
+			pending = true
+			continue
+		}
+
+		if processor.Status != nil && processor.Status.AggregateSnapshot != nil &&
+			processor.Status.AggregateSnapshot.ActiveThreadCount > 0 {
+			pending = true
+			continue
+		}
+
+		_, err := nClient.UpdateProcessor(overriddenProcessor(processor, override))
+		if err := clientwrappers.ErrorUpdateOperation(log, err, "Override processor"); err != nil {
+			return err
+		}
+	}
+
+	if pending {
+		return errorfactory.NifiFlowSyncing{}
+	}
+	return nil
+}
+
+// hasLocalChanges returns whether the dataflow has local changes which are not only its component overrides.
+func hasLocalChanges(
+	nClient nificlient.NifiClient,
+	flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig,
+	pGEntity *nigoapi.ProcessGroupEntity) (bool, error) {
+
+	if pGEntity.Component.VersionControlInformation == nil || !localChanged(pGEntity) {
+		return false, nil
+	}
+	if len(flow.Spec.ComponentOverrides) == 0 {
+		return true, nil
+	}
+
+	comparison, err := nClient.GetLocalModifications(pGEntity.Id)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get local modifications"); err != nil {
+		return false, err
+	}
+
+	components, err := filterOverriddenDifferences(flow, config, comparison.ComponentDifferences)
+	if err != nil {
+		return false, err
+	}
+	return len(components) > 0, nil
+}
+
+// filterOverriddenDifferences removes the local differences of the processors only resulting from their overrides.
+func filterOverriddenDifferences(
+	flow *v1alpha1.NifiDataflow,
+	config *clientconfig.NifiConfig,
+	components []nigoapi.ComponentDifferenceDto) ([]nigoapi.ComponentDifferenceDto, error) {
+
+	if len(flow.Spec.ComponentOverrides) == 0 {
+		return components, nil
+	}
+
+	processGroups, processors, _, _, err := listComponents(config, flow.Status.ProcessGroupID)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get recursively flow components"); err != nil {
+		return nil, err
+	}
+
+	overrides := resolveComponentOverrides(flow, processGroups, processors)
+	var filtered []nigoapi.ComponentDifferenceDto
+	for _, component := range components {
+		override, ok := overrides[component.ComponentId]
+		if !ok || component.ComponentType != processorComponentType || !isOverriddenDifference(component, override) {
+			filtered = append(filtered, component)
+		}
+	}
+	return filtered, nil
+}
+
+// isOverriddenDifference returns whether all the differences of the component result from its override.
+func isOverriddenDifference(component nigoapi.ComponentDifferenceDto, override v1alpha1.ComponentOverride) bool {
+	for _, difference := range component.Differences {
+		switch difference.DifferenceType {
+		case concurrentTasksChanged:
+			if override.ConcurrentTasks == nil {
+				return false
+			}
+		case runScheduleChanged:
+			if override.SchedulingPeriod == "" {
+				return false
+			}
+		case schedulingStrategyChanged:
+			if override.SchedulingStrategy == "" {
+				return false
+			}
+		case executionModeChanged:
+			if override.ExecutionNode == "" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// resolveComponentOverrides returns the overrides of the dataflow processors, by processor id.
+func resolveComponentOverrides(
+	flow *v1alpha1.NifiDataflow,
+	processGroups []nigoapi.ProcessGroupEntity,
+	processors []nigoapi.ProcessorEntity) map[string]v1alpha1.ComponentOverride {
+
+	groups := make(map[string]nigoapi.ProcessGroupEntity)
+	for _, pg := range processGroups {
+		groups[pg.Id] = pg
+	}
+
+	overrides := make(map[string]v1alpha1.ComponentOverride)
+	for _, processor := range processors {
+		if processor.Component == nil {
+			continue
+		}
+
+		namePath := componentNamePath(flow.Status.ProcessGroupID, processor.Component.Name,
+			processor.Component.ParentGroupId, groups)
+		for _, override := range flow.Spec.ComponentOverrides {
+			if (override.Id != "" && override.Id == processor.Id) ||
+				(override.Id == "" && override.NamePath == namePath) {
+				overrides[processor.Id] = override
+				break
+			}
+		}
+	}
+	return overrides
+}
+
+// componentNamePath returns the name of the component prefixed by the names of its parent process groups, from the
+// dataflow process group.
+func componentNamePath(
+	rootGroupId, name, parentGroupId string,
+	groups map[string]nigoapi.ProcessGroupEntity) string {
+
+	namePath := name
+	groupId := parentGroupId
+	for groupId != rootGroupId {
+		pg, ok := groups[groupId]
+		if !ok || pg.Component == nil {
+			break
+		}
+		namePath = pg.Component.Name + "/" + namePath
+		groupId = pg.Component.ParentGroupId
+	}
+	return namePath
+}
+
+// isOverrideApplied returns whether the processor configuration matches its override.
+func isOverrideApplied(processor nigoapi.ProcessorEntity, override v1alpha1.ComponentOverride) bool {
+	processorConfig := processor.Component.Config
+	if processorConfig == nil {
+		return false
+	}
+
+	return (override.ConcurrentTasks == nil || *override.ConcurrentTasks == processorConfig.ConcurrentlySchedulableTaskCount) &&
+		(override.SchedulingPeriod == "" || override.SchedulingPeriod == processorConfig.SchedulingPeriod) &&
+		(override.SchedulingStrategy == "" || override.SchedulingStrategy == processorConfig.SchedulingStrategy) &&
+		(override.ExecutionNode == "" || override.ExecutionNode == processorConfig.ExecutionNode)
+}
+
+// overriddenProcessor returns the processor update applying its override.
+func overriddenProcessor(processor nigoapi.ProcessorEntity, override v1alpha1.ComponentOverride) nigoapi.ProcessorEntity {
+	processorConfig := &nigoapi.ProcessorConfigDto{
+		SchedulingPeriod:   override.SchedulingPeriod,
+		SchedulingStrategy: override.SchedulingStrategy,
+		ExecutionNode:      override.ExecutionNode,
+	}
+	if override.ConcurrentTasks != nil {
+		processorConfig.ConcurrentlySchedulableTaskCount = *override.ConcurrentTasks
+	}
+
+	return nigoapi.ProcessorEntity{
+		Id:       processor.Id,
+		Revision: processor.Revision,
+		Component: &nigoapi.ProcessorDto{
+			Id:     processor.Id,
+			Config: processorConfig,
+		},
+	}
+}
@@ -37,7 +37,7 @@ func ExistParameterContext(parameterContext *v1alpha1.NifiParameterContext, conf
 }
 
 func CreateParameterContext(parameterContext *v1alpha1.NifiParameterContext, parameterSecrets []*corev1.Secret,
-	config *clientconfig.NifiConfig) (*v1alpha1.NifiParameterContextStatus, error) {
+	sensitiveSourcesVersion string, config *clientconfig.NifiConfig) (*v1alpha1.NifiParameterContextStatus, error) {
 
 	nClient, err := common.NewClusterConnection(log, config)
 	if err != nil {
@@ -54,11 +54,13 @@ func CreateParameterContext(parameterContext *v1alpha1.NifiParameterContext, par
 
 	parameterContext.Status.Id = entity.Id
 	parameterContext.Status.Version = *entity.Revision.Version
+	parameterContext.Status.SensitiveSourcesVersion = sensitiveSourcesVersion
 
 	return &parameterContext.Status, nil
 }
 
 func SyncParameterContext(parameterContext *v1alpha1.NifiParameterContext, parameterSecrets []*corev1.Secret,
+	inheritedParameterContexts []*v1alpha1.NifiParameterContext, sensitiveSourcesVersion string,
 	config *clientconfig.NifiConfig) (*v1alpha1.NifiParameterContextStatus, error) {
 
 	nClient, err := common.NewClusterConnection(log, config)
@@ -66,7 +68,7 @@ func SyncParameterContext(parameterContext *v1alpha1.NifiParameterContext, param
 		return nil, err
 	}
 
-	entity, err := nClient.GetParameterContext(parameterContext.Status.Id)
+	entity, inheritedIds, err := nClient.GetParameterContextInheritance(parameterContext.Status.Id)
 	if err := clientwrappers.ErrorGetOperation(log, err, "Get parameter-context"); err != nil {
 		return nil, err
 	}
@@ -86,25 +88,42 @@ func SyncParameterContext(parameterContext *v1alpha1.NifiParameterContext, param
 		}
 	}
 
-	if !parameterContextIsSync(parameterContext, parameterSecrets, entity) {
+	expectedInheritedIds := inheritedParameterContextIds(inheritedParameterContexts)
+	inheritanceIsSync := inheritedParameterContextsIsSync(expectedInheritedIds, inheritedIds)
+
+	// NiFi never returns the sensitive values, their changes are detected from the versions of their sources. The
+	// versions are only recorded when they are unknown, as for an existing parameter context, the values in place are
+	// kept.
+	sensitiveIsSync := parameterContext.Status.SensitiveSourcesVersion == "" ||
+		parameterContext.Status.SensitiveSourcesVersion == sensitiveSourcesVersion
 
-		entity.Component.Parameters = updateRequestPrepare(parameterContext, parameterSecrets, entity)
+	if !parameterContextIsSync(parameterContext, parameterSecrets, entity) || !inheritanceIsSync || !sensitiveIsSync {
 
-		updateRequest, err := nClient.CreateParameterContextUpdateRequest(entity.Id, *entity)
+		entity.Component.Parameters = updateRequestPrepare(parameterContext, parameterSecrets, entity, !sensitiveIsSync)
+
+		var updateRequest *nigoapi.ParameterContextUpdateRequestEntity
+		if inheritanceIsSync {
+			updateRequest, err = nClient.CreateParameterContextUpdateRequest(entity.Id, *entity)
+		} else {
+			updateRequest, err = nClient.CreateParameterContextInheritanceUpdateRequest(entity.Id, *entity, expectedInheritedIds)
+		}
 		if err := clientwrappers.ErrorCreateOperation(log, err, "Create parameter-context update-request"); err != nil {
 			return nil, err
 		}
 
 		parameterContext.Status.LatestUpdateRequest =
 			updateRequest2Status(updateRequest)
+		parameterContext.Status.SensitiveSourcesVersion = sensitiveSourcesVersion
 		return &parameterContext.Status, errorfactory.NifiParameterContextUpdateRequestRunning{}
 	}
 
 	var status *v1alpha1.NifiParameterContextStatus
-	if parameterContext.Status.Version != *entity.Revision.Version || parameterContext.Status.Id != entity.Id {
-		status := &parameterContext.Status
+	if parameterContext.Status.Version != *entity.Revision.Version || parameterContext.Status.Id != entity.Id ||
+		parameterContext.Status.SensitiveSourcesVersion != sensitiveSourcesVersion {
+		status = &parameterContext.Status
 		status.Version = *entity.Revision.Version
 		status.Id = entity.Id
+		status.SensitiveSourcesVersion = sensitiveSourcesVersion
 	}
 
 	return status, nil
@@ -170,10 +189,34 @@ func parameterContextIsSync(
 	return e.Component.Description == entity.Component.Description && e.Component.Name == entity.Component.Name
 }
 
+// inheritedParameterContextIds returns the NiFi ids of the inherited parameter contexts, keeping their order.
+func inheritedParameterContextIds(inheritedParameterContexts []*v1alpha1.NifiParameterContext) []string {
+	var ids []string
+	for _, inherited := range inheritedParameterContexts {
+		ids = append(ids, inherited.Status.Id)
+	}
+	return ids
+}
+
+// inheritedParameterContextsIsSync check if the inherited parameter contexts are the same, in the same order.
+func inheritedParameterContextsIsSync(expectedIds, ids []string) bool {
+	if len(expectedIds) != len(ids) {
+		return false
+	}
+
+	for i := range expectedIds {
+		if expectedIds[i] != ids[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func updateRequestPrepare(
 	parameterContext *v1alpha1.NifiParameterContext,
 	parameterSecrets []*corev1.Secret,
-	entity *nigoapi.ParameterContextEntity) []nigoapi.ParameterEntity {
+	entity *nigoapi.ParameterContextEntity,
+	forceSensitive bool) []nigoapi.ParameterEntity {
 
 	tmp := entity.Component.Parameters
 	updateParameterContextEntity(parameterContext, parameterSecrets, entity)
@@ -207,7 +250,8 @@ func updateRequestPrepare(
 							(*expected.Parameter.Value == *param.Parameter.Value)))) ||
 					!((expected.Parameter.Description == nil && param.Parameter.Description == nil) ||
 						((expected.Parameter.Description != nil && param.Parameter.Description != nil) &&
-							(*expected.Parameter.Description == *param.Parameter.Description))) {
+							(*expected.Parameter.Description == *param.Parameter.Description))) ||
+					(forceSensitive && expected.Parameter.Sensitive) {
 
 					notFound = false
 					if expected.Parameter.Value == nil && param.Parameter.Value != nil {
@@ -0,0 +1,354 @@
+package parameterprovider
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers"
+	"github.com/Orange-OpenSource/nifikop/pkg/common"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
+	"github.com/Orange-OpenSource/nifikop/pkg/util"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var log = ctrl.Log.WithName("parameterprovider-method")
+
+const (
+	sensitive    = "SENSITIVE"
+	nonSensitive = "NON_SENSITIVE"
+
+	parameterUnchanged = "UNCHANGED"
+
+	// maskedValue is the value returned by NiFi in place of the sensitive properties.
+	maskedValue = "********"
+)
+
+func ExistParameterProvider(parameterProvider *v1alpha1.NifiParameterProvider, config *clientconfig.NifiConfig) (bool, error) {
+
+	if parameterProvider.Status.Id == "" {
+		return false, nil
+	}
+
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return false, err
+	}
+
+	entity, err := nClient.GetParameterProvider(parameterProvider.Status.Id)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get parameter-provider"); err != nil {
+		if err == nificlient.ErrNifiClusterReturned404 {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return entity != nil, nil
+}
+
+func CreateParameterProvider(parameterProvider *v1alpha1.NifiParameterProvider,
+	config *clientconfig.NifiConfig) (*v1alpha1.NifiParameterProviderStatus, error) {
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, err
+	}
+
+	scratchEntity := nificlient.ParameterProviderEntity{}
+	updateParameterProviderEntity(parameterProvider, &scratchEntity)
+
+	entity, err := nClient.CreateParameterProvider(scratchEntity)
+	if err := clientwrappers.ErrorCreateOperation(log, err, "Create parameter-provider"); err != nil {
+		return nil, err
+	}
+
+	return &v1alpha1.NifiParameterProviderStatus{
+		Id:      entity.Id,
+		Version: *entity.Revision.Version,
+	}, nil
+}
+
+// SyncParameterProvider ensures the configuration of the parameter provider, fetches its parameters and applies
+// them to the parameter contexts mapped on the fetched parameter groups by the given NifiParameterContexts.
+func SyncParameterProvider(parameterProvider *v1alpha1.NifiParameterProvider,
+	parameterContexts []*v1alpha1.NifiParameterContext,
+	config *clientconfig.NifiConfig) (*v1alpha1.NifiParameterProviderStatus, error) {
+
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, err
+	}
+
+	entity, err := nClient.GetParameterProvider(parameterProvider.Status.Id)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get parameter-provider"); err != nil {
+		return nil, err
+	}
+
+	latestApplyRequest := parameterProvider.Status.LatestApplyRequest
+	if latestApplyRequest != nil && !latestApplyRequest.Complete {
+		applyRequest, err := nClient.GetParameterProviderApplyRequest(parameterProvider.Status.Id, latestApplyRequest.Id)
+		if applyRequest != nil {
+			parameterProvider.Status.LatestApplyRequest = applyRequest2Status(applyRequest)
+		}
+
+		if err := clientwrappers.ErrorGetOperation(log, err, "Get apply-parameters-request"); err != nificlient.ErrNifiClusterReturned404 {
+			if err != nil {
+				return &parameterProvider.Status, err
+			}
+			return &parameterProvider.Status, errorfactory.NifiParameterProviderApplyRequestRunning{}
+		}
+	}
+
+	updated := false
+	if !parameterProviderIsSync(parameterProvider, entity) {
+		updateParameterProviderEntity(parameterProvider, entity)
+		entity, err = nClient.UpdateParameterProvider(*entity)
+		if err := clientwrappers.ErrorUpdateOperation(log, err, "Update parameter-provider"); err != nil {
+			return nil, err
+		}
+		updated = true
+	}
+
+	status := parameterProvider.Status
+
+	// Fetching the parameters updates the parameter provider, it's only done when the configuration changes and at
+	// the refresh interval. The parameter groups of the last fetch are kept by NiFi in the meantime.
+	now := time.Now()
+	if updated || isFetchRequired(parameterProvider, now) {
+		entity, err = nClient.FetchParameterProviderParameters(nificlient.ParameterProviderParameterFetchEntity{
+			Id:       entity.Id,
+			Revision: entity.Revision,
+		})
+		if err := clientwrappers.ErrorUpdateOperation(log, err, "Fetch parameter-provider parameters"); err != nil {
+			return nil, err
+		}
+		status.FetchedGeneration = parameterProvider.Generation
+		status.LastFetchTime = now.UTC().Format(time.RFC3339)
+	}
+
+	status.Id = entity.Id
+	status.Version = *entity.Revision.Version
+	status.FetchedGroups = fetchedGroups(entity)
+	status.ParameterContexts = providedParameterContexts(entity)
+
+	configurations, isSync := parameterGroupConfigurations(entity, parameterContexts)
+	if !isSync {
+		applyRequest, err := nClient.CreateParameterProviderApplyRequest(nificlient.ParameterProviderParameterApplicationEntity{
+			Id:                           entity.Id,
+			Revision:                     entity.Revision,
+			ParameterGroupConfigurations: configurations,
+		})
+		if err := clientwrappers.ErrorCreateOperation(log, err, "Create parameter-provider apply-parameters-request"); err != nil {
+			return nil, err
+		}
+
+		status.LatestApplyRequest = applyRequest2Status(applyRequest)
+		return &status, errorfactory.NifiParameterProviderApplyRequestRunning{}
+	}
+
+	return &status, nil
+}
+
+func RemoveParameterProvider(parameterProvider *v1alpha1.NifiParameterProvider,
+	config *clientconfig.NifiConfig) error {
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return err
+	}
+
+	entity, err := nClient.GetParameterProvider(parameterProvider.Status.Id)
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get parameter-provider"); err != nil {
+		if err == nificlient.ErrNifiClusterReturned404 {
+			return nil
+		}
+		return err
+	}
+
+	err = nClient.RemoveParameterProvider(*entity)
+
+	return clientwrappers.ErrorRemoveOperation(log, err, "Remove parameter-provider")
+}
+
+func parameterProviderIsSync(parameterProvider *v1alpha1.NifiParameterProvider, entity *nificlient.ParameterProviderEntity) bool {
+	if parameterProvider.Name != entity.Component.Name ||
+		parameterProvider.Spec.Description != entity.Component.Comments {
+		return false
+	}
+
+	for name, value := range parameterProvider.Spec.Properties {
+		current, ok := entity.Component.Properties[name]
+		if !ok || current == nil {
+			return false
+		}
+		// NiFi never returns the sensitive property values.
+		if *current != value && *current != maskedValue {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isFetchRequired check if the parameters have never been fetched with the current spec, or if the refresh interval
+// has elapsed since the last fetch.
+func isFetchRequired(parameterProvider *v1alpha1.NifiParameterProvider, now time.Time) bool {
+	status := parameterProvider.Status
+	if status.LastFetchTime == "" || status.FetchedGeneration != parameterProvider.Generation {
+		return true
+	}
+	if parameterProvider.Spec.RefreshInterval == nil {
+		return false
+	}
+
+	lastFetchTime, err := time.Parse(time.RFC3339, status.LastFetchTime)
+	if err != nil {
+		return true
+	}
+	return now.Sub(lastFetchTime) >= parameterProvider.Spec.RefreshInterval.Duration
+}
+
+// parameterGroupConfigurations returns the parameter group configurations to apply, mapping each fetched group
+// referenced by a NifiParameterContext on the parameter context of the same name, and whether the fetched
+// parameters are already applied. The groups mapped on no parameter context are not considered.
+func parameterGroupConfigurations(entity *nificlient.ParameterProviderEntity,
+	parameterContexts []*v1alpha1.NifiParameterContext) ([]nificlient.ParameterGroupConfigurationEntity, bool) {
+
+	isSync := true
+	mappedParameters := make(map[string]bool)
+	var configurations []nificlient.ParameterGroupConfigurationEntity
+	for _, fetched := range entity.Component.ParameterGroupConfigurations {
+		configuration := fetched
+		for _, parameterContext := range parameterContexts {
+			if parameterContext.Spec.ParameterProvider.GroupName != fetched.GroupName {
+				continue
+			}
+
+			synchronized := true
+			configuration.ParameterContextName = parameterContext.Name
+			configuration.Synchronized = &synchronized
+			configuration.ParameterSensitivities = make(map[string]string)
+			for name := range fetched.ParameterSensitivities {
+				mappedParameters[name] = true
+				configuration.ParameterSensitivities[name] = nonSensitive
+				if util.StringSliceContains(parameterContext.Spec.ParameterProvider.SensitiveParameters, name) {
+					configuration.ParameterSensitivities[name] = sensitive
+				}
+			}
+
+			if !parameterGroupConfigurationIsSync(configuration, fetched) {
+				isSync = false
+			}
+			break
+		}
+		configurations = append(configurations, configuration)
+	}
+
+	// The status of the parameters is only returned by the fetch.
+	for _, parameterStatus := range entity.Component.ParameterStatus {
+		if parameterStatus.Parameter == nil || parameterStatus.Parameter.Parameter == nil ||
+			!mappedParameters[parameterStatus.Parameter.Parameter.Name] {
+			continue
+		}
+		if parameterStatus.Status != parameterUnchanged {
+			isSync = false
+		}
+	}
+
+	return configurations, isSync
+}
+
+func parameterGroupConfigurationIsSync(expected, fetched nificlient.ParameterGroupConfigurationEntity) bool {
+	if expected.ParameterContextName != fetched.ParameterContextName ||
+		fetched.Synchronized == nil || !*fetched.Synchronized {
+		return false
+	}
+
+	for name, sensitivity := range expected.ParameterSensitivities {
+		if fetched.ParameterSensitivities[name] != sensitivity {
+			return false
+		}
+	}
+	return true
+}
+
+func fetchedGroups(entity *nificlient.ParameterProviderEntity) []string {
+	var groups []string
+	for _, configuration := range entity.Component.ParameterGroupConfigurations {
+		groups = append(groups, configuration.GroupName)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+func providedParameterContexts(entity *nificlient.ParameterProviderEntity) []v1alpha1.ProvidedParameterContext {
+	var parameterContexts []v1alpha1.ProvidedParameterContext
+	for _, reference := range entity.Component.ReferencingParameterContexts {
+		if reference.Component == nil {
+			continue
+		}
+		parameterContexts = append(parameterContexts, v1alpha1.ProvidedParameterContext{
+			Name: reference.Component.Name,
+			Id:   reference.Id,
+		})
+	}
+	return parameterContexts
+}
+
+func updateParameterProviderEntity(parameterProvider *v1alpha1.NifiParameterProvider, entity *nificlient.ParameterProviderEntity) {
+
+	var defaultVersion int64 = 0
+
+	if entity == nil {
+		entity = &nificlient.ParameterProviderEntity{}
+	}
+
+	if entity.Component == nil {
+		entity.Revision = &nigoapi.RevisionDto{
+			Version: &defaultVersion,
+		}
+	}
+
+	if entity.Component == nil {
+		entity.Component = &nificlient.ParameterProviderDto{
+			Type_: parameterProvider.Spec.Type,
+		}
+		if bundle := parameterProvider.Spec.Bundle; bundle != nil {
+			entity.Component.Bundle = &nigoapi.BundleDto{
+				Group:    bundle.Group,
+				Artifact: bundle.Artifact,
+				Version:  bundle.Version,
+			}
+		}
+	}
+
+	entity.Component.Name = parameterProvider.Name
+	entity.Component.Comments = parameterProvider.Spec.Description
+
+	properties := make(map[string]*string)
+	for name, value := range parameterProvider.Spec.Properties {
+		value := value
+		properties[name] = &value
+	}
+	entity.Component.Properties = properties
+
+	// Only the configuration is updated, the fetched parameters are applied through the apply requests.
+	entity.Component.ParameterGroupConfigurations = nil
+	entity.Component.ParameterStatus = nil
+	entity.Component.ReferencingParameterContexts = nil
+}
+
+func applyRequest2Status(applyRequest *nificlient.ParameterProviderApplyParametersRequestEntity) *v1alpha1.ParameterProviderApplyRequest {
+	ar := applyRequest.Request
+	return &v1alpha1.ParameterProviderApplyRequest{
+		Id:               ar.RequestId,
+		Uri:              ar.Uri,
+		SubmissionTime:   ar.SubmissionTime,
+		LastUpdated:      ar.LastUpdated,
+		Complete:         ar.Complete,
+		FailureReason:    ar.FailureReason,
+		PercentCompleted: ar.PercentCompleted,
+		State:            ar.State,
+	}
+}
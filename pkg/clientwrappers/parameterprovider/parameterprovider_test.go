@@ -0,0 +1,119 @@
+package parameterprovider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsFetchRequired(t *testing.T) {
+	now := time.Now()
+	recentFetch := now.Add(-time.Minute).UTC().Format(time.RFC3339)
+	elapsedFetch := now.Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	testCases := []struct {
+		name              string
+		generation        int64
+		refreshInterval   *metav1.Duration
+		lastFetchTime     string
+		fetchedGeneration int64
+		expected          bool
+	}{
+		{name: "never fetched", generation: 1, expected: true},
+		{name: "spec changed", generation: 2, fetchedGeneration: 1, lastFetchTime: recentFetch, expected: true},
+		{name: "fetched without refresh interval", generation: 1, fetchedGeneration: 1, lastFetchTime: elapsedFetch},
+		{
+			name:              "refresh interval not elapsed",
+			generation:        1,
+			fetchedGeneration: 1,
+			refreshInterval:   &metav1.Duration{Duration: 10 * time.Minute},
+			lastFetchTime:     recentFetch,
+		},
+		{
+			name:              "refresh interval elapsed",
+			generation:        1,
+			fetchedGeneration: 1,
+			refreshInterval:   &metav1.Duration{Duration: 10 * time.Minute},
+			lastFetchTime:     elapsedFetch,
+			expected:          true,
+		},
+		{
+			name:              "invalid last fetch time",
+			generation:        1,
+			fetchedGeneration: 1,
+			refreshInterval:   &metav1.Duration{Duration: 10 * time.Minute},
+			lastFetchTime:     "10/03/2021 12:00",
+			expected:          true,
+		},
+	}
+
+	for _, test := range testCases {
+		parameterProvider := &v1alpha1.NifiParameterProvider{ObjectMeta: metav1.ObjectMeta{Generation: test.generation}}
+		parameterProvider.Spec.RefreshInterval = test.refreshInterval
+		parameterProvider.Status.LastFetchTime = test.lastFetchTime
+		parameterProvider.Status.FetchedGeneration = test.fetchedGeneration
+
+		if fetch := isFetchRequired(parameterProvider, now); fetch != test.expected {
+			t.Errorf("%s: expected fetch required %t, got: %t", test.name, test.expected, fetch)
+		}
+	}
+}
+
+func TestParameterGroupConfigurations(t *testing.T) {
+	synchronized := true
+	parameterStatus := func(name, status string) nificlient.ParameterStatusDto {
+		return nificlient.ParameterStatusDto{
+			Parameter: &nigoapi.ParameterEntity{Parameter: &nigoapi.ParameterDto{Name: name}},
+			Status:    status,
+		}
+	}
+	entity := &nificlient.ParameterProviderEntity{Component: &nificlient.ParameterProviderDto{
+		ParameterGroupConfigurations: []nificlient.ParameterGroupConfigurationEntity{
+			{
+				GroupName:              "mapped",
+				ParameterContextName:   "context",
+				Synchronized:           &synchronized,
+				ParameterSensitivities: map[string]string{"password": sensitive, "url": nonSensitive},
+			},
+			{GroupName: "unmapped", ParameterSensitivities: map[string]string{"other": nonSensitive}},
+		},
+	}}
+	parameterContext := &v1alpha1.NifiParameterContext{ObjectMeta: metav1.ObjectMeta{Name: "context"}}
+	parameterContext.Spec.ParameterProvider = &v1alpha1.ParameterProviderSource{
+		GroupName:           "mapped",
+		SensitiveParameters: []string{"password"},
+	}
+	parameterContexts := []*v1alpha1.NifiParameterContext{parameterContext}
+
+	configurations, isSync := parameterGroupConfigurations(entity, parameterContexts)
+	if !isSync {
+		t.Error("Expected the applied configurations to be in sync")
+	}
+	if len(configurations) != 2 || configurations[0].ParameterContextName != "context" ||
+		configurations[1].ParameterContextName != "" {
+		t.Errorf("Expected the mapped group only to be configured, got: %+v", configurations)
+	}
+
+	entity.Component.ParameterStatus = []nificlient.ParameterStatusDto{
+		parameterStatus("password", parameterUnchanged),
+		parameterStatus("other", "NEW"),
+	}
+	if _, isSync := parameterGroupConfigurations(entity, parameterContexts); !isSync {
+		t.Error("Expected the changes of the unmapped groups to be ignored")
+	}
+
+	entity.Component.ParameterStatus = append(entity.Component.ParameterStatus, parameterStatus("url", "CHANGED"))
+	if _, isSync := parameterGroupConfigurations(entity, parameterContexts); isSync {
+		t.Error("Expected the changes of the mapped groups to be applied")
+	}
+
+	entity.Component.ParameterStatus = nil
+	parameterContext.Spec.ParameterProvider.SensitiveParameters = nil
+	if _, isSync := parameterGroupConfigurations(entity, parameterContexts); isSync {
+		t.Error("Expected the sensitivity changes to be applied")
+	}
+}
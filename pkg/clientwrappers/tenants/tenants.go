@@ -0,0 +1,134 @@
+package tenants
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers"
+	"github.com/Orange-OpenSource/nifikop/pkg/common"
+	"github.com/Orange-OpenSource/nifikop/pkg/util"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	pkicommon "github.com/Orange-OpenSource/nifikop/pkg/util/pki"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var log = ctrl.Log.WithName("tenants-method")
+
+// PruneTenants lists the users and user groups of the NiFi cluster whose identity is neither managed, protected nor
+// allowlisted, and removes them when the tenant pruning is enforced. It returns the unmanaged tenants left on the NiFi
+// cluster, with the access policies granted to them. The access policies themselves are never removed, as they may be
+// shared with managed tenants: NiFi only revokes the bindings of the removed tenants.
+func PruneTenants(
+	cluster *v1alpha1.NifiCluster,
+	managedIdentities []string,
+	config *clientconfig.NifiConfig) (*v1alpha1.UnmanagedTenantsStatus, error) {
+
+	nClient, err := common.NewClusterConnection(log, config)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[string]bool)
+	for _, identity := range managedIdentities {
+		expected[identity] = true
+	}
+	for _, identity := range ProtectedIdentities(cluster) {
+		expected[identity] = true
+	}
+	for _, identity := range cluster.Spec.TenantPruning.Allowlist {
+		expected[identity] = true
+	}
+	enforce := cluster.Spec.TenantPruning.GetMode() == v1alpha1.TenantPruningEnforce
+
+	status := &v1alpha1.UnmanagedTenantsStatus{}
+	policies := make(map[string]bool)
+
+	groupEntities, err := nClient.GetUserGroups()
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get user groups"); err != nil {
+		return nil, err
+	}
+
+	for _, entity := range groupEntities {
+		if entity.Component == nil || !entity.Component.Configurable || expected[entity.Component.Identity] {
+			continue
+		}
+
+		if enforce {
+			err := nClient.RemoveUserGroup(entity)
+			if err := clientwrappers.ErrorRemoveOperation(log, err, "Remove unmanaged user group"); err != nil {
+				return nil, err
+			}
+			log.Info("Removed unmanaged user group", "identity", entity.Component.Identity)
+			continue
+		}
+
+		status.UserGroups = append(status.UserGroups, entity.Component.Identity)
+		for _, policy := range entity.Component.AccessPolicies {
+			if policy.Component != nil {
+				policies[fmt.Sprintf("%s %s", policy.Component.Action, policy.Component.Resource)] = true
+			}
+		}
+	}
+
+	userEntities, err := nClient.GetUsers()
+	if err := clientwrappers.ErrorGetOperation(log, err, "Get users"); err != nil {
+		return nil, err
+	}
+
+	for _, entity := range userEntities {
+		if entity.Component == nil || !entity.Component.Configurable || expected[entity.Component.Identity] {
+			continue
+		}
+
+		if enforce {
+			err := nClient.RemoveUser(entity)
+			if err := clientwrappers.ErrorRemoveOperation(log, err, "Remove unmanaged user"); err != nil {
+				return nil, err
+			}
+			log.Info("Removed unmanaged user", "identity", entity.Component.Identity)
+			continue
+		}
+
+		status.Users = append(status.Users, entity.Component.Identity)
+		for _, policy := range entity.Component.AccessPolicies {
+			if policy.Component != nil {
+				policies[fmt.Sprintf("%s %s", policy.Component.Action, policy.Component.Resource)] = true
+			}
+		}
+	}
+
+	if len(status.Users) == 0 && len(status.UserGroups) == 0 {
+		return nil, nil
+	}
+
+	for policy := range policies {
+		status.AccessPolicies = append(status.AccessPolicies, policy)
+	}
+	sort.Strings(status.Users)
+	sort.Strings(status.UserGroups)
+	sort.Strings(status.AccessPolicies)
+
+	return status, nil
+}
+
+// ProtectedIdentities returns the identities the operator relies on to manage the NiFi cluster, which are never
+// reported nor removed even when their NifiUser is missing: the controller user, which is the initial admin of the
+// cluster, and the nodes, including the ones removed from the spec but still known by the status.
+func ProtectedIdentities(cluster *v1alpha1.NifiCluster) []string {
+	identities := []string{pkicommon.ControllerUserForCluster(cluster).GetIdentity()}
+
+	nodeIds := make(map[int32]bool)
+	for _, node := range cluster.Spec.Nodes {
+		nodeIds[node.Id] = true
+	}
+	for nodeId := range cluster.Status.NodesState {
+		nodeIds[util.ConvertStringToInt32(nodeId)] = true
+	}
+	for nodeId := range nodeIds {
+		identities = append(identities, pkicommon.GetNodeUserName(cluster, nodeId))
+	}
+
+	sort.Strings(identities[1:])
+	return identities
+}
@@ -0,0 +1,172 @@
+package tenants
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/common"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeNifiClient struct {
+	nificlient.NifiClient
+
+	users         []nigoapi.UserEntity
+	userGroups    []nigoapi.UserGroupEntity
+	removedUsers  []string
+	removedGroups []string
+}
+
+func (c *fakeNifiClient) GetUsers() ([]nigoapi.UserEntity, error) {
+	return c.users, nil
+}
+
+func (c *fakeNifiClient) GetUserGroups() ([]nigoapi.UserGroupEntity, error) {
+	return c.userGroups, nil
+}
+
+func (c *fakeNifiClient) RemoveUser(entity nigoapi.UserEntity) error {
+	c.removedUsers = append(c.removedUsers, entity.Component.Identity)
+	return nil
+}
+
+func (c *fakeNifiClient) RemoveUserGroup(entity nigoapi.UserGroupEntity) error {
+	c.removedGroups = append(c.removedGroups, entity.Component.Identity)
+	return nil
+}
+
+func accessPolicy(action, resource string) nigoapi.AccessPolicySummaryEntity {
+	return nigoapi.AccessPolicySummaryEntity{
+		Component: &nigoapi.AccessPolicySummaryDto{Action: action, Resource: resource},
+	}
+}
+
+func groupAccessPolicy(action, resource string) nigoapi.AccessPolicyEntity {
+	return nigoapi.AccessPolicyEntity{
+		Component: &nigoapi.AccessPolicyDto{Action: action, Resource: resource},
+	}
+}
+
+func user(identity string, configurable bool, accessPolicies ...nigoapi.AccessPolicySummaryEntity) nigoapi.UserEntity {
+	return nigoapi.UserEntity{Component: &nigoapi.UserDto{
+		Identity: identity, Configurable: configurable, AccessPolicies: accessPolicies}}
+}
+
+func userGroup(identity string, accessPolicies ...nigoapi.AccessPolicyEntity) nigoapi.UserGroupEntity {
+	return nigoapi.UserGroupEntity{Component: &nigoapi.UserGroupDto{
+		Identity: identity, Configurable: true, AccessPolicies: accessPolicies}}
+}
+
+func testCluster(mode v1alpha1.TenantPruningMode) *v1alpha1.NifiCluster {
+	cluster := &v1alpha1.NifiCluster{ObjectMeta: metav1.ObjectMeta{Name: "nifi", Namespace: "default"}}
+	cluster.Spec.ListenersConfig = &v1alpha1.ListenersConfig{}
+	cluster.Spec.Nodes = []v1alpha1.Node{{Id: 1}}
+	cluster.Spec.TenantPruning = v1alpha1.TenantPruningSpec{Mode: mode, Allowlist: []string{"break-glass"}}
+	return cluster
+}
+
+func TestProtectedIdentities(t *testing.T) {
+	cluster := testCluster(v1alpha1.TenantPruningEnforce)
+	cluster.Status.NodesState = map[string]v1alpha1.NodeState{"1": {}, "2": {}}
+
+	expected := []string{
+		"nifi-controller.default.mgt.cluster.local",
+		"nifi-1-node.nifi-headless.default.svc.cluster.local",
+		"nifi-2-node.nifi-headless.default.svc.cluster.local",
+	}
+	cluster.Spec.Service.HeadlessEnabled = true
+	if identities := ProtectedIdentities(cluster); !reflect.DeepEqual(identities, expected) {
+		t.Errorf("Expected protected identities %v, got: %v", expected, identities)
+	}
+}
+
+func TestPruneTenants(t *testing.T) {
+	cluster := testCluster(v1alpha1.TenantPruningReport)
+	protected := ProtectedIdentities(cluster)
+
+	testCases := []struct {
+		name                  string
+		mode                  v1alpha1.TenantPruningMode
+		expected              *v1alpha1.UnmanagedTenantsStatus
+		expectedRemovedUsers  []string
+		expectedRemovedGroups []string
+	}{
+		{
+			name: "report",
+			mode: v1alpha1.TenantPruningReport,
+			expected: &v1alpha1.UnmanagedTenantsStatus{
+				Users:          []string{"manual-user"},
+				UserGroups:     []string{"manual-group"},
+				AccessPolicies: []string{"read /flow", "write /tenants"},
+			},
+		},
+		{
+			name:                  "enforce",
+			mode:                  v1alpha1.TenantPruningEnforce,
+			expectedRemovedUsers:  []string{"manual-user"},
+			expectedRemovedGroups: []string{"manual-group"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			client := &fakeNifiClient{
+				users: []nigoapi.UserEntity{
+					user("managed-user", true, accessPolicy("read", "/flow")),
+					user(protected[0], true, accessPolicy("write", "/controller")),
+					user(protected[1], true, accessPolicy("write", "/proxy")),
+					user("break-glass", true, accessPolicy("write", "/policies")),
+					user("ldap-user", false, accessPolicy("write", "/controller")),
+					user("manual-user", true, accessPolicy("read", "/flow")),
+				},
+				userGroups: []nigoapi.UserGroupEntity{
+					userGroup("managed-group", groupAccessPolicy("read", "/flow")),
+					userGroup("manual-group", groupAccessPolicy("write", "/tenants"), nigoapi.AccessPolicyEntity{}),
+				},
+			}
+			newNifiFromConfig := common.NewNifiFromConfig
+			common.NewNifiFromConfig = func(*clientconfig.NifiConfig) (nificlient.NifiClient, error) {
+				return client, nil
+			}
+			defer func() { common.NewNifiFromConfig = newNifiFromConfig }()
+
+			cluster.Spec.TenantPruning.Mode = test.mode
+			status, err := PruneTenants(cluster, []string{"managed-user", "managed-group"}, &clientconfig.NifiConfig{})
+			if err != nil {
+				t.Fatal("Expected no error, got:", err)
+			}
+			if !reflect.DeepEqual(status, test.expected) {
+				t.Errorf("Expected unmanaged tenants %+v, got: %+v", test.expected, status)
+			}
+			if !reflect.DeepEqual(client.removedUsers, test.expectedRemovedUsers) {
+				t.Errorf("Expected removed users %v, got: %v", test.expectedRemovedUsers, client.removedUsers)
+			}
+			if !reflect.DeepEqual(client.removedGroups, test.expectedRemovedGroups) {
+				t.Errorf("Expected removed user groups %v, got: %v", test.expectedRemovedGroups, client.removedGroups)
+			}
+		})
+	}
+}
+
+func TestPruneTenantsWithoutUnmanagedTenants(t *testing.T) {
+	client := &fakeNifiClient{
+		users: []nigoapi.UserEntity{user("managed-user", true), user("break-glass", true)},
+	}
+	newNifiFromConfig := common.NewNifiFromConfig
+	common.NewNifiFromConfig = func(*clientconfig.NifiConfig) (nificlient.NifiClient, error) {
+		return client, nil
+	}
+	defer func() { common.NewNifiFromConfig = newNifiFromConfig }()
+
+	status, err := PruneTenants(testCluster(v1alpha1.TenantPruningReport), []string{"managed-user"}, &clientconfig.NifiConfig{})
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if status != nil {
+		t.Errorf("Expected no unmanaged tenants, got: %+v", status)
+	}
+}
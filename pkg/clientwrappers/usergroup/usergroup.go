@@ -6,6 +6,7 @@ import (
 	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/accesspolicies"
 	"github.com/Orange-OpenSource/nifikop/pkg/common"
 	"github.com/Orange-OpenSource/nifikop/pkg/nificlient"
+	"github.com/Orange-OpenSource/nifikop/pkg/util"
 	"github.com/Orange-OpenSource/nifikop/pkg/util/clientconfig"
 	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -38,14 +39,14 @@ func ExistUserGroup(userGroup *v1alpha1.NifiUserGroup, config *clientconfig.Nifi
 }
 
 func CreateUserGroup(userGroup *v1alpha1.NifiUserGroup,
-	users []*v1alpha1.NifiUser, config *clientconfig.NifiConfig) (*v1alpha1.NifiUserGroupStatus, error) {
+	users, selectedUsers []*v1alpha1.NifiUser, config *clientconfig.NifiConfig) (*v1alpha1.NifiUserGroupStatus, error) {
 	nClient, err := common.NewClusterConnection(log, config)
 	if err != nil {
 		return nil, err
 	}
 
 	scratchEntity := nigoapi.UserGroupEntity{}
-	updateUserGroupEntity(userGroup, users, &scratchEntity)
+	updateUserGroupEntity(userGroup, users, []string{}, &scratchEntity)
 
 	entity, err := nClient.CreateUserGroup(scratchEntity)
 	if err := clientwrappers.ErrorCreateOperation(log, err, "Create user-group"); err != nil {
@@ -53,12 +54,15 @@ func CreateUserGroup(userGroup *v1alpha1.NifiUserGroup,
 	}
 
 	return &v1alpha1.NifiUserGroupStatus{
-		Id:      entity.Id,
-		Version: *entity.Revision.Version,
+		Id:            entity.Id,
+		Version:       *entity.Revision.Version,
+		SelectedUsers: userIds(selectedUsers),
 	}, nil
 }
 
-func SyncUserGroup(userGroup *v1alpha1.NifiUserGroup, users []*v1alpha1.NifiUser,
+// SyncUserGroup adds the given users to the user group, and removes the ones previously added by the users selector
+// which are no more part of it. The other members are only removed when pruneUsers is set.
+func SyncUserGroup(userGroup *v1alpha1.NifiUserGroup, users, selectedUsers []*v1alpha1.NifiUser, pruneUsers bool,
 	config *clientconfig.NifiConfig) (*v1alpha1.NifiUserGroupStatus, error) {
 
 	nClient, err := common.NewClusterConnection(log, config)
@@ -90,8 +94,9 @@ func SyncUserGroup(userGroup *v1alpha1.NifiUserGroup, users []*v1alpha1.NifiUser
 		}
 	}
 
-	if !userGroupIsSync(userGroup, users, entity) {
-		updateUserGroupEntity(userGroup, users, entity)
+	removedUsers := usersToRemove(userGroup, users, pruneUsers, entity)
+	if !userGroupIsSync(userGroup, users, removedUsers, entity) {
+		updateUserGroupEntity(userGroup, users, removedUsers, entity)
 		entity, err = nClient.UpdateUserGroup(*entity)
 		if err := clientwrappers.ErrorUpdateOperation(log, err, "Update user-group"); err != nil {
 			return nil, err
@@ -101,6 +106,7 @@ func SyncUserGroup(userGroup *v1alpha1.NifiUserGroup, users []*v1alpha1.NifiUser
 	status := userGroup.Status
 	status.Version = *entity.Revision.Version
 	status.Id = entity.Id
+	status.SelectedUsers = userIds(selectedUsers)
 
 	// Remove from access policy
 	for _, entity := range entity.Component.AccessPolicies {
@@ -143,15 +149,36 @@ func RemoveUserGroup(userGroup *v1alpha1.NifiUserGroup, users []*v1alpha1.NifiUs
 		return err
 	}
 
-	updateUserGroupEntity(userGroup, users, entity)
+	updateUserGroupEntity(userGroup, users, usersToRemove(userGroup, users, true, entity), entity)
 	err = nClient.RemoveUserGroup(*entity)
 
 	return clientwrappers.ErrorRemoveOperation(log, err, "Remove user-group")
 }
 
+// usersToRemove returns the ids of the members to remove from the user group: the ones previously added by the users
+// selector which are no more part of the group and, when pruneUsers is set, all the other ones.
+func usersToRemove(
+	userGroup *v1alpha1.NifiUserGroup,
+	users []*v1alpha1.NifiUser,
+	pruneUsers bool,
+	entity *nigoapi.UserGroupEntity) []string {
+
+	removedUsers := []string{}
+	for _, tenant := range entity.Component.Users {
+		if containsUserId(users, tenant.Id) {
+			continue
+		}
+		if pruneUsers || util.StringSliceContains(userGroup.Status.SelectedUsers, tenant.Id) {
+			removedUsers = append(removedUsers, tenant.Id)
+		}
+	}
+	return removedUsers
+}
+
 func userGroupIsSync(
 	userGroup *v1alpha1.NifiUserGroup,
 	users []*v1alpha1.NifiUser,
+	removedUsers []string,
 	entity *nigoapi.UserGroupEntity) bool {
 
 	if userGroup.GetIdentity() != entity.Component.Identity {
@@ -159,21 +186,24 @@ func userGroupIsSync(
 	}
 
 	for _, expected := range users {
-		notFound := true
-		for _, tenant := range entity.Component.Users {
-			if expected.Status.Id == tenant.Id {
-				notFound = false
-				break
-			}
+		if !entityContainsUser(entity, expected) {
+			return false
 		}
-		if notFound {
+	}
+
+	for _, tenant := range entity.Component.Users {
+		if util.StringSliceContains(removedUsers, tenant.Id) {
 			return false
 		}
 	}
 	return true
 }
 
-func updateUserGroupEntity(userGroup *v1alpha1.NifiUserGroup, users []*v1alpha1.NifiUser, entity *nigoapi.UserGroupEntity) {
+func updateUserGroupEntity(
+	userGroup *v1alpha1.NifiUserGroup,
+	users []*v1alpha1.NifiUser,
+	removedUsers []string,
+	entity *nigoapi.UserGroupEntity) {
 
 	var defaultVersion int64 = 0
 
@@ -193,9 +223,45 @@ func updateUserGroupEntity(userGroup *v1alpha1.NifiUserGroup, users []*v1alpha1.
 
 	entity.Component.Identity = userGroup.GetIdentity()
 
+	// The users added out of the operator are kept, unless they have to be removed.
+	tenants := []nigoapi.TenantEntity{}
+	for _, tenant := range entity.Component.Users {
+		if !util.StringSliceContains(removedUsers, tenant.Id) {
+			tenants = append(tenants, tenant)
+		}
+	}
+	entity.Component.Users = tenants
+	for _, user := range users {
+		if !entityContainsUser(entity, user) {
+			entity.Component.Users = append(entity.Component.Users, nigoapi.TenantEntity{Id: user.Status.Id})
+		}
+	}
+}
+
+func entityContainsUser(entity *nigoapi.UserGroupEntity, user *v1alpha1.NifiUser) bool {
+	for _, tenant := range entity.Component.Users {
+		if tenant.Id == user.Status.Id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsUserId(users []*v1alpha1.NifiUser, id string) bool {
+	for _, user := range users {
+		if user.Status.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+func userIds(users []*v1alpha1.NifiUser) []string {
+	var ids []string
 	for _, user := range users {
-		entity.Component.Users = append(entity.Component.Users, nigoapi.TenantEntity{Id: user.Status.Id})
+		ids = append(ids, user.Status.Id)
 	}
+	return ids
 }
 
 func userGroupContainsAccessPolicy(userGroup *v1alpha1.NifiUserGroup, entity nigoapi.AccessPolicyEntity, rootPGId string) bool {
@@ -0,0 +1,102 @@
+package usergroup
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testUser(id string) *v1alpha1.NifiUser {
+	return &v1alpha1.NifiUser{Status: v1alpha1.NifiUserStatus{Id: id}}
+}
+
+func testEntity(userIds ...string) *nigoapi.UserGroupEntity {
+	entity := &nigoapi.UserGroupEntity{Component: &nigoapi.UserGroupDto{Identity: "default-group"}}
+	for _, id := range userIds {
+		entity.Component.Users = append(entity.Component.Users, nigoapi.TenantEntity{Id: id})
+	}
+	return entity
+}
+
+func TestUsersToRemove(t *testing.T) {
+	userGroup := &v1alpha1.NifiUserGroup{ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: "default"}}
+	userGroup.Status.SelectedUsers = []string{"b", "c"}
+	users := []*v1alpha1.NifiUser{testUser("a"), testUser("b")}
+
+	testCases := []struct {
+		name       string
+		entity     *nigoapi.UserGroupEntity
+		pruneUsers bool
+		expected   []string
+	}{
+		{"in sync", testEntity("a", "b"), false, []string{}},
+		{"no more selected user", testEntity("a", "b", "c"), false, []string{"c"}},
+		{"manually added user kept", testEntity("a", "b", "d"), false, []string{}},
+		{"manually added user pruned", testEntity("a", "b", "c", "d"), true, []string{"c", "d"}},
+	}
+
+	for _, test := range testCases {
+		if removed := usersToRemove(userGroup, users, test.pruneUsers, test.entity); !reflect.DeepEqual(removed, test.expected) {
+			t.Errorf("%s: expected %v, got: %v", test.name, test.expected, removed)
+		}
+	}
+}
+
+func TestUserGroupIsSync(t *testing.T) {
+	userGroup := &v1alpha1.NifiUserGroup{ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: "default"}}
+	users := []*v1alpha1.NifiUser{testUser("a"), testUser("b")}
+
+	testCases := []struct {
+		name         string
+		entity       *nigoapi.UserGroupEntity
+		removedUsers []string
+		expected     bool
+	}{
+		{"in sync", testEntity("a", "b"), []string{}, true},
+		{"missing user", testEntity("a"), []string{}, false},
+		{"additional user kept", testEntity("a", "b", "c"), []string{}, true},
+		{"additional user removed", testEntity("a", "b", "c"), []string{"c"}, false},
+		{"in sync with removed users", testEntity("b", "a"), []string{"c"}, true},
+	}
+
+	for _, test := range testCases {
+		if sync := userGroupIsSync(userGroup, users, test.removedUsers, test.entity); sync != test.expected {
+			t.Errorf("%s: expected %v, got: %v", test.name, test.expected, sync)
+		}
+	}
+
+	entity := testEntity("a", "b")
+	entity.Component.Identity = "renamed"
+	if userGroupIsSync(userGroup, users, []string{}, entity) {
+		t.Error("Expected a user group with another identity not to be in sync")
+	}
+}
+
+func TestUpdateUserGroupEntity(t *testing.T) {
+	userGroup := &v1alpha1.NifiUserGroup{ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: "default"}}
+	users := []*v1alpha1.NifiUser{testUser("a"), testUser("b")}
+
+	entity := testEntity("c", "a")
+	updateUserGroupEntity(userGroup, users, []string{}, entity)
+	expected := []nigoapi.TenantEntity{{Id: "c"}, {Id: "a"}, {Id: "b"}}
+	if !reflect.DeepEqual(entity.Component.Users, expected) {
+		t.Errorf("Expected users %v, got: %v", expected, entity.Component.Users)
+	}
+
+	entity = testEntity("c", "a")
+	updateUserGroupEntity(userGroup, users, []string{"c"}, entity)
+	expected = []nigoapi.TenantEntity{{Id: "a"}, {Id: "b"}}
+	if !reflect.DeepEqual(entity.Component.Users, expected) {
+		t.Errorf("Expected removed users %v, got: %v", expected, entity.Component.Users)
+	}
+
+	entity = &nigoapi.UserGroupEntity{}
+	updateUserGroupEntity(userGroup, users, []string{}, entity)
+	if entity.Component.Identity != "default-group" || *entity.Revision.Version != 0 ||
+		!reflect.DeepEqual(entity.Component.Users, expected) {
+		t.Errorf("Expected a new user group entity, got: %+v", entity.Component)
+	}
+}
@@ -52,6 +52,9 @@ type FatalReconcileError struct{ error }
 // ReconcileRollingUpgrade states that rolling upgrade is reconciling
 type ReconcileRollingUpgrade struct{ error }
 
+// NodeConfigurationRejected states that the desired configuration of some nodes can't be applied
+type NodeConfigurationRejected struct{ error }
+
 // NilClientConfig states that the client config is nil
 type NilClientConfig struct{ error }
 
@@ -73,9 +76,18 @@ type NifiConnectionDropping struct{ error }
 // NifiFlowDraining states that flowfile drop is still draining
 type NifiFlowDraining struct{ error }
 
+// NifiFlowDrainAborted states that the flow update has been aborted as the flow was not drained within the drain timeout
+type NifiFlowDrainAborted struct{ error }
+
+// NifiFlowRolledBack states that the blue/green flow update has been rolled back
+type NifiFlowRolledBack struct{ error }
+
 // NifiParameterContextUpdateRequestRunning states that the parameter context update request is still running
 type NifiParameterContextUpdateRequestRunning struct{ error }
 
+// NifiParameterProviderApplyRequestRunning states that the parameter provider apply parameters request is still running
+type NifiParameterProviderApplyRequestRunning struct{ error }
+
 // NifiFlowUpdateRequestRunning states that the flow update request is still running
 type NifiFlowUpdateRequestRunning struct{ error }
 
@@ -85,6 +97,9 @@ type NifiFlowControllerServiceScheduling struct{ error }
 // NifiFlowSyncing states that the flow's controller service are still scheduling
 type NifiFlowSyncing struct{ error }
 
+// NifiFlowLocalChangesCommitted states that the flow's local changes have been committed as a new flow version
+type NifiFlowLocalChangesCommitted struct{ error }
+
 // NifiFlowScheduling states that the flow is still scheduling
 type NifiFlowScheduling struct{ error }
 
@@ -122,6 +137,8 @@ func New(t interface{}, err error, msg string, wrapArgs ...interface{}) error {
 		return FatalReconcileError{wrapped}
 	case ReconcileRollingUpgrade:
 		return ReconcileRollingUpgrade{wrapped}
+	case NodeConfigurationRejected:
+		return NodeConfigurationRejected{wrapped}
 	}
 	return wrapped
 }
@@ -34,6 +34,7 @@ var errorTypes = []error{
 	TooManyResources{},
 	InternalError{},
 	FatalReconcileError{},
+	NodeConfigurationRejected{},
 	NifiClusterNotReady{},
 	NifiClusterTaskRunning{},
 }
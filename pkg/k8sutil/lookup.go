@@ -51,9 +51,37 @@ func LookupSecret(client runtimeClient.Client, secretName, secretNamespace strin
 	return
 }
 
+// LookupConfigMap returns the config map instance based on its name and namespace
+func LookupConfigMap(client runtimeClient.Client, configMapName, configMapNamespace string) (configMap *corev1.ConfigMap, err error) {
+	configMap = &corev1.ConfigMap{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: configMapName, Namespace: configMapNamespace}, configMap)
+	return
+}
+
 // LookupNifiUser returns the user instance based on its name and namespace
 func LookupNifiUser(client runtimeClient.Client, userName, userNamespace string) (user *v1alpha1.NifiUser, err error) {
 	user = &v1alpha1.NifiUser{}
 	err = client.Get(context.TODO(), types.NamespacedName{Name: userName, Namespace: userNamespace}, user)
 	return
 }
+
+// LookupNifiParameterProvider returns the parameter provider instance based on its name and namespace
+func LookupNifiParameterProvider(client runtimeClient.Client, parameterProviderName, parameterProviderNamespace string) (parameterProvider *v1alpha1.NifiParameterProvider, err error) {
+	parameterProvider = &v1alpha1.NifiParameterProvider{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: parameterProviderName, Namespace: parameterProviderNamespace}, parameterProvider)
+	return
+}
+
+// LookupNifiDataflow returns the dataflow instance based on its name and namespace
+func LookupNifiDataflow(client runtimeClient.Client, dataflowName, dataflowNamespace string) (dataflow *v1alpha1.NifiDataflow, err error) {
+	dataflow = &v1alpha1.NifiDataflow{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: dataflowName, Namespace: dataflowNamespace}, dataflow)
+	return
+}
+
+// LookupNifiUserGroup returns the user group instance based on its name and namespace
+func LookupNifiUserGroup(client runtimeClient.Client, userGroupName, userGroupNamespace string) (userGroup *v1alpha1.NifiUserGroup, err error) {
+	userGroup = &v1alpha1.NifiUserGroup{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: userGroupName, Namespace: userGroupNamespace}, userGroup)
+	return
+}
@@ -56,6 +56,10 @@ func UpdateNodeStatus(c client.Client, nodeIds []string, cluster *v1alpha1.NifiC
 				cluster.Status.NodesState = map[string]v1alpha1.NodeState{nodeId: {InitClusterNode: s}}
 			case bool:
 				cluster.Status.NodesState = map[string]v1alpha1.NodeState{nodeId: {PodIsReady: s}}
+			case v1alpha1.DiscoveredNode:
+				cluster.Status.NodesState = map[string]v1alpha1.NodeState{nodeId: {DiscoveredNode: &s}}
+			case v1alpha1.NodeHealthState:
+				cluster.Status.NodesState = map[string]v1alpha1.NodeState{nodeId: {NodeHealth: &s}}
 			}
 		} else if val, ok := cluster.Status.NodesState[nodeId]; ok {
 			switch s := state.(type) {
@@ -67,6 +71,10 @@ func UpdateNodeStatus(c client.Client, nodeIds []string, cluster *v1alpha1.NifiC
 				val.InitClusterNode = s
 			case bool:
 				val.PodIsReady = s
+			case v1alpha1.DiscoveredNode:
+				val.DiscoveredNode = &s
+			case v1alpha1.NodeHealthState:
+				val.NodeHealth = &s
 			}
 			cluster.Status.NodesState[nodeId] = val
 		} else {
@@ -79,6 +87,10 @@ func UpdateNodeStatus(c client.Client, nodeIds []string, cluster *v1alpha1.NifiC
 				cluster.Status.NodesState[nodeId] = v1alpha1.NodeState{InitClusterNode: s}
 			case bool:
 				cluster.Status.NodesState[nodeId] = v1alpha1.NodeState{PodIsReady: s}
+			case v1alpha1.DiscoveredNode:
+				cluster.Status.NodesState[nodeId] = v1alpha1.NodeState{DiscoveredNode: &s}
+			case v1alpha1.NodeHealthState:
+				cluster.Status.NodesState[nodeId] = v1alpha1.NodeState{NodeHealth: &s}
 			}
 		}
 	}
@@ -111,6 +123,10 @@ func UpdateNodeStatus(c client.Client, nodeIds []string, cluster *v1alpha1.NifiC
 					cluster.Status.NodesState = map[string]v1alpha1.NodeState{nodeId: {InitClusterNode: s}}
 				case bool:
 					cluster.Status.NodesState = map[string]v1alpha1.NodeState{nodeId: {PodIsReady: s}}
+				case v1alpha1.DiscoveredNode:
+					cluster.Status.NodesState = map[string]v1alpha1.NodeState{nodeId: {DiscoveredNode: &s}}
+				case v1alpha1.NodeHealthState:
+					cluster.Status.NodesState = map[string]v1alpha1.NodeState{nodeId: {NodeHealth: &s}}
 				}
 			} else if val, ok := cluster.Status.NodesState[nodeId]; ok {
 				switch s := state.(type) {
@@ -122,6 +138,10 @@ func UpdateNodeStatus(c client.Client, nodeIds []string, cluster *v1alpha1.NifiC
 					val.InitClusterNode = s
 				case bool:
 					val.PodIsReady = s
+				case v1alpha1.DiscoveredNode:
+					val.DiscoveredNode = &s
+				case v1alpha1.NodeHealthState:
+					val.NodeHealth = &s
 				}
 				cluster.Status.NodesState[nodeId] = val
 			} else {
@@ -134,6 +154,10 @@ func UpdateNodeStatus(c client.Client, nodeIds []string, cluster *v1alpha1.NifiC
 					cluster.Status.NodesState[nodeId] = v1alpha1.NodeState{InitClusterNode: s}
 				case bool:
 					cluster.Status.NodesState[nodeId] = v1alpha1.NodeState{PodIsReady: s}
+				case v1alpha1.DiscoveredNode:
+					cluster.Status.NodesState[nodeId] = v1alpha1.NodeState{DiscoveredNode: &s}
+				case v1alpha1.NodeHealthState:
+					cluster.Status.NodesState[nodeId] = v1alpha1.NodeState{NodeHealth: &s}
 				}
 			}
 		}
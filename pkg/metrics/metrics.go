@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace         = "nifikop"
+	dataflowSubsystem = "dataflow"
+)
+
+var (
+	dataflowLabels  = []string{"namespace", "name"}
+	componentLabels = []string{"namespace", "name", "state"}
+
+	dataflowFlowFilesQueued = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: dataflowSubsystem,
+		Name:      "flowfiles_queued",
+		Help:      "Number of FlowFiles queued into the dataflow.",
+	}, dataflowLabels)
+	dataflowBytesQueued = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: dataflowSubsystem,
+		Name:      "bytes_queued",
+		Help:      "Number of bytes queued into the dataflow.",
+	}, dataflowLabels)
+	dataflowBytesIn = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: dataflowSubsystem,
+		Name:      "bytes_in_5m",
+		Help:      "Number of bytes that have come into the dataflow in the last 5 minutes.",
+	}, dataflowLabels)
+	dataflowBytesOut = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: dataflowSubsystem,
+		Name:      "bytes_out_5m",
+		Help:      "Number of bytes transferred out of the dataflow in the last 5 minutes.",
+	}, dataflowLabels)
+	dataflowActiveThreads = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: dataflowSubsystem,
+		Name:      "active_threads",
+		Help:      "Number of active threads of the dataflow.",
+	}, dataflowLabels)
+	dataflowComponents = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: dataflowSubsystem,
+		Name:      "components",
+		Help:      "Number of components of the dataflow per state.",
+	}, componentLabels)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		dataflowFlowFilesQueued,
+		dataflowBytesQueued,
+		dataflowBytesIn,
+		dataflowBytesOut,
+		dataflowActiveThreads,
+		dataflowComponents,
+	)
+}
+
+// SetDataflowStatistics exports the runtime statistics of the given dataflow.
+func SetDataflowStatistics(flow *v1alpha1.NifiDataflow, stats v1alpha1.FlowStatistics) {
+	dataflowFlowFilesQueued.WithLabelValues(flow.Namespace, flow.Name).Set(float64(stats.FlowFilesQueued))
+	dataflowBytesQueued.WithLabelValues(flow.Namespace, flow.Name).Set(float64(stats.BytesQueued))
+	dataflowBytesIn.WithLabelValues(flow.Namespace, flow.Name).Set(float64(stats.BytesIn))
+	dataflowBytesOut.WithLabelValues(flow.Namespace, flow.Name).Set(float64(stats.BytesOut))
+	dataflowActiveThreads.WithLabelValues(flow.Namespace, flow.Name).Set(float64(stats.ActiveThreadCount))
+	dataflowComponents.WithLabelValues(flow.Namespace, flow.Name, "running").Set(float64(stats.RunningCount))
+	dataflowComponents.WithLabelValues(flow.Namespace, flow.Name, "stopped").Set(float64(stats.StoppedCount))
+	dataflowComponents.WithLabelValues(flow.Namespace, flow.Name, "invalid").Set(float64(stats.InvalidCount))
+	dataflowComponents.WithLabelValues(flow.Namespace, flow.Name, "disabled").Set(float64(stats.DisabledCount))
+}
+
+// DeleteDataflowStatistics stops exporting the runtime statistics of the given dataflow.
+func DeleteDataflowStatistics(flow *v1alpha1.NifiDataflow) {
+	for _, gauge := range []*prometheus.GaugeVec{
+		dataflowFlowFilesQueued, dataflowBytesQueued, dataflowBytesIn, dataflowBytesOut, dataflowActiveThreads} {
+		gauge.DeleteLabelValues(flow.Namespace, flow.Name)
+	}
+	for _, state := range []string{"running", "stopped", "invalid", "disabled"} {
+		dataflowComponents.DeleteLabelValues(flow.Namespace, flow.Name, state)
+	}
+}
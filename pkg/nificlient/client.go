@@ -76,6 +76,8 @@ type NifiClient interface {
 	UpdateFlowControllerServices(entity nigoapi.ActivateControllerServicesEntity) (*nigoapi.ActivateControllerServicesEntity, error)
 	UpdateFlowProcessGroup(entity nigoapi.ScheduleComponentsEntity) (*nigoapi.ScheduleComponentsEntity, error)
 	GetFlowControllerServices(id string) (*nigoapi.ControllerServicesEntity, error)
+	GetProcessGroupStatus(id string) (*nigoapi.ProcessGroupStatusEntity, error)
+	GetBulletinBoard(after int64) (*nigoapi.BulletinBoardEntity, error)
 
 	// Drop request func
 	GetDropRequest(connectionId, id string) (*nigoapi.DropRequestEntity, error)
@@ -86,12 +88,14 @@ type NifiClient interface {
 	CreateProcessGroup(entity nigoapi.ProcessGroupEntity, pgParentId string) (*nigoapi.ProcessGroupEntity, error)
 	UpdateProcessGroup(entity nigoapi.ProcessGroupEntity) (*nigoapi.ProcessGroupEntity, error)
 	RemoveProcessGroup(entity nigoapi.ProcessGroupEntity) error
+	GetLocalModifications(pgId string) (*nigoapi.FlowComparisonEntity, error)
 
 	// Version func
 	CreateVersionUpdateRequest(pgId string, entity nigoapi.VersionControlInformationEntity) (*nigoapi.VersionedFlowUpdateRequestEntity, error)
 	GetVersionUpdateRequest(id string) (*nigoapi.VersionedFlowUpdateRequestEntity, error)
 	CreateVersionRevertRequest(pgId string, entity nigoapi.VersionControlInformationEntity) (*nigoapi.VersionedFlowUpdateRequestEntity, error)
 	GetVersionRevertRequest(id string) (*nigoapi.VersionedFlowUpdateRequestEntity, error)
+	CreateFlowVersion(pgId string, entity nigoapi.StartVersionControlRequestEntity) (*nigoapi.VersionControlInformationEntity, error)
 
 	// Snippet func
 	CreateSnippet(entity nigoapi.SnippetEntity) (*nigoapi.SnippetEntity, error)
@@ -101,15 +105,32 @@ type NifiClient interface {
 	UpdateProcessor(entity nigoapi.ProcessorEntity) (*nigoapi.ProcessorEntity, error)
 	UpdateProcessorRunStatus(id string, entity nigoapi.ProcessorRunStatusEntity) (*nigoapi.ProcessorEntity, error)
 
+	// Controller service func
+	UpdateControllerServiceRunStatus(id string, entity nigoapi.ControllerServiceRunStatusEntity) (*nigoapi.ControllerServiceEntity, error)
+
 	// Input port func
 	UpdateInputPortRunStatus(id string, entity nigoapi.PortRunStatusEntity) (*nigoapi.ProcessorEntity, error)
 
+	// Output port func
+	UpdateOutputPortRunStatus(id string, entity nigoapi.PortRunStatusEntity) (*nigoapi.ProcessorEntity, error)
+
 	// Parameter context func
 	GetParameterContext(id string) (*nigoapi.ParameterContextEntity, error)
 	CreateParameterContext(entity nigoapi.ParameterContextEntity) (*nigoapi.ParameterContextEntity, error)
 	RemoveParameterContext(entity nigoapi.ParameterContextEntity) error
 	CreateParameterContextUpdateRequest(contextId string, entity nigoapi.ParameterContextEntity) (*nigoapi.ParameterContextUpdateRequestEntity, error)
 	GetParameterContextUpdateRequest(contextId, id string) (*nigoapi.ParameterContextUpdateRequestEntity, error)
+	GetParameterContextInheritance(id string) (*nigoapi.ParameterContextEntity, []string, error)
+	CreateParameterContextInheritanceUpdateRequest(contextId string, entity nigoapi.ParameterContextEntity, inheritedIds []string) (*nigoapi.ParameterContextUpdateRequestEntity, error)
+
+	// Parameter provider func
+	GetParameterProvider(id string) (*ParameterProviderEntity, error)
+	CreateParameterProvider(entity ParameterProviderEntity) (*ParameterProviderEntity, error)
+	UpdateParameterProvider(entity ParameterProviderEntity) (*ParameterProviderEntity, error)
+	RemoveParameterProvider(entity ParameterProviderEntity) error
+	FetchParameterProviderParameters(entity ParameterProviderParameterFetchEntity) (*ParameterProviderEntity, error)
+	CreateParameterProviderApplyRequest(entity ParameterProviderParameterApplicationEntity) (*ParameterProviderApplyParametersRequestEntity, error)
+	GetParameterProviderApplyRequest(providerId, id string) (*ParameterProviderApplyParametersRequestEntity, error)
 
 	// User groups func
 	GetUserGroups() ([]nigoapi.UserGroupEntity, error)
@@ -150,6 +171,8 @@ type nifiClient struct {
 	opts       *clientconfig.NifiConfig
 	client     *nigoapi.APIClient
 	nodeClient map[int32]*nigoapi.APIClient
+	config     *nigoapi.Configuration
+	nodeConfig map[int32]*nigoapi.Configuration
 	timeout    time.Duration
 	nodes      []nigoapi.NodeDto
 
@@ -168,13 +191,15 @@ func New(opts *clientconfig.NifiConfig) NifiClient {
 }
 
 func (n *nifiClient) Build() error {
-	config := n.getNifiGoApiConfig()
-	n.client = n.newClient(config)
+	n.config = n.getNifiGoApiConfig()
+	n.client = n.newClient(n.config)
 
 	n.nodeClient = make(map[int32]*nigoapi.APIClient)
+	n.nodeConfig = make(map[int32]*nigoapi.Configuration)
 	for nodeId, _ := range n.opts.NodesURI {
 		nodeConfig := n.getNiNodeGoApiConfig(nodeId)
 		n.nodeClient[nodeId] = n.newClient(nodeConfig)
+		n.nodeConfig[nodeId] = nodeConfig
 	}
 
 	if !n.opts.SkipDescribeCluster {
@@ -295,6 +320,19 @@ func (n *nifiClient) privilegeCoordinatorClient() (*nigoapi.APIClient, context.C
 	return n.client, nil
 }
 
+// privilegeCoordinatorConfig returns the configuration of the client returned by privilegeCoordinatorClient.
+func (n *nifiClient) privilegeCoordinatorConfig() (*nigoapi.Configuration, context.Context) {
+	if clientId := n.coordinatorNodeId(); clientId != nil {
+		return n.nodeConfig[*clientId], n.opts.NodesContext[*clientId]
+	}
+
+	if clientId := n.privilegeNodeClient(); clientId != nil {
+		return n.nodeConfig[*clientId], n.opts.NodesContext[*clientId]
+	}
+
+	return n.config, nil
+}
+
 func (n *nifiClient) privilegeCoordinatorExceptNodeIdClient(nId int32) (*nigoapi.APIClient, context.Context) {
 	nodeDto := n.nodeDtoByNodeId(nId)
 	if nodeDto == nil || isCoordinator(nodeDto) {
@@ -357,10 +395,19 @@ func isConnected(node *nigoapi.NodeDto) bool {
 }
 
 func (n *nifiClient) nodeDtoByNodeId(nId int32) *nigoapi.NodeDto {
+	searchedUri := fmt.Sprintf(n.opts.NodeURITemplate, nId)
+	// Without template (i.e nodes discovered from the NiFi cluster API), we rely on the known node uri.
+	if n.opts.NodeURITemplate == "" {
+		nodeUri, ok := n.opts.NodesURI[nId]
+		if !ok {
+			return nil
+		}
+		searchedUri = nodeUri.RequestHost
+	}
 	for id := range n.nodes {
 		nodeDto := n.nodes[id]
 		// Check if the Cluster Node uri match with the one associated to the NifiCluster nodeId searched
-		if fmt.Sprintf("%s:%d", nodeDto.Address, nodeDto.ApiPort) == fmt.Sprintf(n.opts.NodeURITemplate, nId) {
+		if fmt.Sprintf("%s:%d", nodeDto.Address, nodeDto.ApiPort) == searchedUri {
 			return &nodeDto
 		}
 	}
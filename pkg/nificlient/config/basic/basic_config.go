@@ -76,10 +76,28 @@ type Claims struct {
 func clusterConfig(client client.Client, cluster *v1alpha1.NifiCluster) (*clientconfig.NifiConfig, error) {
 	conf := configcommon.ClusterConfig(cluster)
 
-	username, password, rootCAs, err := GetControllerBasicConfigFromSecret(client, cluster.Spec.SecretRef)
+	secretRef := cluster.Spec.SecretRef
+	// An internal cluster is authenticated with the credentials of its single user
+	if cluster.IsInternal() && cluster.Spec.SingleUserConfiguration.SecretRef != nil {
+		secretRef = *cluster.Spec.SingleUserConfiguration.SecretRef
+		if secretRef.Namespace == "" {
+			secretRef.Namespace = cluster.Namespace
+		}
+	}
+
+	username, password, rootCAs, err := GetControllerBasicConfigFromSecret(client, secretRef)
 	if err != nil {
 		return conf, err
 	}
+
+	// Trust the certificate authority of the internal cluster PKI when no one is provided
+	if rootCAs == nil && cluster.IsInternal() && configcommon.UseSSL(cluster) {
+		tlsConfig, err := configcommon.TlsConfig(client, cluster)
+		if err != nil {
+			return conf, err
+		}
+		rootCAs = tlsConfig.RootCAs
+	}
 	conf.UseSSL = true
 	conf.TLSConfig = &tls.Config{RootCAs: rootCAs}
 	conf.SkipDescribeCluster = true
@@ -33,10 +33,18 @@ func ClusterConfig(cluster *v1alpha1.NifiCluster) *clientconfig.NifiConfig {
 func externalClusterConfig(cluster *v1alpha1.NifiCluster) *clientconfig.NifiConfig {
 	conf := &clientconfig.NifiConfig{}
 	ref := cluster.Spec
+	nodeURITemplate := ref.NodeURITemplate
 	nodesURI := generateNodesAddressFromTemplate(ref.Nodes, ref.NodeURITemplate)
+	if ref.GetNodeDiscovery() == v1alpha1.ApiNodeDiscovery {
+		// Until the first discovery succeeded, we rely on the static nodes definition if any.
+		if discoveredNodesURI := generateNodesAddressFromDiscovery(cluster.Status.NodesState); len(discoveredNodesURI) > 0 {
+			nodeURITemplate = ""
+			nodesURI = discoveredNodesURI
+		}
+	}
 
 	conf.RootProcessGroupId = ref.RootProcessGroupId
-	conf.NodeURITemplate = ref.NodeURITemplate
+	conf.NodeURITemplate = nodeURITemplate
 	conf.NodesURI = nodesURI
 	conf.NifiURI = ref.NifiURI
 	conf.OperationTimeout = clientconfig.NifiDefaultTimeout
@@ -96,6 +104,21 @@ func generateNodesAddressFromTemplate(nodes []v1alpha1.Node, template string) ma
 	return addresses
 }
 
+func generateNodesAddressFromDiscovery(nodesState map[string]v1alpha1.NodeState) map[int32]clientconfig.NodeUri {
+	addresses := make(map[int32]clientconfig.NodeUri)
+
+	for nId, state := range nodesState {
+		if state.DiscoveredNode == nil {
+			continue
+		}
+		addresses[util.ConvertStringToInt32(nId)] = clientconfig.NodeUri{
+			HostListener: state.DiscoveredNode.Address,
+			RequestHost:  state.DiscoveredNode.Address,
+		}
+	}
+	return addresses
+}
+
 func UseSSL(cluster *v1alpha1.NifiCluster) bool {
 	return cluster.Spec.ListenersConfig.SSLSecrets != nil
 }
@@ -143,3 +143,40 @@ func TestGenerateNodesURITemplate(t *testing.T) {
 			clusterName, "%d", clusterNamespace, httpContainerPort),
 		generateNodesURITemplate(cluster))
 }
+
+func TestExternalClusterConfigNodeDiscovery(t *testing.T) {
+	assert := assert.New(t)
+
+	cluster := &v1alpha1.NifiCluster{}
+	cluster.Name = clusterName
+	cluster.Namespace = clusterNamespace
+	cluster.Spec = v1alpha1.NifiClusterSpec{
+		Type:            v1alpha1.ExternalCluster,
+		NodeURITemplate: "nifi-%d.external.com:8443",
+		NifiURI:         "nifi.external.com:8443",
+		Nodes:           []v1alpha1.Node{{Id: 1}},
+	}
+
+	// Static discovery relies on the template
+	conf := ClusterConfig(cluster)
+	assert.Equal(1, len(conf.NodesURI))
+	assert.Equal("nifi-1.external.com:8443", conf.NodesURI[1].RequestHost)
+
+	// Api discovery falls back on the template until nodes have been discovered
+	cluster.Spec.NodeDiscovery = v1alpha1.ApiNodeDiscovery
+	conf = ClusterConfig(cluster)
+	assert.Equal(1, len(conf.NodesURI))
+	assert.Equal("nifi-1.external.com:8443", conf.NodesURI[1].RequestHost)
+
+	cluster.Status.NodesState = map[string]v1alpha1.NodeState{
+		"0": {DiscoveredNode: &v1alpha1.DiscoveredNode{ClusterNodeId: "a", Address: "10.0.0.1:8443"}},
+		"3": {DiscoveredNode: &v1alpha1.DiscoveredNode{ClusterNodeId: "b", Address: "10.0.0.2:8443"}},
+	}
+	conf = ClusterConfig(cluster)
+	assert.Equal(2, len(conf.NodesURI))
+	assert.Equal("10.0.0.1:8443", conf.NodesURI[0].RequestHost)
+	assert.Equal("10.0.0.1:8443", conf.NodesURI[0].HostListener)
+	assert.Equal("10.0.0.2:8443", conf.NodesURI[3].RequestHost)
+	assert.Equal("", conf.NodeURITemplate)
+	assert.Equal("nifi.external.com:8443", conf.NifiURI)
+}
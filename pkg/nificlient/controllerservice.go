@@ -0,0 +1,23 @@
+package nificlient
+
+import nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+
+func (n *nifiClient) UpdateControllerServiceRunStatus(
+	id string,
+	entity nigoapi.ControllerServiceRunStatusEntity) (*nigoapi.ControllerServiceEntity, error) {
+
+	// Get nigoapi client, favoring the one associated to the coordinator node.
+	client, context := n.privilegeCoordinatorClient()
+	if client == nil {
+		log.Error(ErrNoNodeClientsAvailable, "Error during creating node client")
+		return nil, ErrNoNodeClientsAvailable
+	}
+
+	// Request on Nifi Rest API to update the controller service run status
+	controllerService, rsp, body, err := client.ControllerServicesApi.UpdateRunStatus(context, id, entity)
+	if err := errorUpdateOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &controllerService, nil
+}
@@ -0,0 +1,62 @@
+package nificlient
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateControllerServiceRunStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	id := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+
+	mockEntity := MockControllerServiceRunStatus("DISABLED")
+
+	entity, err := testUpdateControllerServiceRunStatus(t, mockEntity, id, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+
+	entity, err = testUpdateControllerServiceRunStatus(t, mockEntity, id, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testUpdateControllerServiceRunStatus(t, mockEntity, id, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testUpdateControllerServiceRunStatus(t *testing.T, entity nigoapi.ControllerServiceRunStatusEntity, id string, status int) (*nigoapi.ControllerServiceEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/controller-services/%s/run-status", id))
+	httpmock.RegisterResponder(http.MethodPut, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				MockControllerService(id, "16cfd2ec-0174-1000-0000-00004b9b35cd", "service", entity.State))
+		})
+
+	return client.UpdateControllerServiceRunStatus(id, entity)
+}
+
+func MockControllerServiceRunStatus(state string) nigoapi.ControllerServiceRunStatusEntity {
+	var version int64 = 10
+	return nigoapi.ControllerServiceRunStatusEntity{
+		Revision: &nigoapi.RevisionDto{Version: &version},
+		State:    state,
+	}
+}
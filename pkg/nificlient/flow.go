@@ -1,6 +1,8 @@
 package nificlient
 
 import (
+	"strconv"
+
 	"github.com/antihax/optional"
 	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
 )
@@ -80,6 +82,48 @@ func (n *nifiClient) UpdateFlowProcessGroup(entity nigoapi.ScheduleComponentsEnt
 	return &csEntity, nil
 }
 
+func (n *nifiClient) GetProcessGroupStatus(id string) (*nigoapi.ProcessGroupStatusEntity, error) {
+	// Get nigoapi client, favoring the one associated to the coordinator node.
+	client, context := n.privilegeCoordinatorClient()
+	if client == nil {
+		log.Error(ErrNoNodeClientsAvailable, "Error during creating node client")
+		return nil, ErrNoNodeClientsAvailable
+	}
+
+	// Request on Nifi Rest API to get the process group status, aggregated on all the nodes
+	pgStatusEntity, rsp, body, err := client.FlowApi.GetProcessGroupStatus(context, id,
+		&nigoapi.FlowApiGetProcessGroupStatusOpts{
+			Recursive: optional.NewBool(false),
+			Nodewise:  optional.NewBool(false),
+		})
+	if err := errorGetOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &pgStatusEntity, nil
+}
+
+func (n *nifiClient) GetBulletinBoard(after int64) (*nigoapi.BulletinBoardEntity, error) {
+	// Get nigoapi client, favoring the one associated to the coordinator node.
+	client, context := n.privilegeCoordinatorClient()
+	if client == nil {
+		log.Error(ErrNoNodeClientsAvailable, "Error during creating node client")
+		return nil, ErrNoNodeClientsAvailable
+	}
+
+	// Request on Nifi Rest API to get the bulletins emitted after the given one
+	opts := &nigoapi.FlowApiGetBulletinBoardOpts{}
+	if after > 0 {
+		opts.After = optional.NewString(strconv.FormatInt(after, 10))
+	}
+	bulletinBoard, rsp, body, err := client.FlowApi.GetBulletinBoard(context, opts)
+	if err := errorGetOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &bulletinBoard, nil
+}
+
 // TODO : when last supported will be NiFi 1.12.X
 //func (n *nifiClient) FlowDropRequest(connectionId, id string) (*nigoapi.DropRequestEntity, error) {
 //	// Get nigoapi client, favoring the one associated to the coordinator node.
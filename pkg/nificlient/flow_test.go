@@ -216,6 +216,132 @@ func testUpdateFlowProcessGroup(t *testing.T, entity nigoapi.ScheduleComponentsE
 	return client.UpdateFlowProcessGroup(entity)
 }
 
+func TestGetProcessGroupStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	id := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+
+	entity, err := testGetProcessGroupStatus(t, id, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+	assert.Equal(int32(12), entity.ProcessGroupStatus.AggregateSnapshot.FlowFilesQueued)
+
+	entity, err = testGetProcessGroupStatus(t, id, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testGetProcessGroupStatus(t, id, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testGetProcessGroupStatus(t *testing.T, id string, status int) (*nigoapi.ProcessGroupStatusEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/flow/process-groups/%s/status", id))
+	httpmock.RegisterResponder(http.MethodGet, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				MockProcessGroupStatus(id, 12, 1024))
+		})
+
+	return client.GetProcessGroupStatus(id)
+}
+
+func TestGetBulletinBoard(t *testing.T) {
+	assert := assert.New(t)
+
+	pgId := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+
+	entity, err := testGetBulletinBoard(t, pgId, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+	assert.Equal(2, len(entity.BulletinBoard.Bulletins))
+
+	entity, err = testGetBulletinBoard(t, pgId, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testGetBulletinBoard(t, pgId, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testGetBulletinBoard(t *testing.T, pgId string, status int) (*nigoapi.BulletinBoardEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, "/flow/bulletin-board")
+	httpmock.RegisterResponder(http.MethodGet, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				MockBulletinBoard([]nigoapi.BulletinEntity{
+					MockBulletin(11, pgId, "16cfd2ec-2174-1065-0650-10004b9b35cc", "ERROR", "unit test error"),
+					MockBulletin(12, pgId, "16cfd2ec-2174-1065-0650-10004b9b35cc", "WARNING", "unit test warning"),
+				}))
+		})
+
+	return client.GetBulletinBoard(10)
+}
+
+func MockProcessGroupStatus(id string, flowFilesQueued int32, bytesQueued int64) nigoapi.ProcessGroupStatusEntity {
+	return nigoapi.ProcessGroupStatusEntity{
+		CanRead: true,
+		ProcessGroupStatus: &nigoapi.ProcessGroupStatusDto{
+			Id: id,
+			AggregateSnapshot: &nigoapi.ProcessGroupStatusSnapshotDto{
+				Id:              id,
+				FlowFilesQueued: flowFilesQueued,
+				BytesQueued:     bytesQueued,
+			},
+		},
+	}
+}
+
+func MockBulletinBoard(bulletins []nigoapi.BulletinEntity) nigoapi.BulletinBoardEntity {
+	return nigoapi.BulletinBoardEntity{
+		BulletinBoard: &nigoapi.BulletinBoardDto{
+			Bulletins: bulletins,
+		},
+	}
+}
+
+func MockBulletin(id int64, pgId, sourceId, level, message string) nigoapi.BulletinEntity {
+	return nigoapi.BulletinEntity{
+		Id:       id,
+		GroupId:  pgId,
+		SourceId: sourceId,
+		CanRead:  true,
+		Bulletin: &nigoapi.BulletinDto{
+			Id:         id,
+			GroupId:    pgId,
+			SourceId:   sourceId,
+			SourceName: "unit-test processor",
+			Level:      level,
+			Message:    message,
+		},
+	}
+}
+
 func MockFlowControllerServices(cs []nigoapi.ControllerServiceEntity) nigoapi.ControllerServicesEntity {
 	return nigoapi.ControllerServicesEntity{
 		ControllerServices: cs,
@@ -15,6 +15,9 @@
 package nificlient
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/antihax/optional"
@@ -105,3 +108,103 @@ func (n *nifiClient) GetParameterContextUpdateRequest(contextId, id string) (*ni
 
 	return &request, nil
 }
+
+// parameterContextInheritanceDto extends the nigoapi parameter context model with the inherited parameter contexts,
+// available since NiFi 1.15.
+type parameterContextInheritanceDto struct {
+	nigoapi.ParameterContextDto
+	InheritedParameterContexts []nigoapi.ParameterContextReferenceEntity `json:"inheritedParameterContexts"`
+}
+
+type parameterContextInheritanceEntity struct {
+	Revision                     *nigoapi.RevisionDto            `json:"revision,omitempty"`
+	Id                           string                          `json:"id,omitempty"`
+	DisconnectedNodeAcknowledged bool                            `json:"disconnectedNodeAcknowledged,omitempty"`
+	Component                    *parameterContextInheritanceDto `json:"component,omitempty"`
+}
+
+// inheritedParametersEntity lists the parameters of a parameter context flagged as inherited by NiFi.
+type inheritedParametersEntity struct {
+	Component *struct {
+		Parameters []struct {
+			Parameter *struct {
+				Name      string `json:"name"`
+				Inherited bool   `json:"inherited"`
+			} `json:"parameter"`
+		} `json:"parameters"`
+	} `json:"component"`
+}
+
+func (n *nifiClient) GetParameterContextInheritance(id string) (*nigoapi.ParameterContextEntity, []string, error) {
+	// Request on Nifi Rest API to get the parameter context informations, including the inherited parameter contexts.
+	var pcEntity parameterContextInheritanceEntity
+	rsp, body, err := n.callRawApi(http.MethodGet, fmt.Sprintf("/parameter-contexts/%s", id), nil, &pcEntity)
+	if err := errorGetOperation(rsp, body, err); err != nil {
+		return nil, nil, err
+	}
+
+	var inheritedParameters inheritedParametersEntity
+	if err := json.Unmarshal([]byte(*body), &inheritedParameters); err != nil {
+		return nil, nil, err
+	}
+
+	entity := nigoapi.ParameterContextEntity{
+		Revision:                     pcEntity.Revision,
+		Id:                           pcEntity.Id,
+		DisconnectedNodeAcknowledged: pcEntity.DisconnectedNodeAcknowledged,
+	}
+	var inheritedIds []string
+	if pcEntity.Component != nil {
+		component := pcEntity.Component.ParameterContextDto
+		// The inherited parameters are owned by the inherited parameter contexts.
+		component.Parameters = make([]nigoapi.ParameterEntity, 0)
+		for i, parameter := range pcEntity.Component.Parameters {
+			if p := inheritedParameters.Component.Parameters[i].Parameter; p != nil && p.Inherited {
+				continue
+			}
+			component.Parameters = append(component.Parameters, parameter)
+		}
+		entity.Component = &component
+
+		for _, inherited := range pcEntity.Component.InheritedParameterContexts {
+			inheritedIds = append(inheritedIds, inherited.Id)
+		}
+	}
+
+	return &entity, inheritedIds, nil
+}
+
+func (n *nifiClient) CreateParameterContextInheritanceUpdateRequest(
+	contextId string,
+	entity nigoapi.ParameterContextEntity,
+	inheritedIds []string) (*nigoapi.ParameterContextUpdateRequestEntity, error) {
+
+	pcEntity := parameterContextInheritanceEntity{
+		Revision:                     entity.Revision,
+		Id:                           entity.Id,
+		DisconnectedNodeAcknowledged: entity.DisconnectedNodeAcknowledged,
+		Component: &parameterContextInheritanceDto{
+			InheritedParameterContexts: make([]nigoapi.ParameterContextReferenceEntity, 0, len(inheritedIds)),
+		},
+	}
+	if entity.Component != nil {
+		pcEntity.Component.ParameterContextDto = *entity.Component
+	}
+	for _, inheritedId := range inheritedIds {
+		pcEntity.Component.InheritedParameterContexts = append(pcEntity.Component.InheritedParameterContexts,
+			nigoapi.ParameterContextReferenceEntity{
+				Id:        inheritedId,
+				Component: &nigoapi.ParameterContextReferenceDto{Id: inheritedId},
+			})
+	}
+
+	// Request on Nifi Rest API to create the parameter context update request, including the inherited parameter contexts.
+	var request nigoapi.ParameterContextUpdateRequestEntity
+	rsp, body, err := n.callRawApi(http.MethodPost,
+		fmt.Sprintf("/parameter-contexts/%s/update-requests", contextId), pcEntity, &request)
+	if err := errorUpdateOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
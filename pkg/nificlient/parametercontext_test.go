@@ -1,6 +1,7 @@
 package nificlient
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -234,6 +235,140 @@ func testGetParameterContextUpdateRequest(
 	return client.GetParameterContextUpdateRequest(entity.Component.Id, id)
 }
 
+func TestGetParameterContextInheritance(t *testing.T) {
+	assert := assert.New(t)
+
+	id := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+	inheritedIds := []string{"16cfd2ec-0174-1000-0000-00004b9b35dd", "16cfd2ec-0174-1000-0000-00004b9b35ee"}
+
+	entity, ids, err := testGetParameterContextInheritance(t, id, inheritedIds, 200)
+	assert.Nil(err)
+	assert.Equal(inheritedIds, ids)
+	assert.Equal(1, len(entity.Component.Parameters))
+	assert.Equal("key1", entity.Component.Parameters[0].Parameter.Name)
+
+	entity, ids, err = testGetParameterContextInheritance(t, id, inheritedIds, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+	assert.Nil(ids)
+
+	entity, ids, err = testGetParameterContextInheritance(t, id, inheritedIds, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+	assert.Nil(ids)
+}
+
+func testGetParameterContextInheritance(t *testing.T, id string, inheritedIds []string, status int) (*nigoapi.ParameterContextEntity, []string, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/parameter-contexts/%s", id))
+	httpmock.RegisterResponder(http.MethodGet, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				MockParameterContextInheritance(id, "test-unit",
+					map[string]string{"key1": "value1"},
+					map[string]string{"inherited1": "value1"},
+					inheritedIds))
+		})
+
+	return client.GetParameterContextInheritance(id)
+}
+
+func TestCreateParameterContextInheritanceUpdateRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	mockEntity := MockParameterContext("16cfd2ec-0174-1000-0000-00004b9b35cc", "test-unit",
+		"unit test",
+		map[string]string{"key1": "value1", "key2": "value2"},
+		map[string]string{"secret1": "value1", "secret2": "value2"})
+	inheritedIds := []string{"16cfd2ec-0174-1000-0000-00004b9b35dd"}
+
+	entity, err := testCreateParameterContextInheritanceUpdateRequest(t, &mockEntity, inheritedIds, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+
+	entity, err = testCreateParameterContextInheritanceUpdateRequest(t, &mockEntity, inheritedIds, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testCreateParameterContextInheritanceUpdateRequest(t, &mockEntity, inheritedIds, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testCreateParameterContextInheritanceUpdateRequest(
+	t *testing.T,
+	entity *nigoapi.ParameterContextEntity,
+	inheritedIds []string,
+	status int) (*nigoapi.ParameterContextUpdateRequestEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf(
+		"/parameter-contexts/%s/update-requests", entity.Id))
+	httpmock.RegisterResponder(http.MethodPost, url,
+		func(req *http.Request) (*http.Response, error) {
+			var pcEntity parameterContextInheritanceEntity
+			if err := json.NewDecoder(req.Body).Decode(&pcEntity); err != nil ||
+				len(pcEntity.Component.InheritedParameterContexts) != len(inheritedIds) {
+				return httpmock.NewStringResponse(400, "invalid inherited parameter contexts"), nil
+			}
+			return httpmock.NewJsonResponse(
+				status,
+				entity)
+		})
+
+	return client.CreateParameterContextInheritanceUpdateRequest(entity.Id, *entity, inheritedIds)
+}
+
+func MockParameterContextInheritance(
+	id, name string,
+	params, inheritedParams map[string]string,
+	inheritedIds []string) map[string]interface{} {
+
+	var entity map[string]interface{}
+	b, _ := json.Marshal(MockParameterContext(id, name, "unit test", params, map[string]string{}))
+	json.Unmarshal(b, &entity)
+
+	component := entity["component"].(map[string]interface{})
+	parameters := component["parameters"].([]interface{})
+	for k, v := range inheritedParams {
+		parameters = append(parameters, map[string]interface{}{
+			"parameter": map[string]interface{}{"name": k, "value": v, "inherited": true},
+		})
+	}
+	component["parameters"] = parameters
+
+	var inherited []interface{}
+	for _, inheritedId := range inheritedIds {
+		inherited = append(inherited, map[string]interface{}{
+			"id":        inheritedId,
+			"component": map[string]interface{}{"id": inheritedId},
+		})
+	}
+	component["inheritedParameterContexts"] = inherited
+
+	return entity
+}
+
 func MockParameterContext(
 	id, name, description string,
 	params, sensitivesParameters map[string]string) nigoapi.ParameterContextEntity {
@@ -0,0 +1,197 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package nificlient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+)
+
+// The parameter providers are available since NiFi 1.18, their models are not part of nigoapi.
+
+type ParameterProviderEntity struct {
+	// The revision for this request/response.
+	Revision *nigoapi.RevisionDto `json:"revision,omitempty"`
+	// The id of the component.
+	Id string `json:"id,omitempty"`
+	// The parameter provider.
+	Component *ParameterProviderDto `json:"component,omitempty"`
+}
+
+type ParameterProviderDto struct {
+	// The id of the parameter provider.
+	Id string `json:"id,omitempty"`
+	// The name of the parameter provider.
+	Name string `json:"name,omitempty"`
+	// The fully qualified type of the parameter provider.
+	Type_ string `json:"type,omitempty"`
+	// The details of the artifact that bundled this parameter provider type.
+	Bundle *nigoapi.BundleDto `json:"bundle,omitempty"`
+	// The comments of the parameter provider.
+	Comments string `json:"comments,omitempty"`
+	// The properties of the parameter provider.
+	Properties map[string]*string `json:"properties,omitempty"`
+	// Indicates whether the parameter provider is valid, invalid, or still in the process of validating.
+	ValidationStatus string `json:"validationStatus,omitempty"`
+	// The validation errors of the parameter provider.
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+	// The parameter group configurations fetched by the parameter provider.
+	ParameterGroupConfigurations []ParameterGroupConfigurationEntity `json:"parameterGroupConfigurations,omitempty"`
+	// The status of the fetched parameters.
+	ParameterStatus []ParameterStatusDto `json:"parameterStatus,omitempty"`
+	// The parameter contexts which are provided by the parameter provider.
+	ReferencingParameterContexts []nigoapi.ParameterContextReferenceEntity `json:"referencingParameterContexts,omitempty"`
+}
+
+type ParameterGroupConfigurationEntity struct {
+	// The name of the external parameter group.
+	GroupName string `json:"groupName"`
+	// The name of the parameter context receiving the parameters of the group.
+	ParameterContextName string `json:"parameterContextName,omitempty"`
+	// The sensitivity of each parameter of the group : SENSITIVE or NON_SENSITIVE.
+	ParameterSensitivities map[string]string `json:"parameterSensitivities,omitempty"`
+	// Whether the parameter group is synchronized with a parameter context.
+	Synchronized *bool `json:"synchronized,omitempty"`
+}
+
+type ParameterStatusDto struct {
+	// The fetched parameter.
+	Parameter *nigoapi.ParameterEntity `json:"parameter,omitempty"`
+	// The status of the parameter : NEW, CHANGED, REMOVED, MISSING_BUT_REFERENCED or UNCHANGED.
+	Status string `json:"status,omitempty"`
+}
+
+type ParameterProviderParameterFetchEntity struct {
+	// The id of the parameter provider.
+	Id string `json:"id"`
+	// The revision of the parameter provider.
+	Revision *nigoapi.RevisionDto `json:"revision"`
+}
+
+type ParameterProviderParameterApplicationEntity struct {
+	// The id of the parameter provider.
+	Id string `json:"id"`
+	// The revision of the parameter provider.
+	Revision *nigoapi.RevisionDto `json:"revision"`
+	// The parameter group configurations to apply.
+	ParameterGroupConfigurations []ParameterGroupConfigurationEntity `json:"parameterGroupConfigurations"`
+}
+
+type ParameterProviderApplyParametersRequestEntity struct {
+	// The apply parameters request.
+	Request *ParameterProviderApplyParametersRequestDto `json:"request,omitempty"`
+}
+
+type ParameterProviderApplyParametersRequestDto struct {
+	// The id of the request.
+	RequestId string `json:"requestId,omitempty"`
+	// The uri for this request.
+	Uri string `json:"uri,omitempty"`
+	// The timestamp of when the request was submitted.
+	SubmissionTime string `json:"submissionTime,omitempty"`
+	// The last time this request was updated.
+	LastUpdated string `json:"lastUpdated,omitempty"`
+	// Whether or not this request has completed.
+	Complete bool `json:"complete,omitempty"`
+	// An explanation of why the request failed, or null if this request has not failed.
+	FailureReason string `json:"failureReason,omitempty"`
+	// The percentage complete of the request, between 0 and 100.
+	PercentCompleted int32 `json:"percentCompleted,omitempty"`
+	// The state of the request.
+	State string `json:"state,omitempty"`
+}
+
+func (n *nifiClient) GetParameterProvider(id string) (*ParameterProviderEntity, error) {
+	// Request on Nifi Rest API to get the parameter provider informations
+	var ppEntity ParameterProviderEntity
+	rsp, body, err := n.callRawApi(http.MethodGet, fmt.Sprintf("/parameter-providers/%s", id), nil, &ppEntity)
+	if err := errorGetOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &ppEntity, nil
+}
+
+func (n *nifiClient) CreateParameterProvider(entity ParameterProviderEntity) (*ParameterProviderEntity, error) {
+	// Request on Nifi Rest API to create the parameter provider
+	var ppEntity ParameterProviderEntity
+	rsp, body, err := n.callRawApi(http.MethodPost, "/controller/parameter-providers", entity, &ppEntity)
+	if err := errorCreateOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &ppEntity, nil
+}
+
+func (n *nifiClient) UpdateParameterProvider(entity ParameterProviderEntity) (*ParameterProviderEntity, error) {
+	// Request on Nifi Rest API to update the parameter provider
+	var ppEntity ParameterProviderEntity
+	rsp, body, err := n.callRawApi(http.MethodPut, fmt.Sprintf("/parameter-providers/%s", entity.Id), entity, &ppEntity)
+	if err := errorUpdateOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &ppEntity, nil
+}
+
+func (n *nifiClient) RemoveParameterProvider(entity ParameterProviderEntity) error {
+	// Request on Nifi Rest API to remove the parameter provider
+	rsp, body, err := n.callRawApi(http.MethodDelete,
+		fmt.Sprintf("/parameter-providers/%s?version=%s", entity.Id, strconv.FormatInt(*entity.Revision.Version, 10)),
+		nil, nil)
+
+	return errorDeleteOperation(rsp, body, err)
+}
+
+func (n *nifiClient) FetchParameterProviderParameters(entity ParameterProviderParameterFetchEntity) (*ParameterProviderEntity, error) {
+	// Request on Nifi Rest API to fetch the parameters of the parameter provider
+	var ppEntity ParameterProviderEntity
+	rsp, body, err := n.callRawApi(http.MethodPost,
+		fmt.Sprintf("/parameter-providers/%s/parameters/fetch-requests", entity.Id), entity, &ppEntity)
+	if err := errorUpdateOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &ppEntity, nil
+}
+
+func (n *nifiClient) CreateParameterProviderApplyRequest(
+	entity ParameterProviderParameterApplicationEntity) (*ParameterProviderApplyParametersRequestEntity, error) {
+
+	// Request on Nifi Rest API to apply the fetched parameters to the parameter contexts
+	var request ParameterProviderApplyParametersRequestEntity
+	rsp, body, err := n.callRawApi(http.MethodPost,
+		fmt.Sprintf("/parameter-providers/%s/apply-parameters-requests", entity.Id), entity, &request)
+	if err := errorUpdateOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (n *nifiClient) GetParameterProviderApplyRequest(providerId, id string) (*ParameterProviderApplyParametersRequestEntity, error) {
+	// Request on Nifi Rest API to get the apply parameters request information
+	var request ParameterProviderApplyParametersRequestEntity
+	rsp, body, err := n.callRawApi(http.MethodGet,
+		fmt.Sprintf("/parameter-providers/%s/apply-parameters-requests/%s", providerId, id), nil, &request)
+	if err := errorGetOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
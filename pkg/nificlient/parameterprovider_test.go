@@ -0,0 +1,350 @@
+package nificlient
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetParameterProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	id := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+
+	entity, err := testGetParameterProvider(t, id, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+
+	entity, err = testGetParameterProvider(t, id, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testGetParameterProvider(t, id, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testGetParameterProvider(t *testing.T, id string, status int) (*ParameterProviderEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/parameter-providers/%s", id))
+	httpmock.RegisterResponder(http.MethodGet, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				MockParameterProvider(id, "provider-mock", "org.apache.nifi.parameter.EnvironmentVariableParameterProvider"))
+		})
+
+	return client.GetParameterProvider(id)
+}
+
+func TestCreateParameterProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	mockEntity := MockParameterProvider("16cfd2ec-0174-1000-0000-00004b9b35cc", "mock",
+		"org.apache.nifi.parameter.EnvironmentVariableParameterProvider")
+
+	entity, err := testCreateParameterProvider(t, &mockEntity, 201)
+	assert.Nil(err)
+	assert.NotNil(entity)
+
+	entity, err = testCreateParameterProvider(t, &mockEntity, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testCreateParameterProvider(t, &mockEntity, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testCreateParameterProvider(t *testing.T, entity *ParameterProviderEntity, status int) (*ParameterProviderEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, "/controller/parameter-providers")
+	httpmock.RegisterResponder(http.MethodPost, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				entity)
+		})
+
+	return client.CreateParameterProvider(*entity)
+}
+
+func TestUpdateParameterProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	mockEntity := MockParameterProvider("16cfd2ec-0174-1000-0000-00004b9b35cc", "mock",
+		"org.apache.nifi.parameter.EnvironmentVariableParameterProvider")
+
+	entity, err := testUpdateParameterProvider(t, &mockEntity, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+
+	entity, err = testUpdateParameterProvider(t, &mockEntity, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testUpdateParameterProvider(t, &mockEntity, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testUpdateParameterProvider(t *testing.T, entity *ParameterProviderEntity, status int) (*ParameterProviderEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/parameter-providers/%s", entity.Id))
+	httpmock.RegisterResponder(http.MethodPut, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				entity)
+		})
+
+	return client.UpdateParameterProvider(*entity)
+}
+
+func TestRemoveParameterProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	mockEntity := MockParameterProvider("16cfd2ec-0174-1000-0000-00004b9b35cc", "mock",
+		"org.apache.nifi.parameter.EnvironmentVariableParameterProvider")
+
+	err := testRemoveParameterProvider(t, &mockEntity, 200)
+	assert.Nil(err)
+
+	err = testRemoveParameterProvider(t, &mockEntity, 404)
+	assert.Nil(err)
+
+	err = testRemoveParameterProvider(t, &mockEntity, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+}
+
+func testRemoveParameterProvider(t *testing.T, entity *ParameterProviderEntity, status int) error {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/parameter-providers/%s", entity.Id))
+	httpmock.RegisterResponder(http.MethodDelete, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				entity)
+		})
+
+	return client.RemoveParameterProvider(*entity)
+}
+
+func TestFetchParameterProviderParameters(t *testing.T) {
+	assert := assert.New(t)
+
+	id := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+
+	entity, err := testFetchParameterProviderParameters(t, id, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+	assert.Equal(1, len(entity.Component.ParameterGroupConfigurations))
+	assert.Equal("NEW", entity.Component.ParameterStatus[0].Status)
+
+	entity, err = testFetchParameterProviderParameters(t, id, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testFetchParameterProviderParameters(t, id, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testFetchParameterProviderParameters(t *testing.T, id string, status int) (*ParameterProviderEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	mockEntity := MockParameterProvider(id, "mock", "org.apache.nifi.parameter.EnvironmentVariableParameterProvider")
+	name := "KAFKA_BROKERS"
+	mockEntity.Component.ParameterGroupConfigurations = []ParameterGroupConfigurationEntity{
+		{GroupName: "Environment Variables"},
+	}
+	mockEntity.Component.ParameterStatus = []ParameterStatusDto{
+		{Parameter: &nigoapi.ParameterEntity{Parameter: &nigoapi.ParameterDto{Name: name}}, Status: "NEW"},
+	}
+
+	url := nifiAddress(cluster, fmt.Sprintf("/parameter-providers/%s/parameters/fetch-requests", id))
+	httpmock.RegisterResponder(http.MethodPost, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				mockEntity)
+		})
+
+	return client.FetchParameterProviderParameters(ParameterProviderParameterFetchEntity{
+		Id:       id,
+		Revision: mockEntity.Revision,
+	})
+}
+
+func TestCreateParameterProviderApplyRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	id := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+
+	entity, err := testCreateParameterProviderApplyRequest(t, id, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+
+	entity, err = testCreateParameterProviderApplyRequest(t, id, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testCreateParameterProviderApplyRequest(t, id, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testCreateParameterProviderApplyRequest(t *testing.T, id string, status int) (*ParameterProviderApplyParametersRequestEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/parameter-providers/%s/apply-parameters-requests", id))
+	httpmock.RegisterResponder(http.MethodPost, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				MockParameterProviderApplyRequest("2d9c1a3e-0184-1000-0000-00003c5a1b2f", false))
+		})
+
+	var version int64 = 10
+	return client.CreateParameterProviderApplyRequest(ParameterProviderParameterApplicationEntity{
+		Id:       id,
+		Revision: &nigoapi.RevisionDto{Version: &version},
+		ParameterGroupConfigurations: []ParameterGroupConfigurationEntity{
+			{GroupName: "Environment Variables", ParameterContextName: "dataflow-params"},
+		},
+	})
+}
+
+func TestGetParameterProviderApplyRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	id := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+	requestId := "2d9c1a3e-0184-1000-0000-00003c5a1b2f"
+
+	entity, err := testGetParameterProviderApplyRequest(t, id, requestId, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+	assert.True(entity.Request.Complete)
+
+	entity, err = testGetParameterProviderApplyRequest(t, id, requestId, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testGetParameterProviderApplyRequest(t, id, requestId, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testGetParameterProviderApplyRequest(t *testing.T, id, requestId string, status int) (*ParameterProviderApplyParametersRequestEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/parameter-providers/%s/apply-parameters-requests/%s", id, requestId))
+	httpmock.RegisterResponder(http.MethodGet, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				MockParameterProviderApplyRequest(requestId, true))
+		})
+
+	return client.GetParameterProviderApplyRequest(id, requestId)
+}
+
+func MockParameterProvider(id, name, providerType string) ParameterProviderEntity {
+	var version int64 = 10
+	value := "/etc/nifi/params"
+	return ParameterProviderEntity{
+		Id: id,
+		Component: &ParameterProviderDto{
+			Id:         id,
+			Name:       name,
+			Type_:      providerType,
+			Properties: map[string]*string{"parameter-group-name": &value},
+		},
+		Revision: &nigoapi.RevisionDto{Version: &version},
+	}
+}
+
+func MockParameterProviderApplyRequest(requestId string, complete bool) ParameterProviderApplyParametersRequestEntity {
+	return ParameterProviderApplyParametersRequestEntity{
+		Request: &ParameterProviderApplyParametersRequestDto{
+			RequestId:        requestId,
+			Uri:              "http://uri.com:8888",
+			SubmissionTime:   "10/19/2022 08:24:36.000 UTC",
+			LastUpdated:      "10/19/2022 08:24:36.000 UTC",
+			Complete:         complete,
+			PercentCompleted: 100,
+			State:            "Applying parameters",
+		},
+	}
+}
@@ -78,3 +78,20 @@ func (n *nifiClient) RemoveProcessGroup(entity nigoapi.ProcessGroupEntity) error
 
 	return errorDeleteOperation(rsp, body, err)
 }
+
+func (n *nifiClient) GetLocalModifications(pgId string) (*nigoapi.FlowComparisonEntity, error) {
+	// Get nigoapi client, favoring the one associated to the coordinator node.
+	client, context := n.privilegeCoordinatorClient()
+	if client == nil {
+		log.Error(ErrNoNodeClientsAvailable, "Error during creating node client")
+		return nil, ErrNoNodeClientsAvailable
+	}
+
+	// Request on Nifi Rest API to get the local modifications of the versioned process group
+	flowComparisonEntity, rsp, body, err := client.ProcessGroupsApi.GetLocalModifications(context, pgId)
+	if err := errorGetOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &flowComparisonEntity, nil
+}
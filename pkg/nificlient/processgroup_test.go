@@ -199,6 +199,67 @@ func testRemoveProcessGroup(t *testing.T, entity *nigoapi.ProcessGroupEntity, st
 	return client.RemoveProcessGroup(*entity)
 }
 
+func TestGetLocalModifications(t *testing.T) {
+	assert := assert.New(t)
+
+	id := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+
+	entity, err := testGetLocalModifications(t, id, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+	assert.Equal(1, len(entity.ComponentDifferences))
+
+	entity, err = testGetLocalModifications(t, id, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testGetLocalModifications(t, id, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testGetLocalModifications(t *testing.T, id string, status int) (*nigoapi.FlowComparisonEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/process-groups/%s/local-modifications", id))
+	httpmock.RegisterResponder(http.MethodGet, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				MockFlowComparison(id, "16cfd2ec-0174-1050-0000-00004b9b35cc", "Processor"))
+		})
+
+	return client.GetLocalModifications(id)
+}
+
+func MockFlowComparison(pgId, componentId, componentType string) nigoapi.FlowComparisonEntity {
+	return nigoapi.FlowComparisonEntity{
+		ComponentDifferences: []nigoapi.ComponentDifferenceDto{
+			{
+				ComponentType:  componentType,
+				ComponentId:    componentId,
+				ComponentName:  "test-unit",
+				ProcessGroupId: pgId,
+				Differences: []nigoapi.DifferenceDto{
+					{
+						DifferenceType: "PROPERTY_CHANGED",
+						Difference:     "Property 'Batch Size' was changed from '1' to '10'",
+					},
+				},
+			},
+		},
+	}
+}
+
 func MockProcessGroup(id, name, parentPGId, registryId, bucketId, flowId string, flowVersion int32) nigoapi.ProcessGroupEntity {
 	var version int64 = 10
 	return nigoapi.ProcessGroupEntity{
@@ -10,6 +10,49 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestUpdateProcessor(t *testing.T) {
+	assert := assert.New(t)
+
+	id := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+
+	mockEntity := MockProcessor(id, "GenerateFlowFile", 4)
+
+	entity, err := testUpdateProcessor(t, mockEntity, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+
+	entity, err = testUpdateProcessor(t, mockEntity, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testUpdateProcessor(t, mockEntity, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testUpdateProcessor(t *testing.T, entity nigoapi.ProcessorEntity, status int) (*nigoapi.ProcessorEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/processors/%s", entity.Id))
+	httpmock.RegisterResponder(http.MethodPut, url,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(
+				status,
+				entity)
+		})
+
+	return client.UpdateProcessor(entity)
+}
+
 func TestUpdateProcessorRunStatus(t *testing.T) {
 	assert := assert.New(t)
 
@@ -60,3 +103,18 @@ func MockProcessorRunStatus(state string) nigoapi.ProcessorRunStatusEntity {
 		State:    state,
 	}
 }
+
+func MockProcessor(id, name string, concurrentTasks int32) nigoapi.ProcessorEntity {
+	var version int64 = 10
+	return nigoapi.ProcessorEntity{
+		Id:       id,
+		Revision: &nigoapi.RevisionDto{Version: &version},
+		Component: &nigoapi.ProcessorDto{
+			Id:   id,
+			Name: name,
+			Config: &nigoapi.ProcessorConfigDto{
+				ConcurrentlySchedulableTaskCount: concurrentTasks,
+			},
+		},
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package nificlient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	nigoapi "github.com/erdrix/nigoapi/pkg/nifi"
+)
+
+// callRawApi performs a JSON request on the Nifi Rest API, favoring the node coordinator, for the features which are
+// more recent than the nigoapi models. The response is decoded into result when the request succeeded.
+func (n *nifiClient) callRawApi(method, path string, payload interface{}, result interface{}) (*http.Response, *string, error) {
+	config, context := n.privilegeCoordinatorConfig()
+	if config == nil {
+		log.Error(ErrNoNodeClientsAvailable, "Error during creating node client")
+		return nil, nil, ErrNoNodeClientsAvailable
+	}
+
+	var reqBody io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, config.BasePath+path, reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", config.UserAgent)
+	if config.Host != "" {
+		req.Host = config.Host
+	}
+	for header, value := range config.DefaultHeader {
+		req.Header.Add(header, value)
+	}
+	if context != nil {
+		req = req.WithContext(context)
+		if token, ok := context.Value(nigoapi.ContextAccessToken).(string); ok {
+			req.Header.Add("Authorization", "Bearer "+token)
+		}
+	}
+
+	rsp, err := config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rsp.Body.Close()
+
+	b, err := ioutil.ReadAll(rsp.Body)
+	body := string(b)
+	if err != nil {
+		return rsp, &body, err
+	}
+
+	if rsp.StatusCode < 300 && result != nil {
+		err = json.Unmarshal(b, result)
+	}
+	return rsp, &body, err
+}
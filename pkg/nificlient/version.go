@@ -69,3 +69,20 @@ func (n *nifiClient) GetVersionRevertRequest(id string) (*nigoapi.VersionedFlowU
 
 	return &request, nil
 }
+
+func (n *nifiClient) CreateFlowVersion(pgId string, entity nigoapi.StartVersionControlRequestEntity) (*nigoapi.VersionControlInformationEntity, error) {
+	// Get nigoapi client, favoring the one associated to the coordinator node.
+	client, context := n.privilegeCoordinatorClient()
+	if client == nil {
+		log.Error(ErrNoNodeClientsAvailable, "Error during creating node client")
+		return nil, ErrNoNodeClientsAvailable
+	}
+
+	// Request on Nifi Rest API to save the process group as a new flow version into the registry
+	vciEntity, rsp, body, err := client.VersionsApi.SaveToFlowRegistry(context, pgId, entity)
+	if err := errorUpdateOperation(rsp, body, err); err != nil {
+		return nil, err
+	}
+
+	return &vciEntity, nil
+}
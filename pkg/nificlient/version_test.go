@@ -203,6 +203,71 @@ func testGetVersionRevertRequest(t *testing.T, entity *nigoapi.VersionControlInf
 
 }
 
+func TestCreateFlowVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	pgId := "16cfd2ec-0174-1000-0000-00004b9b35cc"
+
+	mockEntity := MockStartVersionControlRequest(
+		"16cfd2ec-0174-1450-0000-00004b9b35cc",
+		"16cfd2ec-0174-6580-0000-00004b9b35cc",
+		"16cfd2ec-0174-10546-0000-00004b9b35cc",
+		"unit test commit")
+
+	entity, err := testCreateFlowVersion(t, pgId, &mockEntity, 200)
+	assert.Nil(err)
+	assert.NotNil(entity)
+	assert.Equal(int32(6), entity.VersionControlInformation.Version)
+
+	entity, err = testCreateFlowVersion(t, pgId, &mockEntity, 404)
+	assert.IsType(ErrNifiClusterReturned404, err)
+	assert.Nil(entity)
+
+	entity, err = testCreateFlowVersion(t, pgId, &mockEntity, 500)
+	assert.IsType(ErrNifiClusterNotReturned200, err)
+	assert.Nil(entity)
+}
+
+func testCreateFlowVersion(t *testing.T, pgId string, entity *nigoapi.StartVersionControlRequestEntity, status int) (*nigoapi.VersionControlInformationEntity, error) {
+
+	cluster := testClusterMock(t)
+
+	client, err := testClientFromCluster(cluster, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	url := nifiAddress(cluster, fmt.Sprintf("/versions/process-groups/%s", pgId))
+	httpmock.RegisterResponder(http.MethodPost, url,
+		func(req *http.Request) (*http.Response, error) {
+			vf := entity.VersionedFlow
+			return httpmock.NewJsonResponse(
+				status,
+				MockVersionUpdateRequest(pgId, vf.RegistryId, vf.BucketId, vf.FlowId, 6))
+		})
+
+	return client.CreateFlowVersion(pgId, *entity)
+}
+
+func MockStartVersionControlRequest(registryId, bucketId, flowId, comments string) nigoapi.StartVersionControlRequestEntity {
+	var version int64 = 10
+	return nigoapi.StartVersionControlRequestEntity{
+		ProcessGroupRevision: &nigoapi.RevisionDto{
+			Version: &version,
+		},
+		VersionedFlow: &nigoapi.VersionedFlowDto{
+			RegistryId: registryId,
+			BucketId:   bucketId,
+			FlowId:     flowId,
+			Comments:   comments,
+			Action:     "COMMIT",
+		},
+	}
+}
+
 func MockVersionUpdateRequest(pgId, registryId, bucketId, flowId string, flowVersion int32) nigoapi.VersionControlInformationEntity {
 	var version int64 = 10
 	return nigoapi.VersionControlInformationEntity{
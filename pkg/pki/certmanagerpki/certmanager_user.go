@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
 	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
@@ -44,12 +45,12 @@ func (c *certManager) ReconcileUserCertificate(ctx context.Context, user *v1alph
 	var err error
 	var secret *corev1.Secret
 	// See if we have an existing certificate for this user already
-	_, err = c.getUserCertificate(ctx, user)
+	existing, err := c.getUserCertificate(ctx, user)
 
 	if err != nil && apierrors.IsNotFound(err) {
 		// the certificate does not exist, let's make one
-		// check if jks is required and create password for it
-		if user.Spec.IncludeJKS {
+		// check if a keystore is required and create password for it
+		if len(user.Spec.GetKeystoreFormats()) > 0 {
 			if err := c.injectJKSPassword(ctx, user); err != nil {
 				return nil, err
 			}
@@ -62,6 +63,21 @@ func (c *certManager) ReconcileUserCertificate(ctx context.Context, user *v1alph
 	} else if err != nil {
 		// API failure, requeue
 		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "failed looking up user certificate")
+	} else if desired := c.clusterCertificateForUser(user, scheme); !certificateSettingsEqual(existing, desired) {
+		// the subject, lifetime or keystores changed, cert-manager re-issues the certificate once updated
+		if len(user.Spec.GetKeystoreFormats()) > 0 {
+			if err := c.ensureKeystorePassword(ctx, user); err != nil {
+				return nil, err
+			}
+		}
+		existing.Spec.Subject = desired.Spec.Subject
+		existing.Spec.Organization = desired.Spec.Organization
+		existing.Spec.Duration = desired.Spec.Duration
+		existing.Spec.RenewBefore = desired.Spec.RenewBefore
+		existing.Spec.Keystores = desired.Spec.Keystores
+		if err = c.client.Update(ctx, existing); err != nil {
+			return nil, errorfactory.New(errorfactory.APIFailure{}, err, "could not update user certificate")
+		}
 	}
 
 	// Get the secret created from the certificate
@@ -103,6 +119,42 @@ func (c *certManager) injectJKSPassword(ctx context.Context, user *v1alpha1.Nifi
 	return nil
 }
 
+// ensureKeystorePassword ensures that the existing secret of a user contains the keystores password, it is created
+// with the password if cert-manager did not create it yet
+func (c *certManager) ensureKeystorePassword(ctx context.Context, user *v1alpha1.NifiUser) error {
+	secret := &corev1.Secret{}
+	err := c.client.Get(ctx, types.NamespacedName{Name: user.Spec.SecretName, Namespace: user.Namespace}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return c.injectJKSPassword(ctx, user)
+		}
+		return errorfactory.New(errorfactory.APIFailure{}, err, "failed to get user secret")
+	}
+
+	if _, ok := secret.Data[v1alpha1.PasswordKey]; ok {
+		return nil
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	if secret, err = certutil.EnsureSecretPassJKS(secret); err != nil {
+		return errorfactory.New(errorfactory.InternalError{}, err, "could not inject secret with keystore password")
+	}
+	if err = c.client.Update(ctx, secret); err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "could not update secret with keystore password")
+	}
+	return nil
+}
+
+// certificateSettingsEqual returns whether the subject, lifetime and keystores of the certificates are the same
+func certificateSettingsEqual(existing, desired *certv1.Certificate) bool {
+	return reflect.DeepEqual(existing.Spec.Subject, desired.Spec.Subject) &&
+		reflect.DeepEqual(existing.Spec.Organization, desired.Spec.Organization) &&
+		reflect.DeepEqual(existing.Spec.Duration, desired.Spec.Duration) &&
+		reflect.DeepEqual(existing.Spec.RenewBefore, desired.Spec.RenewBefore) &&
+		reflect.DeepEqual(existing.Spec.Keystores, desired.Spec.Keystores)
+}
+
 // ensureControllerReference ensures that a NifiUser owns a given Secret
 func (c *certManager) ensureControllerReference(ctx context.Context, user *v1alpha1.NifiUser, secret *corev1.Secret, scheme *runtime.Scheme) error {
 	err := controllerutil.SetControllerReference(user, secret, scheme)
@@ -133,13 +185,10 @@ func (c *certManager) getUserSecret(ctx context.Context, user *v1alpha1.NifiUser
 		}
 		return secret, errorfactory.New(errorfactory.APIFailure{}, err, "failed to get user secret")
 	}
-	if user.Spec.IncludeJKS {
-		if len(secret.Data) != 6 {
-			return secret, errorfactory.New(errorfactory.ResourceNotReady{}, err, "user secret not populated yet")
-		}
-	} else {
-		if len(secret.Data) != 3 {
-			return secret, errorfactory.New(errorfactory.ResourceNotReady{}, err, "user secret not populated yet")
+	for _, key := range certutil.UserSecretKeys(user) {
+		if _, ok := secret.Data[key]; !ok {
+			return secret, errorfactory.New(errorfactory.ResourceNotReady{},
+				fmt.Errorf("missing secret key %s", key), "user secret not populated yet")
 		}
 	}
 
@@ -165,6 +214,9 @@ func (c *certManager) clusterCertificateForUser(user *v1alpha1.NifiUser, scheme
 			SecretName:  user.Spec.SecretName,
 			KeyEncoding: certv1.PKCS8,
 			CommonName:  user.GetName(),
+			Subject:     certutil.X509Subject(user.Spec.Subject),
+			Duration:    user.Spec.Duration,
+			RenewBefore: user.Spec.RenewBefore,
 			URISANs:     []string{fmt.Sprintf(pkicommon.SpiffeIdTemplate, c.cluster.Name, user.GetNamespace(), user.GetName())},
 			Usages:      []certv1.KeyUsage{certv1.UsageClientAuth, certv1.UsageServerAuth},
 			IssuerRef: certmeta.ObjectReference{
@@ -173,17 +225,24 @@ func (c *certManager) clusterCertificateForUser(user *v1alpha1.NifiUser, scheme
 			},
 		},
 	}
-	if user.Spec.IncludeJKS {
-		cert.Spec.Keystores = &certv1.CertificateKeystores{
-			JKS: &certv1.JKSKeystore{
-				Create: true,
-				PasswordSecretRef: certmeta.SecretKeySelector{
-					LocalObjectReference: certmeta.LocalObjectReference{
-						Name: user.Spec.SecretName,
-					},
-					Key: v1alpha1.PasswordKey,
-				},
-			},
+	if user.Spec.Subject != nil && len(user.Spec.Subject.Organizations) > 0 {
+		cert.Spec.Organization = user.Spec.Subject.Organizations
+	}
+	passwordSecretRef := certmeta.SecretKeySelector{
+		LocalObjectReference: certmeta.LocalObjectReference{
+			Name: user.Spec.SecretName,
+		},
+		Key: v1alpha1.PasswordKey,
+	}
+	for _, format := range user.Spec.GetKeystoreFormats() {
+		if cert.Spec.Keystores == nil {
+			cert.Spec.Keystores = &certv1.CertificateKeystores{}
+		}
+		switch format {
+		case v1alpha1.JKSKeystoreFormat:
+			cert.Spec.Keystores.JKS = &certv1.JKSKeystore{Create: true, PasswordSecretRef: passwordSecretRef}
+		case v1alpha1.PKCS12KeystoreFormat:
+			cert.Spec.Keystores.PKCS12 = &certv1.PKCS12Keystore{Create: true, PasswordSecretRef: passwordSecretRef}
 		}
 	}
 	if user.Spec.DNSNames != nil && len(user.Spec.DNSNames) > 0 {
@@ -18,11 +18,13 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
 	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
 	certutil "github.com/Orange-OpenSource/nifikop/pkg/util/cert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
@@ -85,3 +87,33 @@ func TestReconcileUserCertificate(t *testing.T) {
 		t.Error("Expected  error, got nil")
 	}
 }
+
+func TestClusterCertificateForUser(t *testing.T) {
+	manager := newMock(newMockCluster())
+
+	user := newMockUser()
+	user.Spec.IncludeJKS = false
+	user.Spec.KeystoreFormats = []v1alpha1.KeystoreFormat{v1alpha1.PKCS12KeystoreFormat}
+	user.Spec.Subject = &v1alpha1.CertificateSubject{
+		Organizations:       []string{"test-o"},
+		OrganizationalUnits: []string{"test-ou"},
+	}
+	user.Spec.Duration = &metav1.Duration{Duration: 24 * time.Hour}
+
+	cert := manager.clusterCertificateForUser(user, scheme.Scheme)
+	if cert.Spec.CommonName != user.Name {
+		t.Error("Expected the user name as common name, got:", cert.Spec.CommonName)
+	}
+	if !reflect.DeepEqual(cert.Spec.Organization, []string{"test-o"}) {
+		t.Error("Expected the organizations of the subject, got:", cert.Spec.Organization)
+	}
+	if cert.Spec.Subject == nil || !reflect.DeepEqual(cert.Spec.Subject.OrganizationalUnits, []string{"test-ou"}) {
+		t.Error("Expected the organizational units of the subject, got:", cert.Spec.Subject)
+	}
+	if !reflect.DeepEqual(cert.Spec.Duration, user.Spec.Duration) {
+		t.Error("Expected the duration of the user, got:", cert.Spec.Duration)
+	}
+	if cert.Spec.Keystores == nil || cert.Spec.Keystores.PKCS12 == nil || cert.Spec.Keystores.JKS != nil {
+		t.Error("Expected only a PKCS12 keystore, got:", cert.Spec.Keystores)
+	}
+}
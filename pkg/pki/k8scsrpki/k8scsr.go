@@ -0,0 +1,48 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package k8scsrpki
+
+import (
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/util/pki"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// csrNameTemplate is the template used for the CertificateSigningRequest of a user, they are cluster scoped
+	// so the namespace of the user is part of their name
+	csrNameTemplate = "%s-%s"
+	// pendingKeyKey stores in the user secret the private key of a requested certificate until it is issued
+	pendingKeyKey = "pending.tls.key"
+	// userGenerationAnnotation records on a certificate signing request the generation of the user it was made for, a
+	// denied request is kept until the user spec changes
+	userGenerationAnnotation = "nifiusers.nifi.orange.com/generation"
+	// defaultCABundleConfigMap is the config map holding the kubernetes cluster CA, published in every namespace
+	defaultCABundleConfigMap = "kube-root-ca.crt"
+)
+
+type K8sCSR interface {
+	pki.Manager
+}
+
+// k8sCSR implements a PKIManager using kubernetes CertificateSigningRequests as the backend
+type k8sCSR struct {
+	client  client.Client
+	cluster *v1alpha1.NifiCluster
+}
+
+func New(client client.Client, cluster *v1alpha1.NifiCluster) K8sCSR {
+	return &k8sCSR{client: client, cluster: cluster}
+}
@@ -0,0 +1,153 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package k8scsrpki
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	pkicommon "github.com/Orange-OpenSource/nifikop/pkg/util/pki"
+	"github.com/go-logr/logr"
+	certv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (k *k8sCSR) FinalizePKI(ctx context.Context, logger logr.Logger) error {
+	logger.Info("Removing certificate signing requests and secrets")
+
+	// Safety check that we are actually doing something
+	if k.cluster.Spec.ListenersConfig.SSLSecrets == nil {
+		return nil
+	}
+
+	users := []*v1alpha1.NifiUser{pkicommon.ControllerUserForCluster(k.cluster)}
+	users = append(users, pkicommon.NodeUsersForCluster(k.cluster, []string{})...)
+
+	for _, user := range users {
+		// Delete the pending requests first so we don't accidentally recreate the
+		// secret after it gets deleted
+		if err := k.deleteUserCSR(ctx, user); err != nil {
+			return err
+		}
+
+		secret := &corev1.Secret{}
+		if err := k.client.Get(ctx, types.NamespacedName{Name: user.Spec.SecretName, Namespace: user.Namespace}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if err := k.client.Delete(ctx, secret); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (k *k8sCSR) ReconcilePKI(ctx context.Context, logger logr.Logger, scheme *runtime.Scheme, externalHostnames []string) error {
+	logger.Info("Reconciling k8s-csr PKI")
+
+	if _, err := k.getSignerName(); err != nil {
+		return err
+	}
+
+	// The node and operator certificates are requested by the NifiUser controller, using the kubernetes CA
+	// as signer there is no issuer to setup
+	users := []*v1alpha1.NifiUser{pkicommon.ControllerUserForCluster(k.cluster)}
+	users = append(users, pkicommon.NodeUsersForCluster(k.cluster, externalHostnames)...)
+
+	for _, user := range users {
+		if err := reconcileUser(ctx, k, user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileUser ensures a v1alpha1.NifiUser
+func reconcileUser(ctx context.Context, k *k8sCSR, user *v1alpha1.NifiUser) error {
+	obj := &v1alpha1.NifiUser{}
+	if err := k.client.Get(ctx, types.NamespacedName{Name: user.Name, Namespace: user.Namespace}, obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return k.client.Create(ctx, user)
+	}
+	return nil
+}
+
+// getSignerName returns the signer requested by the certificate signing requests of the cluster
+func (k *k8sCSR) getSignerName() (string, error) {
+	config := k.cluster.Spec.ListenersConfig.SSLSecrets.CSR
+	if config == nil || config.SignerName == "" {
+		return "", errorfactory.New(errorfactory.FatalReconcileError{},
+			errors.New("missing csr signer name"), "the k8s-csr pki backend requires sslSecrets.csr.signerName")
+	}
+	return config.SignerName, nil
+}
+
+// getCABundle returns the PEM bundle of the certificate authority of the signer
+func (k *k8sCSR) getCABundle(ctx context.Context) ([]byte, error) {
+	ref := corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: defaultCABundleConfigMap},
+		Key:                  v1alpha1.CoreCACertKey,
+	}
+	if config := k.cluster.Spec.ListenersConfig.SSLSecrets.CSR; config != nil && config.CABundleRef != nil {
+		ref = *config.CABundleRef
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := k.client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: k.cluster.Namespace}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errorfactory.New(errorfactory.ResourceNotReady{}, err, "ca bundle config map not found", "name", ref.Name)
+		}
+		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "failed to get ca bundle config map", "name", ref.Name)
+	}
+
+	caBundle, ok := configMap.Data[ref.Key]
+	if !ok || len(caBundle) == 0 {
+		return nil, errorfactory.New(errorfactory.ResourceNotReady{},
+			fmt.Errorf("missing config map key %s", ref.Key), "ca bundle not populated yet", "name", ref.Name)
+	}
+	return []byte(caBundle), nil
+}
+
+// deleteUserCSR removes the certificate signing request of a user if any
+func (k *k8sCSR) deleteUserCSR(ctx context.Context, user *v1alpha1.NifiUser) error {
+	csr := &certv1.CertificateSigningRequest{}
+	if err := k.client.Get(ctx, types.NamespacedName{Name: csrName(user)}, csr); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errorfactory.New(errorfactory.APIFailure{}, err, "failed to get user certificate signing request")
+	}
+	if err := k.client.Delete(ctx, csr); err != nil && !apierrors.IsNotFound(err) {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "could not delete user certificate signing request")
+	}
+	return nil
+}
+
+// csrName returns the name of the certificate signing request of a user
+func csrName(user *v1alpha1.NifiUser) string {
+	return fmt.Sprintf(csrNameTemplate, user.Namespace, user.Name)
+}
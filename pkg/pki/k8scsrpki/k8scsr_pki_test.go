@@ -0,0 +1,83 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package k8scsrpki
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	pkicommon "github.com/Orange-OpenSource/nifikop/pkg/util/pki"
+	certv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var log = ctrl.Log.WithName("testing")
+
+func TestFinalizePKI(t *testing.T) {
+	cluster := newMockCluster()
+	manager := newMock(cluster)
+	ctx := context.Background()
+
+	controller := pkicommon.ControllerUserForCluster(cluster)
+	secret := &corev1.Secret{}
+	secret.Name = controller.Spec.SecretName
+	secret.Namespace = controller.Namespace
+	manager.client.Create(ctx, secret)
+	csr := &certv1.CertificateSigningRequest{}
+	csr.Name = csrName(controller)
+	manager.client.Create(ctx, csr)
+
+	if err := manager.FinalizePKI(ctx, log); err != nil {
+		t.Error("Expected no error on finalize, got:", err)
+	}
+
+	if err := manager.client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret); !apierrors.IsNotFound(err) {
+		t.Error("Expected controller secret to be removed, got:", err)
+	}
+	if err := manager.client.Get(ctx, types.NamespacedName{Name: csr.Name}, csr); !apierrors.IsNotFound(err) {
+		t.Error("Expected controller certificate signing request to be removed, got:", err)
+	}
+}
+
+func TestReconcilePKI(t *testing.T) {
+	cluster := newMockCluster()
+	manager := newMock(cluster)
+	ctx := context.Background()
+
+	if err := manager.ReconcilePKI(ctx, log, scheme.Scheme, []string{}); err != nil {
+		t.Error("Expected successful reconcile, got:", err)
+	}
+
+	users := &v1alpha1.NifiUserList{}
+	if err := manager.client.List(ctx, users); err != nil {
+		t.Error("Expected to list users, got:", err)
+	} else if len(users.Items) != len(cluster.Spec.Nodes)+1 {
+		t.Error("Expected a user for each node and the controller, got:", len(users.Items))
+	}
+
+	cluster.Spec.ListenersConfig.SSLSecrets.CSR = nil
+	if err := manager.ReconcilePKI(ctx, log, scheme.Scheme, []string{}); err == nil {
+		t.Error("Expected error for missing signer name, got nil")
+	} else if reflect.TypeOf(err) != reflect.TypeOf(errorfactory.FatalReconcileError{}) {
+		t.Error("Expected fatal reconcile error, got:", reflect.TypeOf(err))
+	}
+}
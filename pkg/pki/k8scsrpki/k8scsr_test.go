@@ -0,0 +1,202 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package k8scsrpki
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	certutil "github.com/Orange-OpenSource/nifikop/pkg/util/cert"
+	certv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testSignerName = "example.com/nifi"
+
+type mockClient struct {
+	client.Client
+}
+
+func newMockCluster() *v1alpha1.NifiCluster {
+	cluster := &v1alpha1.NifiCluster{}
+	cluster.Name = "test"
+	cluster.Namespace = "test-namespace"
+	cluster.Spec = v1alpha1.NifiClusterSpec{}
+	cluster.Spec.ListenersConfig = &v1alpha1.ListenersConfig{}
+	cluster.Spec.ListenersConfig.InternalListeners = []v1alpha1.InternalListenerConfig{
+		{ContainerPort: 9092},
+	}
+	cluster.Spec.ListenersConfig.SSLSecrets = &v1alpha1.SSLSecrets{
+		PKIBackend: v1alpha1.PKIBackendK8sCSR,
+		CSR:        &v1alpha1.CSRConfig{SignerName: testSignerName},
+	}
+
+	cluster.Spec.Nodes = []v1alpha1.Node{
+		{Id: 0},
+		{Id: 1},
+		{Id: 2},
+	}
+	return cluster
+}
+
+func newMock(cluster *v1alpha1.NifiCluster) *k8sCSR {
+	v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	return &k8sCSR{
+		cluster: cluster,
+		client:  fake.NewFakeClientWithScheme(scheme.Scheme),
+	}
+}
+
+// fakeSigner issues the certificates requested to its signer name, as the controller of a kubernetes signer would
+type fakeSigner struct {
+	ca    *x509.Certificate
+	caKey interface{}
+	caPEM []byte
+}
+
+func newFakeSigner(t *testing.T) *fakeSigner {
+	cert, key, _, err := certutil.GenerateTestCert()
+	if err != nil {
+		t.Fatal("Failed to generate test CA:", err)
+	}
+	ca, _ := certutil.DecodeCertificate(cert)
+	block, _ := pem.Decode(key)
+	caKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal("Failed to decode test CA key:", err)
+	}
+	return &fakeSigner{ca: ca, caKey: caKey, caPEM: cert}
+}
+
+// caBundle returns the kube-root-ca.crt config map holding the CA of the signer
+func (s *fakeSigner) caBundle(namespace string) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{}
+	configMap.Name = defaultCABundleConfigMap
+	configMap.Namespace = namespace
+	configMap.Data = map[string]string{v1alpha1.CoreCACertKey: string(s.caPEM)}
+	return configMap
+}
+
+// sign approves and issues the certificate signing request with the given name, valid for the given lifetime
+func (s *fakeSigner) sign(t *testing.T, c client.Client, name string, lifetime time.Duration) {
+	ctx := context.Background()
+	csr := &certv1.CertificateSigningRequest{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, csr); err != nil {
+		t.Fatal("Expected certificate signing request, got error:", err)
+	}
+	if csr.Spec.SignerName != testSignerName {
+		t.Fatal("Expected signer name", testSignerName, "got:", csr.Spec.SignerName)
+	}
+
+	block, _ := pem.Decode(csr.Spec.Request)
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal("Expected valid certificate request, got error:", err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      request.Subject,
+		DNSNames:     request.DNSNames,
+		URIs:         request.URIs,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(lifetime),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, s.ca, request.PublicKey, s.caKey)
+	if err != nil {
+		t.Fatal("Failed to sign certificate request:", err)
+	}
+
+	csr.Status.Conditions = append(csr.Status.Conditions, certv1.CertificateSigningRequestCondition{
+		Type:   certv1.CertificateApproved,
+		Status: corev1.ConditionTrue,
+		Reason: "Test",
+	})
+	csr.Status.Certificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err = c.Status().Update(ctx, csr); err != nil {
+		t.Fatal("Failed to issue certificate:", err)
+	}
+}
+
+// deny denies the certificate signing request with the given name
+func (s *fakeSigner) deny(t *testing.T, c client.Client, name string) {
+	ctx := context.Background()
+	csr := &certv1.CertificateSigningRequest{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, csr); err != nil {
+		t.Fatal("Expected certificate signing request, got error:", err)
+	}
+	csr.Status.Conditions = append(csr.Status.Conditions, certv1.CertificateSigningRequestCondition{
+		Type:    certv1.CertificateDenied,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Test",
+		Message: "denied by test",
+	})
+	if err := c.Status().Update(ctx, csr); err != nil {
+		t.Fatal("Failed to deny certificate signing request:", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	pkiManager := New(&mockClient{}, newMockCluster())
+	if reflect.TypeOf(pkiManager) != reflect.TypeOf(&k8sCSR{}) {
+		t.Error("Expected new k8sCSR from New, got:", reflect.TypeOf(pkiManager))
+	}
+}
+
+func TestGetCABundle(t *testing.T) {
+	cluster := newMockCluster()
+	manager := newMock(cluster)
+	ctx := context.Background()
+
+	if _, err := manager.getCABundle(ctx); err == nil {
+		t.Error("Expected error for missing ca bundle, got nil")
+	}
+
+	signer := newFakeSigner(t)
+	manager.client.Create(ctx, signer.caBundle(cluster.Namespace))
+	if caBundle, err := manager.getCABundle(ctx); err != nil {
+		t.Error("Expected ca bundle, got error:", err)
+	} else if !bytes.Equal(caBundle, signer.caPEM) {
+		t.Error("Expected ca bundle of the signer, got:", string(caBundle))
+	}
+
+	configMap := &corev1.ConfigMap{}
+	configMap.Name = "custom-ca"
+	configMap.Namespace = cluster.Namespace
+	configMap.Data = map[string]string{"bundle.pem": "custom"}
+	manager.client.Create(ctx, configMap)
+	cluster.Spec.ListenersConfig.SSLSecrets.CSR.CABundleRef = &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "custom-ca"},
+		Key:                  "bundle.pem",
+	}
+	if caBundle, err := manager.getCABundle(ctx); err != nil {
+		t.Error("Expected ca bundle, got error:", err)
+	} else if string(caBundle) != "custom" {
+		t.Error("Expected referenced ca bundle, got:", string(caBundle))
+	}
+}
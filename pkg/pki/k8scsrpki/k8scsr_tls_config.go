@@ -0,0 +1,33 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package k8scsrpki
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/pki/certmanagerpki"
+	pkicommon "github.com/Orange-OpenSource/nifikop/pkg/util/pki"
+)
+
+// GetControllerTLSConfig creates a TLS config from the user secret created for
+// manager operations, it has the same layout as the cert-manager one
+func (k *k8sCSR) GetControllerTLSConfig() (*tls.Config, error) {
+	return certmanagerpki.GetControllerTLSConfigFromSecret(k.client, v1alpha1.SecretReference{
+		Namespace: k.cluster.Namespace,
+		Name:      fmt.Sprintf(pkicommon.NodeControllerTemplate, k.cluster.Name),
+	})
+}
@@ -0,0 +1,335 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package k8scsrpki
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	certutil "github.com/Orange-OpenSource/nifikop/pkg/util/cert"
+	pkicommon "github.com/Orange-OpenSource/nifikop/pkg/util/pki"
+	certv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// FinalizeUserCertificate for k8s-csr backend removes the pending certificate signing request, the secret is
+// cleaned up by its controller reference
+func (k *k8sCSR) FinalizeUserCertificate(ctx context.Context, user *v1alpha1.NifiUser) error {
+	return k.deleteUserCSR(ctx, user)
+}
+
+// ReconcileUserCertificate ensures a certificate/secret combination using a kubernetes certificate signing request,
+// the certificate is requested again when it is due for renewal or its subject changed
+func (k *k8sCSR) ReconcileUserCertificate(ctx context.Context, user *v1alpha1.NifiUser, scheme *runtime.Scheme) (*pkicommon.UserCertificate, error) {
+	signerName, err := k.getSignerName()
+	if err != nil {
+		return nil, err
+	}
+	if user.Spec.HasKeystoreFormat(v1alpha1.PKCS12KeystoreFormat) {
+		return nil, errorfactory.New(errorfactory.FatalReconcileError{},
+			errors.New("unsupported keystore format"), "the k8s-csr pki backend does not support pkcs12 keystores")
+	}
+	if user.Spec.Duration != nil {
+		return nil, errorfactory.New(errorfactory.FatalReconcileError{},
+			errors.New("unsupported certificate duration"),
+			"the k8s-csr pki backend does not support the duration, the certificate lifetime is set by the signer")
+	}
+
+	caBundle, err := k.getCABundle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := k.getUserSecret(ctx, user, scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	var issued []byte
+	if requiresIssuance(secret, user) {
+		// the private key of the request is kept in the secret until the certificate is issued, the current
+		// certificate remains in use meanwhile
+		if issued, err = k.requestCertificate(ctx, user, secret, signerName); err != nil {
+			return nil, err
+		}
+	}
+
+	desired := secret.DeepCopy()
+	if issued != nil {
+		desired.Data[corev1.TLSCertKey] = issued
+		desired.Data[corev1.TLSPrivateKeyKey] = secret.Data[pendingKeyKey]
+		delete(desired.Data, pendingKeyKey)
+	}
+	if err = populateSecret(user, desired, caBundle, issued != nil); err != nil {
+		return nil, err
+	}
+
+	if !reflect.DeepEqual(secret.Data, desired.Data) {
+		if err = k.saveSecret(ctx, desired); err != nil {
+			return nil, err
+		}
+	}
+
+	if issued != nil {
+		if err = k.deleteUserCSR(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return &pkicommon.UserCertificate{
+		CA:          desired.Data[v1alpha1.CoreCACertKey],
+		Certificate: desired.Data[corev1.TLSCertKey],
+		Key:         desired.Data[corev1.TLSPrivateKeyKey],
+	}, nil
+}
+
+// requestCertificate ensures a certificate signing request for the pending key of the user secret and returns the
+// certificate once issued
+func (k *k8sCSR) requestCertificate(ctx context.Context, user *v1alpha1.NifiUser, secret *corev1.Secret, signerName string) ([]byte, error) {
+	var err error
+	key := secret.Data[pendingKeyKey]
+	if len(key) == 0 {
+		if key, err = certutil.GeneratePrivateKey(); err != nil {
+			return nil, errorfactory.New(errorfactory.InternalError{}, err, "could not generate user private key")
+		}
+		secret.Data[pendingKeyKey] = key
+		if err = k.saveSecret(ctx, secret); err != nil {
+			return nil, err
+		}
+	}
+
+	desired, err := k.csrForUser(user, key, signerName)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.InternalError{}, err, "could not generate user certificate signing request")
+	}
+
+	csr := &certv1.CertificateSigningRequest{}
+	err = k.client.Get(ctx, types.NamespacedName{Name: desired.Name}, csr)
+	if err != nil && apierrors.IsNotFound(err) {
+		if err = k.client.Create(ctx, desired); err != nil {
+			return nil, errorfactory.New(errorfactory.APIFailure{}, err, "could not create user certificate signing request")
+		}
+		return nil, errorfactory.New(errorfactory.ResourceNotReady{},
+			errors.New("certificate signing request created"), "waiting for the certificate signing request to be approved")
+	} else if err != nil {
+		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "failed looking up user certificate signing request")
+	}
+
+	// the spec of a request is immutable, it is replaced when made for another key, subject or signer
+	if !bytes.Equal(csr.Spec.Request, desired.Spec.Request) || csr.Spec.SignerName != desired.Spec.SignerName {
+		if err = k.deleteUserCSR(ctx, user); err != nil {
+			return nil, err
+		}
+		return nil, errorfactory.New(errorfactory.ResourceNotReady{},
+			errors.New("certificate signing request outdated"), "replacing the user certificate signing request")
+	}
+
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == certv1.CertificateDenied || condition.Type == certv1.CertificateFailed {
+			// the denial remains reported until the user spec changes, or the request is removed, so it is not
+			// requested again on every reconciliation
+			if csr.Annotations[userGenerationAnnotation] == desired.Annotations[userGenerationAnnotation] {
+				return nil, errorfactory.New(errorfactory.FatalReconcileError{},
+					fmt.Errorf("%s: %s", condition.Reason, condition.Message),
+					fmt.Sprintf("user certificate signing request %s", condition.Type))
+			}
+			if err = k.deleteUserCSR(ctx, user); err != nil {
+				return nil, err
+			}
+			return nil, errorfactory.New(errorfactory.ResourceNotReady{},
+				fmt.Errorf("certificate signing request %s", condition.Type), "replacing the user certificate signing request")
+		}
+	}
+
+	if len(csr.Status.Certificate) == 0 {
+		return nil, errorfactory.New(errorfactory.ResourceNotReady{},
+			errors.New("certificate not issued yet"), "waiting for the certificate signing request to be issued")
+	}
+	if _, err = certutil.DecodeCertificate(csr.Status.Certificate); err != nil {
+		return nil, errorfactory.New(errorfactory.InternalError{}, err, "could not decode issued user certificate")
+	}
+
+	return csr.Status.Certificate, nil
+}
+
+// requiresIssuance returns whether a certificate must be requested for the user secret, because it has none, a
+// renewal is pending, it is due for renewal or its subject does not match the user anymore
+func requiresIssuance(secret *corev1.Secret, user *v1alpha1.NifiUser) bool {
+	if _, ok := secret.Data[pendingKeyKey]; ok {
+		return true
+	}
+	cert, err := certutil.DecodeCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return true
+	}
+
+	// like cert-manager, renew when two thirds of the lifetime are elapsed unless specified
+	renewBefore := cert.NotAfter.Sub(cert.NotBefore) / 3
+	if user.Spec.RenewBefore != nil {
+		renewBefore = user.Spec.RenewBefore.Duration
+	}
+	if time.Now().After(cert.NotAfter.Add(-renewBefore)) {
+		return true
+	}
+
+	return !certificateMatches(cert, user)
+}
+
+// certificateMatches returns whether the subject and dns names of the certificate are the ones of the user
+func certificateMatches(cert *x509.Certificate, user *v1alpha1.NifiUser) bool {
+	subject := subjectForUser(user)
+	return cert.Subject.CommonName == subject.CommonName &&
+		stringsEqual(cert.Subject.Organization, subject.Organization) &&
+		stringsEqual(cert.Subject.OrganizationalUnit, subject.OrganizationalUnit) &&
+		stringsEqual(cert.Subject.Country, subject.Country) &&
+		stringsEqual(cert.DNSNames, user.Spec.DNSNames)
+}
+
+// stringsEqual compares two lists of strings regardless of their order
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	return reflect.DeepEqual(sortedA, sortedB)
+}
+
+// populateSecret sets the CA bundle, keystores and their password in the user secret, the keystores are generated
+// again when the certificate or the CA bundle changed
+func populateSecret(user *v1alpha1.NifiUser, secret *corev1.Secret, caBundle []byte, issued bool) error {
+	stale := issued || !bytes.Equal(secret.Data[v1alpha1.CoreCACertKey], caBundle)
+	secret.Data[v1alpha1.CoreCACertKey] = caBundle
+
+	if len(user.Spec.GetKeystoreFormats()) == 0 {
+		return nil
+	}
+
+	injected, err := certutil.EnsureSecretPassJKS(secret)
+	if err != nil {
+		return errorfactory.New(errorfactory.InternalError{}, err, "could not inject secret with keystore password")
+	}
+	secret.Data = injected.Data
+
+	if _, ok := secret.Data[v1alpha1.TLSJKSKeyStore]; ok && !stale {
+		return nil
+	}
+	keyStore, trustStore, err := certutil.GenerateJKSStores(secret.Data[corev1.TLSCertKey],
+		secret.Data[corev1.TLSPrivateKeyKey], caBundle, secret.Data[v1alpha1.PasswordKey])
+	if err != nil {
+		return errorfactory.New(errorfactory.InternalError{}, err, "could not generate user keystores")
+	}
+	secret.Data[v1alpha1.TLSJKSKeyStore] = keyStore
+	secret.Data[v1alpha1.TLSJKSTrustStore] = trustStore
+	return nil
+}
+
+// getUserSecret fetches the secret of a user, or prepares it owned by the user when it does not exist yet
+func (k *k8sCSR) getUserSecret(ctx context.Context, user *v1alpha1.NifiUser, scheme *runtime.Scheme) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := k.client.Get(ctx, types.NamespacedName{Name: user.Spec.SecretName, Namespace: user.Namespace}, secret)
+	if err != nil && apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      user.Spec.SecretName,
+				Namespace: user.Namespace,
+			},
+			Data: map[string][]byte{},
+		}
+		if err = controllerutil.SetControllerReference(user, secret, scheme); err != nil {
+			return nil, errorfactory.New(errorfactory.InternalError{}, err, "error setting controller reference on user secret")
+		}
+		return secret, nil
+	} else if err != nil {
+		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "failed to get user secret")
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	return secret, nil
+}
+
+// saveSecret creates or updates the secret of a user
+func (k *k8sCSR) saveSecret(ctx context.Context, secret *corev1.Secret) error {
+	if secret.ResourceVersion == "" {
+		if err := k.client.Create(ctx, secret); err != nil {
+			return errorfactory.New(errorfactory.APIFailure{}, err, "could not create user secret")
+		}
+		return nil
+	}
+	if err := k.client.Update(ctx, secret); err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "could not update user secret")
+	}
+	return nil
+}
+
+// csrForUser generates a CertificateSigningRequest object for a NifiUser and the given private key
+func (k *k8sCSR) csrForUser(user *v1alpha1.NifiUser, key []byte, signerName string) (*certv1.CertificateSigningRequest, error) {
+	spiffeId, err := url.Parse(fmt.Sprintf(pkicommon.SpiffeIdTemplate, k.cluster.Name, user.GetNamespace(), user.GetName()))
+	if err != nil {
+		return nil, err
+	}
+	request, err := certutil.GenerateCertificateRequest(key, subjectForUser(user), user.Spec.DNSNames, []*url.URL{spiffeId})
+	if err != nil {
+		return nil, err
+	}
+	return &certv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        csrName(user),
+			Labels:      pkicommon.LabelsForNifiPKI(k.cluster.Name),
+			Annotations: map[string]string{userGenerationAnnotation: strconv.FormatInt(user.Generation, 10)},
+		},
+		Spec: certv1.CertificateSigningRequestSpec{
+			Request:    request,
+			SignerName: signerName,
+			Usages: []certv1.KeyUsage{
+				certv1.UsageDigitalSignature,
+				certv1.UsageKeyEncipherment,
+				certv1.UsageClientAuth,
+				certv1.UsageServerAuth,
+			},
+		},
+	}, nil
+}
+
+// subjectForUser returns the subject requested for the certificate of a user
+func subjectForUser(user *v1alpha1.NifiUser) pkix.Name {
+	subject := pkix.Name{CommonName: user.GetName()}
+	if user.Spec.Subject != nil {
+		subject.Organization = user.Spec.Subject.Organizations
+		subject.OrganizationalUnit = user.Spec.Subject.OrganizationalUnits
+		subject.Country = user.Spec.Subject.Countries
+	}
+	return subject
+}
@@ -0,0 +1,244 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package k8scsrpki
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	certutil "github.com/Orange-OpenSource/nifikop/pkg/util/cert"
+	certv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func newMockUser() *v1alpha1.NifiUser {
+	user := &v1alpha1.NifiUser{}
+	user.Name = "test-user"
+	user.Namespace = "test-namespace"
+	user.Spec = v1alpha1.NifiUserSpec{SecretName: "test-secret", IncludeJKS: true, DNSNames: []string{"test-user.example.com"}}
+	return user
+}
+
+func getUserSecret(t *testing.T, manager *k8sCSR, user *v1alpha1.NifiUser) *corev1.Secret {
+	secret := &corev1.Secret{}
+	if err := manager.client.Get(context.Background(), types.NamespacedName{Name: user.Spec.SecretName, Namespace: user.Namespace}, secret); err != nil {
+		t.Fatal("Expected user secret, got error:", err)
+	}
+	return secret
+}
+
+func expectNotReady(t *testing.T, err error) {
+	if err == nil {
+		t.Fatal("Expected not ready error, got nil")
+	} else if reflect.TypeOf(err) != reflect.TypeOf(errorfactory.ResourceNotReady{}) {
+		t.Fatal("Expected not ready error, got:", reflect.TypeOf(err), err)
+	}
+}
+
+func TestFinalizeUserCertificate(t *testing.T) {
+	manager := newMock(newMockCluster())
+	if err := manager.FinalizeUserCertificate(context.Background(), newMockUser()); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+}
+
+func TestReconcileUserCertificate(t *testing.T) {
+	cluster := newMockCluster()
+	manager := newMock(cluster)
+	signer := newFakeSigner(t)
+	user := newMockUser()
+	ctx := context.Background()
+
+	// the CA bundle of the signer is required
+	_, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	expectNotReady(t, err)
+	manager.client.Create(ctx, signer.caBundle(cluster.Namespace))
+
+	// a certificate signing request is created for a new private key
+	_, err = manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	expectNotReady(t, err)
+	secret := getUserSecret(t, manager, user)
+	if _, ok := secret.Data[pendingKeyKey]; !ok {
+		t.Error("Expected pending private key in user secret")
+	}
+	if !metav1.IsControlledBy(secret, user) {
+		t.Error("Expected user secret to be controlled by the user")
+	}
+
+	// waiting for the certificate to be issued
+	_, err = manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	expectNotReady(t, err)
+
+	signer.sign(t, manager.client, csrName(user), 90*24*time.Hour)
+	cert, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	if err != nil {
+		t.Fatal("Expected user certificate, got error:", err)
+	}
+	if cert.DN() != "CN=test-user" {
+		t.Error("Expected certificate for the user, got:", cert.DN())
+	}
+
+	secret = getUserSecret(t, manager, user)
+	for _, key := range certutil.UserSecretKeys(user) {
+		if len(secret.Data[key]) == 0 {
+			t.Error("Expected user secret to contain", key)
+		}
+	}
+	if _, ok := secret.Data[pendingKeyKey]; ok {
+		t.Error("Expected pending private key to be removed from user secret")
+	}
+	if !bytes.Equal(secret.Data[v1alpha1.CoreCACertKey], signer.caPEM) {
+		t.Error("Expected ca bundle of the signer in user secret")
+	}
+	csr := &certv1.CertificateSigningRequest{}
+	if err = manager.client.Get(ctx, types.NamespacedName{Name: csrName(user)}, csr); !apierrors.IsNotFound(err) {
+		t.Error("Expected issued certificate signing request to be removed, got:", err)
+	}
+
+	// an issued certificate is kept as is
+	if _, err = manager.ReconcileUserCertificate(ctx, user, scheme.Scheme); err != nil {
+		t.Error("Expected user certificate, got error:", err)
+	}
+	if unchanged := getUserSecret(t, manager, user); !reflect.DeepEqual(unchanged.Data, secret.Data) {
+		t.Error("Expected user secret to be unchanged")
+	}
+}
+
+func TestReconcileUserCertificateRenewal(t *testing.T) {
+	cluster := newMockCluster()
+	manager := newMock(cluster)
+	signer := newFakeSigner(t)
+	user := newMockUser()
+	ctx := context.Background()
+	manager.client.Create(ctx, signer.caBundle(cluster.Namespace))
+
+	manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	signer.sign(t, manager.client, csrName(user), time.Hour)
+	if _, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme); err != nil {
+		t.Fatal("Expected user certificate, got error:", err)
+	}
+	issued := getUserSecret(t, manager, user)
+
+	// not yet in the renewal window
+	user.Spec.RenewBefore = &metav1.Duration{Duration: time.Minute}
+	if _, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme); err != nil {
+		t.Fatal("Expected user certificate, got error:", err)
+	}
+
+	// in the renewal window the current certificate is kept until the new one is issued
+	user.Spec.RenewBefore = &metav1.Duration{Duration: 2 * time.Hour}
+	_, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	expectNotReady(t, err)
+	pending := getUserSecret(t, manager, user)
+	if !bytes.Equal(pending.Data[corev1.TLSCertKey], issued.Data[corev1.TLSCertKey]) {
+		t.Error("Expected current certificate to be kept during renewal")
+	}
+
+	signer.sign(t, manager.client, csrName(user), 90*24*time.Hour)
+	if _, err = manager.ReconcileUserCertificate(ctx, user, scheme.Scheme); err != nil {
+		t.Fatal("Expected renewed user certificate, got error:", err)
+	}
+	renewed := getUserSecret(t, manager, user)
+	if bytes.Equal(renewed.Data[corev1.TLSCertKey], issued.Data[corev1.TLSCertKey]) ||
+		bytes.Equal(renewed.Data[corev1.TLSPrivateKeyKey], issued.Data[corev1.TLSPrivateKeyKey]) {
+		t.Error("Expected renewed certificate and private key")
+	}
+	if !bytes.Equal(renewed.Data[v1alpha1.PasswordKey], issued.Data[v1alpha1.PasswordKey]) {
+		t.Error("Expected keystore password to be kept")
+	}
+
+	// a change of the dns names requests a new certificate
+	user.Spec.DNSNames = append(user.Spec.DNSNames, "other.example.com")
+	_, err = manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	expectNotReady(t, err)
+}
+
+func TestReconcileUserCertificateDenied(t *testing.T) {
+	cluster := newMockCluster()
+	manager := newMock(cluster)
+	signer := newFakeSigner(t)
+	user := newMockUser()
+	ctx := context.Background()
+	manager.client.Create(ctx, signer.caBundle(cluster.Namespace))
+
+	manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	signer.deny(t, manager.client, csrName(user))
+	if _, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme); err == nil {
+		t.Fatal("Expected error for denied request, got nil")
+	} else if reflect.TypeOf(err) != reflect.TypeOf(errorfactory.FatalReconcileError{}) {
+		t.Fatal("Expected fatal reconcile error, got:", reflect.TypeOf(err))
+	}
+
+	// the denied request is kept and reported until the user spec changes
+	_, err := manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	if reflect.TypeOf(err) != reflect.TypeOf(errorfactory.FatalReconcileError{}) {
+		t.Fatal("Expected fatal reconcile error, got:", reflect.TypeOf(err))
+	}
+	csr := &certv1.CertificateSigningRequest{}
+	if err = manager.client.Get(ctx, types.NamespacedName{Name: csrName(user)}, csr); err != nil {
+		t.Fatal("Expected denied certificate signing request to be kept, got error:", err)
+	} else if len(csr.Status.Conditions) == 0 {
+		t.Error("Expected denied certificate signing request to keep its conditions")
+	}
+
+	// the request is made again once the user spec changed
+	user.Generation++
+	_, err = manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	expectNotReady(t, err)
+	if err = manager.client.Get(ctx, types.NamespacedName{Name: csrName(user)}, csr); !apierrors.IsNotFound(err) {
+		t.Error("Expected denied certificate signing request to be removed, got:", err)
+	}
+	_, err = manager.ReconcileUserCertificate(ctx, user, scheme.Scheme)
+	expectNotReady(t, err)
+	csr = &certv1.CertificateSigningRequest{}
+	if err = manager.client.Get(ctx, types.NamespacedName{Name: csrName(user)}, csr); err != nil {
+		t.Error("Expected new certificate signing request, got error:", err)
+	} else if len(csr.Status.Conditions) != 0 {
+		t.Error("Expected new certificate signing request without conditions, got:", csr.Status.Conditions)
+	}
+}
+
+func TestReconcileUserCertificatePKCS12(t *testing.T) {
+	manager := newMock(newMockCluster())
+	user := newMockUser()
+	user.Spec.KeystoreFormats = []v1alpha1.KeystoreFormat{v1alpha1.PKCS12KeystoreFormat}
+
+	if _, err := manager.ReconcileUserCertificate(context.Background(), user, scheme.Scheme); err == nil {
+		t.Error("Expected error for pkcs12 keystore, got nil")
+	} else if reflect.TypeOf(err) != reflect.TypeOf(errorfactory.FatalReconcileError{}) {
+		t.Error("Expected fatal reconcile error, got:", reflect.TypeOf(err))
+	}
+}
+
+func TestReconcileUserCertificateDuration(t *testing.T) {
+	manager := newMock(newMockCluster())
+	user := newMockUser()
+	user.Spec.Duration = &metav1.Duration{Duration: 24 * time.Hour}
+
+	if _, err := manager.ReconcileUserCertificate(context.Background(), user, scheme.Scheme); err == nil {
+		t.Error("Expected error for certificate duration, got nil")
+	} else if reflect.TypeOf(err) != reflect.TypeOf(errorfactory.FatalReconcileError{}) {
+		t.Error("Expected fatal reconcile error, got:", reflect.TypeOf(err))
+	}
+}
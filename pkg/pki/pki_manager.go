@@ -20,6 +20,7 @@ import (
 
 	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
 	"github.com/Orange-OpenSource/nifikop/pkg/pki/certmanagerpki"
+	"github.com/Orange-OpenSource/nifikop/pkg/pki/k8scsrpki"
 	"github.com/Orange-OpenSource/nifikop/pkg/util/pki"
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -37,6 +38,10 @@ func GetPKIManager(client client.Client, cluster *v1alpha1.NifiCluster) pki.Mana
 	case v1alpha1.PKIBackendCertManager:
 		return certmanagerpki.New(client, cluster)
 
+	// Use kubernetes certificate signing requests for pki backend
+	case v1alpha1.PKIBackendK8sCSR:
+		return k8scsrpki.New(client, cluster)
+
 	// TODO : Add vault
 	// Use vault for pki backend
 	/*case v1alpha1.PKIBackendVault:
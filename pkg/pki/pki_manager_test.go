@@ -85,6 +85,14 @@ func TestGetPKIManager(t *testing.T) {
 		t.Error("Expected:", expected, "got:", pkiType)
 	}
 
+	cluster.Spec.ListenersConfig.SSLSecrets.PKIBackend = v1alpha1.PKIBackendK8sCSR
+	k8scsr := GetPKIManager(&mockClient{}, cluster)
+	pkiType = reflect.TypeOf(k8scsr).String()
+	expected = "*k8scsrpki.k8sCSR"
+	if pkiType != expected {
+		t.Error("Expected:", expected, "got:", pkiType)
+	}
+
 	// Default should be cert-manager also
 	cluster.Spec.ListenersConfig.SSLSecrets.PKIBackend = v1alpha1.PKIBackend("")
 	certmanager = GetPKIManager(&mockClient{}, cluster)
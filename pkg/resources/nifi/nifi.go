@@ -16,10 +16,14 @@ package nifi
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/dataflow"
 	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/scale"
+	"github.com/Orange-OpenSource/nifikop/pkg/clientwrappers/tenants"
 	"github.com/Orange-OpenSource/nifikop/pkg/nificlient/config"
+	"github.com/Orange-OpenSource/nifikop/pkg/nificlient/config/basic"
 	"github.com/Orange-OpenSource/nifikop/pkg/pki"
 	nifiutil "github.com/Orange-OpenSource/nifikop/pkg/util/nifi"
 	"reflect"
@@ -39,6 +43,7 @@ import (
 	pkicommon "github.com/Orange-OpenSource/nifikop/pkg/util/pki"
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	"github.com/go-logr/logr"
+	"golang.org/x/crypto/bcrypt"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -58,8 +63,34 @@ const (
 	serverKeystorePath   = "/var/run/secrets/java.io/keystores/server"
 	clientKeystoreVolume = "client-ks-files"
 	clientKeystorePath   = "/var/run/secrets/java.io/keystores/client"
+
+	repositoryKeystoreVolume = "repository-ks-files"
+	repositoryKeystorePath   = "/var/run/secrets/java.io/keystores/repository"
+	repositoryKeystoreFile   = "repository.p12"
+
+	trustedCAsVolume         = "trusted-cas-files"
+	trustedCAsPath           = "/var/run/secrets/java.io/keystores/trusted-cas"
+	trustedCABundleVolume    = "trusted-ca-bundle"
+	trustedCABundlesPath     = "/var/run/secrets/trusted-cas"
+	trustedCABundleFile      = "ca.pem"
+	trustedCAsHashAnnotation = "nificlusters.nifi.orange.com/additional-trusted-cas-hash"
+
+	sensitivePropertiesKeyProperty       = "nifi.sensitive.props.key"
+	sensitivePropertiesAlgorithmProperty = "nifi.sensitive.props.algorithm"
+	// legacySensitivePropertiesKey is the key used by NiFi before 1.14 when the sensitive properties key is blank
+	legacySensitivePropertiesKey = "nififtw!"
+	// minSensitivePropertiesKeyLength is the minimum length of the sensitive properties key required by NiFi
+	minSensitivePropertiesKeyLength = 12
 )
 
+// repositoryImplementationProperties are the nifi.properties defining the implementation of the node repositories
+var repositoryImplementationProperties = []string{
+	"nifi.flowfile.repository.implementation",
+	"nifi.flowfile.repository.wal.implementation",
+	"nifi.content.repository.implementation",
+	"nifi.provenance.repository.implementation",
+}
+
 // Reconciler implements the Component Reconciler
 type Reconciler struct {
 	resources.Reconciler
@@ -142,6 +173,27 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
+	sensitiveProps, err := r.reconcileSensitivePropertiesKey(log)
+	if err != nil {
+		return errors.WrapIf(err, "failed to reconcile resource")
+	}
+
+	trustedCAsHash, err := r.getAdditionalTrustedCAsHash()
+	if err != nil {
+		return err
+	}
+
+	singleUser, err := r.reconcileSingleUserCredentials(log)
+	if err != nil {
+		return errors.WrapIf(err, "failed to reconcile resource")
+	}
+
+	loginIdentityProviders, err := r.getLoginIdentityProviders()
+	if err != nil {
+		return err
+	}
+
+	var rejectedNodeIds []string
 	for _, node := range r.NifiCluster.Spec.Nodes {
 		// We need to grab names for servers and client in case user is enabling ACLs
 		// That way we can continue to manage dataflows and users
@@ -163,7 +215,21 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 
 		}
 
-		o := r.secretConfig(node.Id, nodeConfig, serverPass, clientPass, superUsers, log)
+		repositoryEncryptionPass, err := r.getRepositoryEncryptionPassword(node.Id)
+		if err != nil {
+			return err
+		}
+
+		o := r.secretConfig(node.Id, nodeConfig, serverPass, clientPass, superUsers, sensitiveProps, repositoryEncryptionPass, singleUser, loginIdentityProviders, log)
+		if err := r.checkRepositoryImplementations(o.(*corev1.Secret)); err != nil {
+			if _, ok := errors.Cause(err).(errorfactory.NodeConfigurationRejected); !ok {
+				return err
+			}
+			// Keep the current configuration of the node, the other nodes are still reconciled
+			log.Error(err, "skipping the reconciliation of the node", "nodeId", node.Id)
+			rejectedNodeIds = append(rejectedNodeIds, fmt.Sprint(node.Id))
+			continue
+		}
 		err = k8sutil.Reconcile(log, r.Client, o, r.NifiCluster)
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", o.GetObjectKind().GroupVersionKind())
@@ -181,7 +247,7 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 				return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", o.GetObjectKind().GroupVersionKind())
 			}
 		}
-		o = r.pod(node.Id, nodeConfig, pvcs, log)
+		o = r.pod(node.Id, nodeConfig, pvcs, trustedCAsHash, log)
 		err, isReady := r.reconcileNifiPod(log, o.(*corev1.Pod))
 		if err != nil {
 			return err
@@ -195,7 +261,13 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
-	var err error
+	// The rejected nodes are not restarted with the new sensitive properties key
+	if len(rejectedNodeIds) == 0 {
+		if err := r.completeSensitivePropertiesKeyRotation(sensitiveProps, log); err != nil {
+			return errors.WrapIf(err, "failed to reconcile resource")
+		}
+	}
+
 	// Reconcile external services
 	services := r.externalServices(log)
 	for _, o := range services {
@@ -249,7 +321,11 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 	}
 
 	if clientConfig.UseSSL {
-		if err := r.reconcileNifiUsersAndGroups(log); err != nil {
+		if err := r.reconcileNifiUsersAndGroups(singleUser, log); err != nil {
+			return errors.WrapIf(err, "failed to reconcile resource")
+		}
+
+		if err := r.reconcileTenantPruning(log); err != nil {
 			return errors.WrapIf(err, "failed to reconcile resource")
 		}
 	}
@@ -266,11 +342,255 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
+	if len(rejectedNodeIds) > 0 {
+		return errorfactory.New(errorfactory.NodeConfigurationRejected{},
+			errors.New("repository implementation change is not supported on an existing node"),
+			"the nodes must be replaced to change their repository implementations", "nodeIds", rejectedNodeIds)
+	}
+
 	log.V(1).Info("Reconciled")
 
 	return nil
 }
 
+// reconcileSensitivePropertiesKey ensures the operator managed secret holding the sensitive properties key and algorithm
+// used by the nodes, and starts a rotation when the desired key or algorithm changed.
+func (r *Reconciler) reconcileSensitivePropertiesKey(log logr.Logger) (*corev1.Secret, error) {
+	spec := r.NifiCluster.Spec.SensitiveProperties
+
+	secret := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{
+		Name:      fmt.Sprintf(templates.SensitivePropsSecretTemplate, r.NifiCluster.Name),
+		Namespace: r.NifiCluster.Namespace,
+	}, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.WrapIf(err, "failed to get sensitive properties secret")
+	}
+	secretExists := err == nil
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	// The key in effect is the one of the secret, or the one the deployed nodes were configured with before the
+	// secret was managed by the operator.
+	currentKey := string(secret.Data[v1alpha1.SensitivePropertiesKey])
+	currentAlgorithm := string(secret.Data[v1alpha1.SensitivePropertiesAlgorithm])
+	deployed := secretExists
+	if currentKey == "" {
+		if currentKey, currentAlgorithm, deployed, err = r.sensitivePropertiesInEffect(); err != nil {
+			return nil, err
+		}
+	}
+
+	var desiredKey string
+	if spec.KeySecretRef != nil {
+		keySecret := &corev1.Secret{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{
+			Name:      spec.KeySecretRef.Name,
+			Namespace: r.NifiCluster.Namespace,
+		}, keySecret); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to get sensitive properties key secret",
+				"secret", spec.KeySecretRef.Name)
+		}
+		key, ok := keySecret.Data[spec.KeySecretRef.Key]
+		if !ok || len(key) == 0 {
+			return nil, errors.NewWithDetails("sensitive properties key not found into secret",
+				"secret", spec.KeySecretRef.Name, "key", spec.KeySecretRef.Key)
+		}
+		if len(key) < minSensitivePropertiesKeyLength {
+			return nil, errors.NewWithDetails("sensitive properties key must be at least 12 characters long",
+				"secret", spec.KeySecretRef.Name, "key", spec.KeySecretRef.Key)
+		}
+		desiredKey = string(key)
+	} else if len(currentKey) >= minSensitivePropertiesKeyLength {
+		desiredKey = currentKey
+	} else {
+		desiredKey = string(certutil.GeneratePass(32))
+	}
+	desiredAlgorithm := spec.GetAlgorithm()
+
+	if string(secret.Data[v1alpha1.SensitivePropertiesKey]) == desiredKey &&
+		string(secret.Data[v1alpha1.SensitivePropertiesAlgorithm]) == desiredAlgorithm {
+		return secret, nil
+	}
+
+	// The flow of the deployed nodes is encrypted with the current key, so it has to be rotated.
+	rotate := deployed && (currentKey != desiredKey || currentAlgorithm != desiredAlgorithm)
+	if rotate {
+		// The nodes which are not restarted yet still encrypt their flow with the previous key, so a new rotation can
+		// only start once the running one is completed.
+		if _, ok := secret.Data[v1alpha1.PreviousSensitivePropertiesKey]; ok {
+			log.Info("waiting for the running sensitive properties key rotation to complete")
+			return secret, nil
+		}
+		secret.Data[v1alpha1.PreviousSensitivePropertiesKey] = []byte(currentKey)
+		secret.Data[v1alpha1.PreviousSensitivePropertiesAlgorithm] = []byte(currentAlgorithm)
+	}
+	secret.Data[v1alpha1.SensitivePropertiesKey] = []byte(desiredKey)
+	secret.Data[v1alpha1.SensitivePropertiesAlgorithm] = []byte(desiredAlgorithm)
+
+	if secretExists {
+		if err := r.Client.Update(context.TODO(), secret); err != nil {
+			return nil, errors.WrapIf(err, "failed to update sensitive properties secret")
+		}
+	} else {
+		secret.ObjectMeta = templates.ObjectMeta(
+			fmt.Sprintf(templates.SensitivePropsSecretTemplate, r.NifiCluster.Name),
+			nifiutil.LabelsForNifi(r.NifiCluster.Name),
+			r.NifiCluster,
+		)
+		if err := r.Client.Create(context.TODO(), secret); err != nil {
+			return nil, errors.WrapIf(err, "failed to create sensitive properties secret")
+		}
+	}
+
+	if !rotate {
+		return secret, nil
+	}
+
+	r.NifiCluster.Status.SensitivePropertiesKeyRotation = v1alpha1.SensitivePropertiesKeyRotationRunning
+	if err := r.Client.Status().Update(context.TODO(), r.NifiCluster); err != nil {
+		return nil, errors.WrapIf(err, "failed to update SensitivePropertiesKeyRotation status")
+	}
+	log.Info("sensitive properties key rotation started")
+
+	return secret, nil
+}
+
+// sensitivePropertiesInEffect returns the sensitive properties key and algorithm of the configuration of the deployed
+// nodes, a blank key meaning that NiFi uses its legacy default key. It returns false when no node is deployed yet.
+func (r *Reconciler) sensitivePropertiesInEffect() (string, string, bool, error) {
+	for _, node := range r.NifiCluster.Spec.Nodes {
+		config := &corev1.Secret{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{
+			Name:      fmt.Sprintf(templates.NodeConfigTemplate+"-%d", r.NifiCluster.Name, node.Id),
+			Namespace: r.NifiCluster.Namespace,
+		}, config)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", "", false, errorfactory.New(errorfactory.APIFailure{}, err, "getting resource failed",
+				"secret", config.Name)
+		}
+
+		properties := util.ParsePropertiesFormat(string(config.Data["nifi.properties"]))
+		key := properties[sensitivePropertiesKeyProperty]
+		if key == "" {
+			key = legacySensitivePropertiesKey
+		}
+		algorithm := properties[sensitivePropertiesAlgorithmProperty]
+		if algorithm == "" {
+			algorithm = v1alpha1.DefaultSensitivePropertiesAlgorithm
+		}
+		return key, algorithm, true, nil
+	}
+	return "", "", false, nil
+}
+
+// reconcileSingleUserCredentials ensures the operator managed secret holding the single user username and the bcrypt hash
+// of its password. The hash is only computed again when the credentials change, to keep the node configuration stable.
+func (r *Reconciler) reconcileSingleUserCredentials(log logr.Logger) (*corev1.Secret, error) {
+	singleUser := r.NifiCluster.Spec.SingleUserConfiguration
+	if !singleUser.Enabled {
+		return &corev1.Secret{}, nil
+	}
+
+	if singleUser.SecretRef == nil {
+		return nil, errorfactory.New(errorfactory.FatalReconcileError{}, errors.New("missing single user secret"),
+			"secretRef must be set when the single user configuration is enabled")
+	}
+
+	ref := *singleUser.SecretRef
+	if ref.Namespace == "" {
+		ref.Namespace = r.NifiCluster.Namespace
+	}
+	username, password, _, err := basic.GetControllerBasicConfigFromSecret(r.Client, ref)
+	if err != nil {
+		return nil, err
+	}
+	if username == "" || password == "" {
+		return nil, errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("missing single user credentials"),
+			"single user secret must contain the username and password keys", "secret", ref.Name)
+	}
+
+	secret := &corev1.Secret{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{
+		Name:      fmt.Sprintf(templates.SingleUserSecretTemplate, r.NifiCluster.Name),
+		Namespace: r.NifiCluster.Namespace,
+	}, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.WrapIf(err, "failed to get single user secret")
+	}
+	secretExists := err == nil
+
+	if secretExists && string(secret.Data[v1alpha1.SingleUserUsername]) == username &&
+		bcrypt.CompareHashAndPassword(secret.Data[v1alpha1.SingleUserPasswordHash], []byte(password)) == nil {
+		return secret, nil
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to hash single user password")
+	}
+	data := map[string][]byte{
+		v1alpha1.SingleUserUsername:     []byte(username),
+		v1alpha1.SingleUserPasswordHash: passwordHash,
+	}
+
+	if !secretExists {
+		secret = &corev1.Secret{
+			ObjectMeta: templates.ObjectMeta(
+				fmt.Sprintf(templates.SingleUserSecretTemplate, r.NifiCluster.Name),
+				nifiutil.LabelsForNifi(r.NifiCluster.Name),
+				r.NifiCluster,
+			),
+			Data: data,
+		}
+		if err := r.Client.Create(context.TODO(), secret); err != nil {
+			return nil, errors.WrapIf(err, "failed to create single user secret")
+		}
+		return secret, nil
+	}
+
+	secret.Data = data
+	if err := r.Client.Update(context.TODO(), secret); err != nil {
+		return nil, errors.WrapIf(err, "failed to update single user secret")
+	}
+	log.Info("single user credentials updated")
+
+	return secret, nil
+}
+
+// completeSensitivePropertiesKeyRotation removes the previous sensitive properties key once every node has been
+// restarted with the new one, meaning their flow has been re-encrypted by the init container.
+func (r *Reconciler) completeSensitivePropertiesKeyRotation(secret *corev1.Secret, log logr.Logger) error {
+	if _, ok := secret.Data[v1alpha1.PreviousSensitivePropertiesKey]; !ok {
+		return nil
+	}
+
+	for _, node := range r.NifiCluster.Spec.Nodes {
+		nodeState, ok := r.NifiCluster.Status.NodesState[fmt.Sprint(node.Id)]
+		if !ok || nodeState.ConfigurationState != v1alpha1.ConfigInSync || !nodeState.PodIsReady {
+			return nil
+		}
+	}
+
+	delete(secret.Data, v1alpha1.PreviousSensitivePropertiesKey)
+	delete(secret.Data, v1alpha1.PreviousSensitivePropertiesAlgorithm)
+	if err := r.Client.Update(context.TODO(), secret); err != nil {
+		return errors.WrapIf(err, "failed to update sensitive properties secret")
+	}
+
+	r.NifiCluster.Status.SensitivePropertiesKeyRotation = v1alpha1.SensitivePropertiesKeyRotationSucceeded
+	if err := r.Client.Status().Update(context.TODO(), r.NifiCluster); err != nil {
+		return errors.WrapIf(err, "failed to update SensitivePropertiesKeyRotation status")
+	}
+	log.Info("sensitive properties key rotation succeeded")
+
+	return nil
+}
+
 func (r *Reconciler) reconcileNifiPodDelete(log logr.Logger) error {
 
 	podList := &corev1.PodList{}
@@ -436,6 +756,11 @@ func (r *Reconciler) getServerAndClientDetails(nodeId int32) (string, string, []
 
 	superUsers := make([]string, 0)
 	for _, secret := range []*corev1.Secret{serverSecret, clientSecret} {
+		// the secret can be created before its certificate is issued, e.g. while a signing request awaits approval
+		if len(secret.Data[corev1.TLSCertKey]) == 0 {
+			return "", "", nil, errorfactory.New(errorfactory.ResourceNotReady{},
+				fmt.Errorf("missing secret key %s", corev1.TLSCertKey), "certificate not issued yet", "secret", secret.Name)
+		}
 		cert, err := certutil.DecodeCertificate(secret.Data[corev1.TLSCertKey])
 		if err != nil {
 			return "", "", nil, errors.WrapIfWithDetails(err, "failed to decode certificate")
@@ -446,7 +771,167 @@ func (r *Reconciler) getServerAndClientDetails(nodeId int32) (string, string, []
 	return serverPass, clientPass, superUsers, nil
 }
 
-//
+// getRepositoryEncryptionPassword returns the password of the keystore used to encrypt the repositories of the node
+func (r *Reconciler) getRepositoryEncryptionPassword(nodeId int32) (string, error) {
+	encryption := r.getRepositoryEncryption(nodeId)
+	if encryption == nil {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{
+		Name:      encryption.KeystorePasswordSecretRef.Name,
+		Namespace: r.NifiCluster.Namespace,
+	}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", errorfactory.New(errorfactory.ResourceNotReady{}, err, "repository keystore password secret not ready")
+		}
+		return "", errorfactory.New(errorfactory.APIFailure{}, err, "failed to get repository keystore password secret")
+	}
+
+	password, ok := secret.Data[encryption.KeystorePasswordSecretRef.Key]
+	if !ok {
+		return "", errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("key not found"),
+			"repository keystore password not found into secret",
+			"secret", encryption.KeystorePasswordSecretRef.Name, "key", encryption.KeystorePasswordSecretRef.Key)
+	}
+	return string(password), nil
+}
+
+// getLoginIdentityProviders returns the additional login identity providers of the cluster, the values of their
+// properties referencing a secret key being read from the secret.
+func (r *Reconciler) getLoginIdentityProviders() ([]v1alpha1.LoginIdentityProvider, error) {
+	config := r.NifiCluster.Spec.LoginIdentityProviders
+	reserved := map[string]bool{"ldap-provider": true, "single-user-provider": true}
+	identifiers := map[string]bool{}
+
+	var providers []v1alpha1.LoginIdentityProvider
+	for _, provider := range config.Providers {
+		if reserved[provider.Identifier] || identifiers[provider.Identifier] {
+			return nil, errorfactory.New(errorfactory.FatalReconcileError{}, errors.New("invalid login identity provider"),
+				"login identity provider identifier is reserved or duplicated", "identifier", provider.Identifier)
+		}
+		identifiers[provider.Identifier] = true
+
+		resolved := v1alpha1.LoginIdentityProvider{Identifier: provider.Identifier, Class: provider.Class}
+		for _, property := range provider.Properties {
+			value := property.Value
+			if property.SecretRef != nil {
+				if value != "" {
+					return nil, errorfactory.New(errorfactory.FatalReconcileError{}, errors.New("invalid login identity provider property"),
+						"at most one of value and secretRef must be set", "identifier", provider.Identifier, "property", property.Name)
+				}
+				secret := &corev1.Secret{}
+				err := r.Client.Get(context.TODO(), types.NamespacedName{Name: property.SecretRef.Name, Namespace: r.NifiCluster.Namespace}, secret)
+				if err != nil {
+					if apierrors.IsNotFound(err) {
+						return nil, errorfactory.New(errorfactory.ResourceNotReady{}, err, "login identity provider secret not ready")
+					}
+					return nil, errorfactory.New(errorfactory.APIFailure{}, err, "failed to get login identity provider secret")
+				}
+				data, ok := secret.Data[property.SecretRef.Key]
+				if !ok {
+					return nil, errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("key not found"),
+						"login identity provider property not found into secret",
+						"secret", property.SecretRef.Name, "key", property.SecretRef.Key)
+				}
+				value = string(data)
+			}
+			resolved.Properties = append(resolved.Properties, v1alpha1.LoginIdentityProviderProperty{Name: property.Name, Value: value})
+		}
+		providers = append(providers, resolved)
+	}
+
+	if config.Selected != "" && !identifiers[config.Selected] {
+		return nil, errorfactory.New(errorfactory.FatalReconcileError{}, errors.New("invalid login identity provider"),
+			"selected login identity provider not found", "identifier", config.Selected)
+	}
+	return providers, nil
+}
+
+// getAdditionalTrustedCAsHash returns the hash of the additional trusted certificate authorities bundles, annotated on
+// the pods so that the nodes are restarted when a bundle changes.
+func (r *Reconciler) getAdditionalTrustedCAsHash() (string, error) {
+	if len(r.NifiCluster.Spec.AdditionalTrustedCAs) == 0 {
+		return "", nil
+	}
+
+	hash := sha256.New()
+	for _, source := range r.NifiCluster.Spec.AdditionalTrustedCAs {
+		bundle, err := r.getTrustedCABundle(source)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(bundle)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (r *Reconciler) getTrustedCABundle(source v1alpha1.TrustedCASource) ([]byte, error) {
+	switch {
+	case source.SecretRef != nil && source.ConfigMapRef == nil:
+		secret := &corev1.Secret{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: source.SecretRef.Name, Namespace: r.NifiCluster.Namespace}, secret)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errorfactory.New(errorfactory.ResourceNotReady{}, err, "trusted CA bundle secret not ready")
+			}
+			return nil, errorfactory.New(errorfactory.APIFailure{}, err, "failed to get trusted CA bundle secret")
+		}
+		bundle, ok := secret.Data[source.SecretRef.Key]
+		if !ok {
+			return nil, errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("key not found"),
+				"trusted CA bundle not found into secret", "secret", source.SecretRef.Name, "key", source.SecretRef.Key)
+		}
+		return bundle, nil
+	case source.ConfigMapRef != nil && source.SecretRef == nil:
+		configMap := &corev1.ConfigMap{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: source.ConfigMapRef.Name, Namespace: r.NifiCluster.Namespace}, configMap)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errorfactory.New(errorfactory.ResourceNotReady{}, err, "trusted CA bundle configmap not ready")
+			}
+			return nil, errorfactory.New(errorfactory.APIFailure{}, err, "failed to get trusted CA bundle configmap")
+		}
+		bundle, ok := configMap.Data[source.ConfigMapRef.Key]
+		if !ok {
+			return nil, errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("key not found"),
+				"trusted CA bundle not found into configmap", "configmap", source.ConfigMapRef.Name, "key", source.ConfigMapRef.Key)
+		}
+		return []byte(bundle), nil
+	default:
+		return nil, errorfactory.New(errorfactory.FatalReconcileError{}, errors.New("invalid trusted CA source"),
+			"exactly one of secretRef and configMapRef must be set")
+	}
+}
+
+// checkRepositoryImplementations prevents switching the repository implementations of an existing node, since the
+// records written by the current implementations can't be read by the new ones. The node must be replaced instead.
+func (r *Reconciler) checkRepositoryImplementations(desired *corev1.Secret) error {
+	current := &corev1.Secret{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errorfactory.New(errorfactory.APIFailure{}, err, "getting resource failed", "secret", desired.Name)
+	}
+
+	currentProperties := util.ParsePropertiesFormat(string(current.Data["nifi.properties"]))
+	desiredProperties := util.ParsePropertiesFormat(string(desired.Data["nifi.properties"]))
+	for _, property := range repositoryImplementationProperties {
+		if currentProperties[property] != desiredProperties[property] {
+			return errorfactory.New(errorfactory.NodeConfigurationRejected{},
+				errors.New("repository implementation change is not supported on an existing node"),
+				"the node must be replaced to change its repository implementations",
+				"nodeId", desired.Labels["nodeId"], "property", property,
+				"current", currentProperties[property], "desired", desiredProperties[property])
+		}
+	}
+	return nil
+}
+
 func generateNodeIdsFromPodSlice(pods []corev1.Pod) []string {
 	ids := make([]string, len(pods))
 	for i, node := range pods {
@@ -696,7 +1181,7 @@ func (r *Reconciler) reconcileNifiPod(log logr.Logger, desiredPod *corev1.Pod) (
 	return nil, k8sutil.PodReady(currentPod)
 }
 
-func (r *Reconciler) reconcileNifiUsersAndGroups(log logr.Logger) error {
+func (r *Reconciler) reconcileNifiUsersAndGroups(singleUser *corev1.Secret, log logr.Logger) error {
 	controllerName := types.NamespacedName{Name: fmt.Sprintf(pkicommon.NodeControllerFQDNTemplate,
 		fmt.Sprintf(pkicommon.NodeControllerTemplate, r.NifiCluster.Name),
 		r.NifiCluster.Namespace,
@@ -727,6 +1212,25 @@ func (r *Reconciler) reconcileNifiUsersAndGroups(log logr.Logger) error {
 		managedAdminUserRef = append(managedAdminUserRef, v1alpha1.UserReference{Name: fmt.Sprintf("%s.%s", r.NifiCluster.Name, user.Name)})
 	}
 
+	// The single user is a managed admin, so that it keeps its policies and is never pruned
+	if r.NifiCluster.Spec.SingleUserConfiguration.Enabled {
+		users = append(users, &v1alpha1.NifiUser{
+			ObjectMeta: templates.ObjectMeta(
+				fmt.Sprintf(templates.SingleUserSecretTemplate, r.NifiCluster.Name),
+				pkicommon.LabelsForNifiPKI(r.NifiCluster.Name), r.NifiCluster,
+			),
+			Spec: v1alpha1.NifiUserSpec{
+				Identity:   string(singleUser.Data[v1alpha1.SingleUserUsername]),
+				CreateCert: &pFalse,
+				ClusterRef: v1alpha1.ClusterReference{
+					Name:      r.NifiCluster.Name,
+					Namespace: r.NifiCluster.Namespace,
+				},
+			},
+		})
+		managedAdminUserRef = append(managedAdminUserRef, v1alpha1.UserReference{Name: fmt.Sprintf(templates.SingleUserSecretTemplate, r.NifiCluster.Name)})
+	}
+
 	var managedReaderUserRef []v1alpha1.UserReference
 	for _, user := range r.NifiCluster.Spec.ManagedReaderUsers {
 		managedReaderUserRef = append(managedReaderUserRef, v1alpha1.UserReference{Name: fmt.Sprintf("%s.%s", r.NifiCluster.Name, user.Name)})
@@ -864,6 +1368,81 @@ func (r *Reconciler) reconcileNifiUsersAndGroups(log logr.Logger) error {
 	return nil
 }
 
+func (r *Reconciler) reconcileTenantPruning(log logr.Logger) error {
+	var unmanagedTenants *v1alpha1.UnmanagedTenantsStatus
+
+	if r.NifiCluster.Spec.TenantPruning.GetMode() != v1alpha1.TenantPruningOff {
+		managedIdentities, err := r.managedTenantIdentities()
+		if err != nil {
+			return err
+		}
+
+		configManager := config.GetClientConfigManager(r.Client, v1alpha1.ClusterReference{
+			Namespace: r.NifiCluster.Namespace,
+			Name:      r.NifiCluster.Name,
+		})
+		clientConfig, err := configManager.BuildConfig()
+		if err != nil {
+			return err
+		}
+
+		unmanagedTenants, err = tenants.PruneTenants(r.NifiCluster, managedIdentities, clientConfig)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "failed to prune unmanaged tenants")
+		}
+
+		if unmanagedTenants != nil {
+			log.Info("Unmanaged tenants found on the NiFi cluster",
+				"users", unmanagedTenants.Users, "userGroups", unmanagedTenants.UserGroups)
+		}
+	}
+
+	if reflect.DeepEqual(r.NifiCluster.Status.UnmanagedTenants, unmanagedTenants) {
+		return nil
+	}
+
+	r.NifiCluster.Status.UnmanagedTenants = unmanagedTenants
+	if err := r.Client.Status().Update(context.TODO(), r.NifiCluster); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to update UnmanagedTenants status")
+	}
+	return nil
+}
+
+// managedTenantIdentities returns the identities of the NifiUsers and NifiUserGroups referencing the cluster, which
+// include the managed admin and reader users, the nodes and the controller users.
+func (r *Reconciler) managedTenantIdentities() ([]string, error) {
+	var identities []string
+
+	var users v1alpha1.NifiUserList
+	if err := r.Client.List(context.TODO(), &users); err != nil {
+		return nil, errors.WrapIf(err, "failed to list NifiUsers")
+	}
+	for _, user := range users.Items {
+		if r.isClusterReferenced(user.Namespace, user.Spec.ClusterRef) {
+			identities = append(identities, user.GetIdentity())
+		}
+	}
+
+	var userGroups v1alpha1.NifiUserGroupList
+	if err := r.Client.List(context.TODO(), &userGroups); err != nil {
+		return nil, errors.WrapIf(err, "failed to list NifiUserGroups")
+	}
+	for _, userGroup := range userGroups.Items {
+		if r.isClusterReferenced(userGroup.Namespace, userGroup.Spec.ClusterRef) {
+			identities = append(identities, userGroup.GetIdentity())
+		}
+	}
+
+	return identities, nil
+}
+
+func (r *Reconciler) isClusterReferenced(namespace string, ref v1alpha1.ClusterReference) bool {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return ref.Name == r.NifiCluster.Name && namespace == r.NifiCluster.Namespace
+}
+
 func (r *Reconciler) reconcilePrometheusReportingTask(log logr.Logger) error {
 
 	var err error
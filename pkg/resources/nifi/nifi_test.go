@@ -0,0 +1,512 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package nifi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/Orange-OpenSource/nifikop/api/v1alpha1"
+	"github.com/Orange-OpenSource/nifikop/pkg/errorfactory"
+	"github.com/Orange-OpenSource/nifikop/pkg/resources/templates"
+	"github.com/Orange-OpenSource/nifikop/pkg/util"
+	"github.com/go-logr/logr"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testCluster() *v1alpha1.NifiCluster {
+	cluster := &v1alpha1.NifiCluster{ObjectMeta: metav1.ObjectMeta{Name: "nifi", Namespace: "default"}}
+	cluster.Spec.ListenersConfig = &v1alpha1.ListenersConfig{}
+	cluster.Spec.Nodes = []v1alpha1.Node{{Id: 1}}
+	return cluster
+}
+
+func testReconciler(cluster *v1alpha1.NifiCluster, objects ...runtime.Object) *Reconciler {
+	v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	client := fake.NewFakeClientWithScheme(scheme.Scheme, append(objects, cluster)...)
+	return New(client, client, scheme.Scheme, cluster)
+}
+
+func nodeConfigSecret(cluster *v1alpha1.NifiCluster, nodeId int32, nifiProperties string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(templates.NodeConfigTemplate+"-%d", cluster.Name, nodeId),
+			Namespace: cluster.Namespace,
+			Labels:    map[string]string{"nodeId": fmt.Sprint(nodeId)},
+		},
+		Data: map[string][]byte{"nifi.properties": []byte(nifiProperties)},
+	}
+}
+
+func sensitivePropsSecret(cluster *v1alpha1.NifiCluster, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      fmt.Sprintf(templates.SensitivePropsSecretTemplate, cluster.Name),
+		Namespace: cluster.Namespace,
+	}}
+	if data != nil {
+		secret.Data = map[string][]byte{}
+		for key, value := range data {
+			secret.Data[key] = []byte(value)
+		}
+	}
+	return secret
+}
+
+func TestReconcileSensitivePropertiesKey(t *testing.T) {
+	const refKey = "referenced-key-value"
+
+	testCases := []struct {
+		name                      string
+		keySecret                 string
+		algorithm                 string
+		objects                   func(cluster *v1alpha1.NifiCluster) []runtime.Object
+		expectedKey               string
+		expectedAlgorithm         string
+		expectedPreviousKey       string
+		expectedPreviousAlgorithm string
+		expectedErr               bool
+	}{
+		{
+			name:              "new cluster",
+			expectedAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+		},
+		{
+			name:              "new cluster with a referenced key",
+			keySecret:         refKey,
+			algorithm:         "NIFI_PBKDF2_AES_GCM_256",
+			expectedKey:       refKey,
+			expectedAlgorithm: "NIFI_PBKDF2_AES_GCM_256",
+		},
+		{
+			name:        "referenced key too short",
+			keySecret:   "short",
+			expectedErr: true,
+		},
+		{
+			name: "existing cluster with the legacy default key",
+			objects: func(cluster *v1alpha1.NifiCluster) []runtime.Object {
+				return []runtime.Object{nodeConfigSecret(cluster, 1, "nifi.sensitive.props.key=\n")}
+			},
+			expectedAlgorithm:         v1alpha1.DefaultSensitivePropertiesAlgorithm,
+			expectedPreviousKey:       legacySensitivePropertiesKey,
+			expectedPreviousAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+		},
+		{
+			name: "existing cluster with an overridden key",
+			objects: func(cluster *v1alpha1.NifiCluster) []runtime.Object {
+				return []runtime.Object{nodeConfigSecret(cluster, 1,
+					"nifi.sensitive.props.key=overridden-key-value\nnifi.sensitive.props.algorithm=PBEWITHMD5AND256BITAES-CBC-OPENSSL\n")}
+			},
+			expectedKey:       "overridden-key-value",
+			expectedAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+		},
+		{
+			name:      "existing cluster rotated to the referenced key",
+			keySecret: refKey,
+			objects: func(cluster *v1alpha1.NifiCluster) []runtime.Object {
+				return []runtime.Object{nodeConfigSecret(cluster, 1, "nifi.sensitive.props.key=overridden-key-value\n")}
+			},
+			expectedKey:               refKey,
+			expectedAlgorithm:         v1alpha1.DefaultSensitivePropertiesAlgorithm,
+			expectedPreviousKey:       "overridden-key-value",
+			expectedPreviousAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+		},
+		{
+			name: "secret without data",
+			objects: func(cluster *v1alpha1.NifiCluster) []runtime.Object {
+				return []runtime.Object{
+					sensitivePropsSecret(cluster, nil),
+					nodeConfigSecret(cluster, 1, "nifi.sensitive.props.key=overridden-key-value\n"),
+				}
+			},
+			expectedKey:       "overridden-key-value",
+			expectedAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+		},
+		{
+			name:      "key rotated",
+			keySecret: refKey,
+			algorithm: "NIFI_PBKDF2_AES_GCM_256",
+			objects: func(cluster *v1alpha1.NifiCluster) []runtime.Object {
+				return []runtime.Object{sensitivePropsSecret(cluster, map[string]string{
+					v1alpha1.SensitivePropertiesKey:       "current-key-value",
+					v1alpha1.SensitivePropertiesAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+				})}
+			},
+			expectedKey:               refKey,
+			expectedAlgorithm:         "NIFI_PBKDF2_AES_GCM_256",
+			expectedPreviousKey:       "current-key-value",
+			expectedPreviousAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+		},
+		{
+			name:      "rotation already running",
+			keySecret: refKey,
+			objects: func(cluster *v1alpha1.NifiCluster) []runtime.Object {
+				return []runtime.Object{sensitivePropsSecret(cluster, map[string]string{
+					v1alpha1.SensitivePropertiesKey:               "current-key-value",
+					v1alpha1.SensitivePropertiesAlgorithm:         v1alpha1.DefaultSensitivePropertiesAlgorithm,
+					v1alpha1.PreviousSensitivePropertiesKey:       "previous-key-value",
+					v1alpha1.PreviousSensitivePropertiesAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+				})}
+			},
+			expectedKey:               "current-key-value",
+			expectedAlgorithm:         v1alpha1.DefaultSensitivePropertiesAlgorithm,
+			expectedPreviousKey:       "previous-key-value",
+			expectedPreviousAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := testCluster()
+			cluster.Spec.SensitiveProperties.Algorithm = test.algorithm
+			var objects []runtime.Object
+			if test.objects != nil {
+				objects = test.objects(cluster)
+			}
+			if test.keySecret != "" {
+				cluster.Spec.SensitiveProperties.KeySecretRef = &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "key"},
+					Key:                  "key",
+				}
+				objects = append(objects, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "key", Namespace: cluster.Namespace},
+					Data:       map[string][]byte{"key": []byte(test.keySecret)},
+				})
+			}
+			r := testReconciler(cluster, objects...)
+
+			_, err := r.reconcileSensitivePropertiesKey(logr.Discard())
+			if test.expectedErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Expected no error, got:", err)
+			}
+
+			secret := &corev1.Secret{}
+			if err := r.Client.Get(context.TODO(), types.NamespacedName{
+				Name:      fmt.Sprintf(templates.SensitivePropsSecretTemplate, cluster.Name),
+				Namespace: cluster.Namespace,
+			}, secret); err != nil {
+				t.Fatal("Expected the sensitive properties secret, got:", err)
+			}
+
+			key := string(secret.Data[v1alpha1.SensitivePropertiesKey])
+			if test.expectedKey != "" && key != test.expectedKey {
+				t.Errorf("Expected key %s, got: %s", test.expectedKey, key)
+			}
+			if len(key) < minSensitivePropertiesKeyLength {
+				t.Errorf("Expected a key of at least %d characters, got: %s", minSensitivePropertiesKeyLength, key)
+			}
+			if algorithm := string(secret.Data[v1alpha1.SensitivePropertiesAlgorithm]); algorithm != test.expectedAlgorithm {
+				t.Errorf("Expected algorithm %s, got: %s", test.expectedAlgorithm, algorithm)
+			}
+			if previousKey := string(secret.Data[v1alpha1.PreviousSensitivePropertiesKey]); previousKey != test.expectedPreviousKey {
+				t.Errorf("Expected previous key %q, got: %q", test.expectedPreviousKey, previousKey)
+			}
+			if previousAlgorithm := string(secret.Data[v1alpha1.PreviousSensitivePropertiesAlgorithm]); previousAlgorithm != test.expectedPreviousAlgorithm {
+				t.Errorf("Expected previous algorithm %q, got: %q", test.expectedPreviousAlgorithm, previousAlgorithm)
+			}
+
+			rotationStarted := test.expectedPreviousKey != "" && test.name != "rotation already running"
+			if running := r.NifiCluster.Status.SensitivePropertiesKeyRotation == v1alpha1.SensitivePropertiesKeyRotationRunning; running != rotationStarted {
+				t.Errorf("Expected rotation started %v, got: %v", rotationStarted, running)
+			}
+		})
+	}
+}
+
+func testRepositoryEncryption(keyId string) *v1alpha1.RepositoryEncryptionConfig {
+	return &v1alpha1.RepositoryEncryptionConfig{
+		KeystoreSecretRef: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "repository-keystore"}, Key: "keystore.p12"},
+		KeystorePasswordSecretRef: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "repository-keystore"}, Key: "password"},
+		KeyId: keyId,
+	}
+}
+
+func renderNifiProperties(r *Reconciler, nodeId int32) map[string]string {
+	sensitiveProps := sensitivePropsSecret(r.NifiCluster, map[string]string{
+		v1alpha1.SensitivePropertiesKey:       "sensitive-key-value",
+		v1alpha1.SensitivePropertiesAlgorithm: v1alpha1.DefaultSensitivePropertiesAlgorithm,
+	})
+	return util.ParsePropertiesFormat(r.generateNifiPropertiesNodeConfig(nodeId, &v1alpha1.NodeConfig{},
+		"", "", nil, sensitiveProps, "keystore-password", logr.Discard()))
+}
+
+func TestRepositoryEncryptionProperties(t *testing.T) {
+	cluster := testCluster()
+	cluster.Spec.Nodes = []v1alpha1.Node{{Id: 1}, {Id: 2, ReadOnlyConfig: &v1alpha1.ReadOnlyConfig{Encryption: testRepositoryEncryption("node-key")}}}
+	r := testReconciler(cluster)
+
+	properties := renderNifiProperties(r, 1)
+	for _, property := range repositoryImplementationProperties {
+		if strings.Contains(properties[property], "Encrypted") {
+			t.Errorf("Expected %s not to be encrypted, got: %s", property, properties[property])
+		}
+	}
+	if properties["nifi.flowfile.repository.encryption.key.id"] != "" {
+		t.Error("Expected no flowfile repository encryption key")
+	}
+
+	cluster.Spec.ReadOnlyConfig.Encryption = testRepositoryEncryption("cluster-key")
+	expected := map[string]map[string]string{
+		"cluster": {
+			"nifi.flowfile.repository.wal.implementation":                 "org.apache.nifi.wali.EncryptedSequentialAccessWriteAheadLog",
+			"nifi.content.repository.implementation":                      "org.apache.nifi.controller.repository.crypto.EncryptedFileSystemRepository",
+			"nifi.provenance.repository.implementation":                   "org.apache.nifi.provenance.EncryptedWriteAheadProvenanceRepository",
+			"nifi.content.repository.encryption.key.provider.location":    repositoryKeystorePath + "/" + repositoryKeystoreFile,
+			"nifi.content.repository.encryption.key.provider.password":    "keystore-password",
+			"nifi.flowfile.repository.encryption.key.id":                  "cluster-key",
+			"nifi.content.repository.encryption.key.id":                   "cluster-key",
+			"nifi.provenance.repository.encryption.key.id":                "cluster-key",
+			"nifi.provenance.repository.encryption.key.provider.password": "keystore-password",
+		},
+		"node": {
+			"nifi.flowfile.repository.encryption.key.id":   "node-key",
+			"nifi.content.repository.encryption.key.id":    "node-key",
+			"nifi.provenance.repository.encryption.key.id": "node-key",
+		},
+	}
+	for name, nodeId := range map[string]int32{"cluster": 1, "node": 2} {
+		properties := renderNifiProperties(r, nodeId)
+		for property, value := range expected[name] {
+			if properties[property] != value {
+				t.Errorf("%s: expected %s=%s, got: %s", name, property, value, properties[property])
+			}
+		}
+	}
+}
+
+func TestCheckRepositoryImplementations(t *testing.T) {
+	cluster := testCluster()
+	plain := renderNifiProperties(testReconciler(cluster), 1)
+
+	encryptedCluster := testCluster()
+	encryptedCluster.Spec.ReadOnlyConfig.Encryption = testRepositoryEncryption("key")
+	encrypted := renderNifiProperties(testReconciler(encryptedCluster), 1)
+
+	toProperties := func(properties map[string]string) string {
+		var lines []string
+		for key, value := range properties {
+			lines = append(lines, key+"="+value)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	testCases := []struct {
+		name        string
+		current     map[string]string
+		desired     map[string]string
+		expectedErr bool
+	}{
+		{"new node", nil, encrypted, false},
+		{"unchanged implementations", plain, plain, false},
+		{"encryption enabled", plain, encrypted, true},
+		{"encryption disabled", encrypted, plain, true},
+	}
+
+	for _, test := range testCases {
+		var objects []runtime.Object
+		if test.current != nil {
+			objects = append(objects, nodeConfigSecret(cluster, 1, toProperties(test.current)))
+		}
+		r := testReconciler(cluster, objects...)
+
+		err := r.checkRepositoryImplementations(nodeConfigSecret(cluster, 1, toProperties(test.desired)))
+		if !test.expectedErr {
+			if err != nil {
+				t.Errorf("%s: expected no error, got: %v", test.name, err)
+			}
+			continue
+		}
+		if _, ok := errors.Cause(err).(errorfactory.NodeConfigurationRejected); !ok {
+			t.Errorf("%s: expected a rejected node configuration, got: %v", test.name, err)
+		}
+	}
+}
+
+func credentialsSecret(cluster *v1alpha1.NifiCluster, username, password string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: cluster.Namespace},
+		Data:       map[string][]byte{"username": []byte(username), "password": []byte(password)},
+	}
+}
+
+func TestReconcileSingleUserCredentials(t *testing.T) {
+	cluster := testCluster()
+	r := testReconciler(cluster)
+	secret, err := r.reconcileSingleUserCredentials(logr.Discard())
+	if err != nil || secret.Data != nil {
+		t.Fatalf("Expected no single user secret when disabled, got: %v, %v", secret, err)
+	}
+
+	cluster.Spec.SingleUserConfiguration.Enabled = true
+	if _, err := r.reconcileSingleUserCredentials(logr.Discard()); err == nil {
+		t.Error("Expected an error without secretRef")
+	}
+
+	cluster.Spec.SingleUserConfiguration.SecretRef = &v1alpha1.SecretReference{Name: "credentials"}
+	r = testReconciler(cluster, credentialsSecret(cluster, "admin", ""))
+	if _, err := r.reconcileSingleUserCredentials(logr.Discard()); err == nil {
+		t.Error("Expected an error without password")
+	}
+
+	r = testReconciler(cluster, credentialsSecret(cluster, "admin", "first-password"))
+	secret, err = r.reconcileSingleUserCredentials(logr.Discard())
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if string(secret.Data[v1alpha1.SingleUserUsername]) != "admin" ||
+		bcrypt.CompareHashAndPassword(secret.Data[v1alpha1.SingleUserPasswordHash], []byte("first-password")) != nil {
+		t.Errorf("Expected the single user credentials, got: %v", secret.Data)
+	}
+	hash := string(secret.Data[v1alpha1.SingleUserPasswordHash])
+
+	secret, err = r.reconcileSingleUserCredentials(logr.Discard())
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if string(secret.Data[v1alpha1.SingleUserPasswordHash]) != hash {
+		t.Error("Expected the password hash to be kept when the credentials are unchanged")
+	}
+
+	if err := r.Client.Update(context.TODO(), credentialsSecret(cluster, "admin", "second-password")); err != nil {
+		t.Fatal(err)
+	}
+	secret, err = r.reconcileSingleUserCredentials(logr.Discard())
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if bcrypt.CompareHashAndPassword(secret.Data[v1alpha1.SingleUserPasswordHash], []byte("second-password")) != nil {
+		t.Error("Expected the password hash to be updated when the password changes")
+	}
+}
+
+func TestLoginIdentityProvidersConfig(t *testing.T) {
+	cluster := testCluster()
+	cluster.Spec.SingleUserConfiguration.Enabled = true
+	cluster.Spec.LoginIdentityProviders = v1alpha1.LoginIdentityProvidersConfiguration{
+		Selected: "custom-provider",
+		Providers: []v1alpha1.LoginIdentityProvider{{
+			Identifier: "custom-provider",
+			Class:      "org.example.CustomProvider",
+			Properties: []v1alpha1.LoginIdentityProviderProperty{
+				{Name: "Url", Value: "https://auth.example.com"},
+				{Name: "Password", SecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "provider"}, Key: "password"}},
+			},
+		}},
+	}
+	providerSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "provider", Namespace: cluster.Namespace},
+		Data:       map[string][]byte{"password": []byte("p&ss")},
+	}
+	r := testReconciler(cluster, providerSecret)
+
+	providers, err := r.getLoginIdentityProviders()
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	singleUser := &corev1.Secret{Data: map[string][]byte{
+		v1alpha1.SingleUserUsername:     []byte("admin<1>"),
+		v1alpha1.SingleUserPasswordHash: []byte("$2a$10$hash"),
+	}}
+	xml := r.getLoginIdentityProvidersConfigString(&v1alpha1.NodeConfig{}, 1, singleUser, providers, logr.Discard())
+	for _, expected := range []string{
+		"<identifier>single-user-provider</identifier>",
+		`<property name="Username">admin&lt;1&gt;</property>`,
+		`<property name="Password">$2a$10$hash</property>`,
+		"<identifier>custom-provider</identifier>",
+		"<class>org.example.CustomProvider</class>",
+		`<property name="Url">https://auth.example.com</property>`,
+		`<property name="Password">p&amp;ss</property>`,
+	} {
+		if !strings.Contains(xml, expected) {
+			t.Errorf("Expected %s into login-identity-providers.xml", expected)
+		}
+	}
+	if strings.Contains(xml, "ldap-provider</identifier>") {
+		t.Error("Expected no LDAP provider")
+	}
+
+	if provider := renderNifiProperties(r, 1)["nifi.security.user.login.identity.provider"]; provider != "single-user-provider" {
+		t.Errorf("Expected the single user provider to take precedence, got: %s", provider)
+	}
+	cluster.Spec.SingleUserConfiguration.Enabled = false
+	if provider := renderNifiProperties(r, 1)["nifi.security.user.login.identity.provider"]; provider != "custom-provider" {
+		t.Errorf("Expected the selected provider, got: %s", provider)
+	}
+
+	for name, providers := range map[string][]v1alpha1.LoginIdentityProvider{
+		"reserved identifier":  {{Identifier: "ldap-provider"}},
+		"duplicate identifier": {{Identifier: "custom-provider"}, {Identifier: "custom-provider"}},
+		"selected not found":   {{Identifier: "other-provider"}},
+	} {
+		cluster.Spec.LoginIdentityProviders.Providers = providers
+		if _, err := r.getLoginIdentityProviders(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestAuthorizersInitialAdmin(t *testing.T) {
+	cluster := testCluster()
+	cluster.Status.NodesState = map[string]v1alpha1.NodeState{"1": {InitClusterNode: true}}
+	r := testReconciler(cluster)
+	singleUser := &corev1.Secret{Data: map[string][]byte{v1alpha1.SingleUserUsername: []byte("admin")}}
+	controller := `<property name="Initial User Identity admin">nifi-controller.default.mgt.cluster.local</property>`
+
+	testCases := []struct {
+		name                 string
+		singleUser           bool
+		clientType           v1alpha1.ClientConfigType
+		expectedInitialAdmin string
+		expectedSingleUser   bool
+	}{
+		{"controller", false, v1alpha1.ClientConfigTLS, "nifi-controller.default.mgt.cluster.local", false},
+		{"single user with the tls client", true, v1alpha1.ClientConfigTLS, "nifi-controller.default.mgt.cluster.local", true},
+		{"single user with the basic client", true, v1alpha1.ClientConfigBasic, "admin", true},
+	}
+
+	for _, test := range testCases {
+		cluster.Spec.SingleUserConfiguration.Enabled = test.singleUser
+		cluster.Spec.ClientType = test.clientType
+		xml := r.getAuthorizersConfigString(&v1alpha1.NodeConfig{}, 1, singleUser, logr.Discard())
+
+		if !strings.Contains(xml, controller) {
+			t.Errorf("%s: expected the controller to be an initial user", test.name)
+		}
+		if seeded := strings.Contains(xml, `<property name="Initial User Identity single-user">admin</property>`); seeded != test.expectedSingleUser {
+			t.Errorf("%s: expected single user seeded %v, got: %v", test.name, test.expectedSingleUser, seeded)
+		}
+		if expected := `<property name="Initial Admin Identity">` + test.expectedInitialAdmin + `</property>`; !strings.Contains(xml, expected) {
+			t.Errorf("%s: expected %s", test.name, expected)
+		}
+	}
+}
@@ -53,7 +53,7 @@ const (
 	ContainerName string = "nifi"
 )
 
-func (r *Reconciler) pod(id int32, nodeConfig *v1alpha1.NodeConfig, pvcs []corev1.PersistentVolumeClaim, log logr.Logger) runtimeClient.Object {
+func (r *Reconciler) pod(id int32, nodeConfig *v1alpha1.NodeConfig, pvcs []corev1.PersistentVolumeClaim, trustedCAsHash string, log logr.Logger) runtimeClient.Object {
 
 	zkAddress := r.NifiCluster.Spec.ZKAddress
 	zkHostname := zk.GetHostnameAddress(zkAddress)
@@ -73,6 +73,22 @@ func (r *Reconciler) pod(id int32, nodeConfig *v1alpha1.NodeConfig, pvcs []corev
 		volumeMount = append(volumeMount, generateVolumeMountForSSL()...)
 	}
 
+	if encryption := r.getRepositoryEncryption(id); encryption != nil {
+		volume = append(volume, generateVolumeForRepositoryEncryption(encryption))
+		volumeMount = append(volumeMount, corev1.VolumeMount{
+			Name:      repositoryKeystoreVolume,
+			MountPath: repositoryKeystorePath,
+		})
+	}
+
+	if len(r.NifiCluster.Spec.AdditionalTrustedCAs) > 0 {
+		volume = append(volume, generateVolumesForTrustedCAs(r.NifiCluster.Spec.AdditionalTrustedCAs)...)
+		volumeMount = append(volumeMount, corev1.VolumeMount{
+			Name:      trustedCAsVolume,
+			MountPath: trustedCAsPath,
+		})
+	}
+
 	podVolumes := append(volume, []corev1.Volume{
 		{
 			Name: nodeSecretVolumeMount,
@@ -115,6 +131,7 @@ func (r *Reconciler) pod(id int32, nodeConfig *v1alpha1.NodeConfig, pvcs []corev
 	sort.Slice(initContainers, func(i, j int) bool {
 		return initContainers[i].Name < initContainers[j].Name
 	})
+	initContainers = append(initContainers, r.trustedCAsInitContainers(nodeConfig)...)
 
 	anntotationsToMerge := []map[string]string{
 		nodeConfig.GetNodeAnnotations(),
@@ -125,6 +142,10 @@ func (r *Reconciler) pod(id int32, nodeConfig *v1alpha1.NodeConfig, pvcs []corev
 		anntotationsToMerge = append(anntotationsToMerge, util.MonitoringAnnotations(*r.NifiCluster.Spec.GetMetricPort()))
 	}
 
+	if trustedCAsHash != "" {
+		anntotationsToMerge = append(anntotationsToMerge, map[string]string{trustedCAsHashAnnotation: trustedCAsHash})
+	}
+
 	// curl -kv --cert /var/run/secrets/java.io/keystores/client/tls.crt --key /var/run/secrets/java.io/keystores/client/tls.key https://nifi.trycatchlearn.fr:8433/nifi
 	// curl -kv --cert /var/run/secrets/java.io/keystores/client/tls.crt --key /var/run/secrets/java.io/keystores/client/tls.key https://securenc-headless.external-dns-test.gcp.trycatchlearn.fr:8443/nifi-api/controller/cluster
 	// keytool -import -noprompt -keystore /home/nifi/truststore.jks -file /var/run/secrets/java.io/keystores/server/ca.crt -storepass $(cat /var/run/secrets/java.io/keystores/server/password) -alias test1
@@ -158,6 +179,7 @@ done`,
 						zkAddress, zkHostname, zkPort)},
 					Resources: generateInitContainerResources(),
 				},
+				r.sensitivePropsKeyInitContainer(nodeConfig, podVolumeMounts),
 			}...)),
 			Affinity: &corev1.Affinity{
 				PodAntiAffinity: generatePodAntiAffinity(r.NifiCluster.Name, r.NifiCluster.Spec.OneNifiNodePerNode),
@@ -187,6 +209,56 @@ done`,
 	return pod
 }
 
+// sensitivePropsKeyInitContainer re-encrypts the flow of the node with the new sensitive properties key and algorithm
+// while a rotation is running. The hash of the applied key is stored next to the flow, so that the flow is re-encrypted
+// only once per rotation.
+func (r *Reconciler) sensitivePropsKeyInitContainer(nodeConfig *v1alpha1.NodeConfig, podVolumeMounts []corev1.VolumeMount) corev1.Container {
+	sensitivePropsSecret := fmt.Sprintf(templates.SensitivePropsSecretTemplate, r.NifiCluster.Name)
+	secretEnvVar := func(name, key string, optional bool) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: sensitivePropsSecret},
+					Key:                  key,
+					Optional:             util.BoolPointer(optional),
+				},
+			},
+		}
+	}
+
+	return corev1.Container{
+		Name:            "sensitive-props-key",
+		Image:           util.GetNodeImage(nodeConfig, r.NifiCluster.Spec.ClusterImage),
+		ImagePullPolicy: nodeConfig.GetImagePullPolicy(),
+		Command: []string{"bash", "-ce", `
+APPLIED_KEY_FILE=$NIFI_BASE_DIR/data/sensitive-props-key.sha256
+APPLIED_KEY=$(echo -n "${SENSITIVE_PROPS_ALGORITHM}:${SENSITIVE_PROPS_KEY}" | sha256sum | cut -d ' ' -f 1)
+if [ -n "$PREVIOUS_SENSITIVE_PROPS_KEY" ] && [ -f "$NIFI_BASE_DIR/data/flow.xml.gz" ] && [ "$(cat $APPLIED_KEY_FILE 2>/dev/null)" != "$APPLIED_KEY" ]; then
+	echo "Rotating the sensitive properties key"
+	cp ${NIFI_HOME}/tmp/* ${NIFI_HOME}/conf/
+	grep -v -e '^nifi.sensitive.props.key=' -e '^nifi.sensitive.props.algorithm=' ${NIFI_HOME}/tmp/nifi.properties > ${NIFI_HOME}/conf/nifi.properties
+	echo "nifi.sensitive.props.key=${PREVIOUS_SENSITIVE_PROPS_KEY}" >> ${NIFI_HOME}/conf/nifi.properties
+	echo "nifi.sensitive.props.algorithm=${PREVIOUS_SENSITIVE_PROPS_ALGORITHM}" >> ${NIFI_HOME}/conf/nifi.properties
+	if [ "$PREVIOUS_SENSITIVE_PROPS_ALGORITHM" != "$SENSITIVE_PROPS_ALGORITHM" ]; then
+		${NIFI_HOME}/bin/nifi.sh set-sensitive-properties-algorithm "$SENSITIVE_PROPS_ALGORITHM"
+	fi
+	if [ "$PREVIOUS_SENSITIVE_PROPS_KEY" != "$SENSITIVE_PROPS_KEY" ]; then
+		${NIFI_HOME}/bin/nifi.sh set-sensitive-properties-key "$SENSITIVE_PROPS_KEY"
+	fi
+fi
+echo -n "$APPLIED_KEY" > $APPLIED_KEY_FILE`},
+		Env: []corev1.EnvVar{
+			secretEnvVar("SENSITIVE_PROPS_KEY", v1alpha1.SensitivePropertiesKey, false),
+			secretEnvVar("SENSITIVE_PROPS_ALGORITHM", v1alpha1.SensitivePropertiesAlgorithm, false),
+			secretEnvVar("PREVIOUS_SENSITIVE_PROPS_KEY", v1alpha1.PreviousSensitivePropertiesKey, true),
+			secretEnvVar("PREVIOUS_SENSITIVE_PROPS_ALGORITHM", v1alpha1.PreviousSensitivePropertiesAlgorithm, true),
+		},
+		VolumeMounts: podVolumeMounts,
+		Resources:    *nodeConfig.GetResources(),
+	}
+}
+
 //
 func generateDataVolumeAndVolumeMount(pvcs []corev1.PersistentVolumeClaim) (volume []corev1.Volume, volumeMount []corev1.VolumeMount) {
 
@@ -328,6 +400,105 @@ func generateVolumesForSSL(cluster *v1alpha1.NifiCluster, nodeId int32) []corev1
 	}
 }
 
+func generateVolumeForRepositoryEncryption(encryption *v1alpha1.RepositoryEncryptionConfig) corev1.Volume {
+	return corev1.Volume{
+		Name: repositoryKeystoreVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: encryption.KeystoreSecretRef.Name,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  encryption.KeystoreSecretRef.Key,
+						Path: repositoryKeystoreFile,
+					},
+				},
+				DefaultMode: util.Int32Pointer(0644),
+			},
+		},
+	}
+}
+
+// generateVolumesForTrustedCAs returns the volumes of the additional trusted certificate authorities bundles, and the
+// one of the truststore they are merged into
+func generateVolumesForTrustedCAs(sources []v1alpha1.TrustedCASource) []corev1.Volume {
+	volumes := []corev1.Volume{
+		{
+			Name: trustedCAsVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+
+	for i, source := range sources {
+		volume := corev1.Volume{Name: fmt.Sprintf("%s-%d", trustedCABundleVolume, i)}
+		if source.SecretRef != nil {
+			volume.VolumeSource.Secret = &corev1.SecretVolumeSource{
+				SecretName:  source.SecretRef.Name,
+				Items:       []corev1.KeyToPath{{Key: source.SecretRef.Key, Path: trustedCABundleFile}},
+				DefaultMode: util.Int32Pointer(0644),
+			}
+		} else if source.ConfigMapRef != nil {
+			volume.VolumeSource.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: source.ConfigMapRef.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: source.ConfigMapRef.Key, Path: trustedCABundleFile}},
+				DefaultMode:          util.Int32Pointer(0644),
+			}
+		}
+		volumes = append(volumes, volume)
+	}
+	return volumes
+}
+
+// trustedCAsInitContainers merges the JVM default truststore and the additional trusted certificate authorities bundles
+// into the truststore mounted in the node, importing each certificate of the PEM bundles.
+func (r *Reconciler) trustedCAsInitContainers(nodeConfig *v1alpha1.NodeConfig) []corev1.Container {
+	if len(r.NifiCluster.Spec.AdditionalTrustedCAs) == 0 {
+		return nil
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      trustedCAsVolume,
+			MountPath: trustedCAsPath,
+		},
+	}
+	for i := range r.NifiCluster.Spec.AdditionalTrustedCAs {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      fmt.Sprintf("%s-%d", trustedCABundleVolume, i),
+			MountPath: fmt.Sprintf("%s/%d", trustedCABundlesPath, i),
+		})
+	}
+
+	return []corev1.Container{
+		{
+			Name:            "additional-trusted-cas",
+			Image:           util.GetNodeImage(nodeConfig, r.NifiCluster.Spec.ClusterImage),
+			ImagePullPolicy: nodeConfig.GetImagePullPolicy(),
+			Command: []string{"bash", "-ce", fmt.Sprintf(`
+TRUSTSTORE=%s/%s
+rm -f $TRUSTSTORE
+keytool -importkeystore -noprompt -srckeystore $(find -L $JAVA_HOME -name cacerts | head -n 1) -srcstorepass changeit -destkeystore $TRUSTSTORE -deststoretype JKS -deststorepass $TRUSTSTORE_PASSWORD
+for bundle in $(ls -d %s/*); do
+	rm -rf /tmp/trusted-cas && mkdir -p /tmp/trusted-cas
+	awk '/-----BEGIN CERTIFICATE-----/{n++} n{print > ("/tmp/trusted-cas/" n ".pem")}' $bundle/%s
+	for cert in /tmp/trusted-cas/*.pem; do
+		echo "Importing $cert from $bundle"
+		keytool -importcert -noprompt -alias "additional-ca-$(basename $bundle)-$(basename $cert .pem)" -file $cert -keystore $TRUSTSTORE -deststorepass $TRUSTSTORE_PASSWORD
+	done
+done`, trustedCAsPath, v1alpha1.TrustedCAsTruststore, trustedCABundlesPath, trustedCABundleFile)},
+			Env: []corev1.EnvVar{
+				{
+					Name:  "TRUSTSTORE_PASSWORD",
+					Value: v1alpha1.TrustedCAsTruststorePassword,
+				},
+			},
+			VolumeMounts: volumeMounts,
+			Resources:    generateInitContainerResources(),
+		},
+	}
+}
+
 func generateVolumeMountForSSL() []corev1.VolumeMount {
 	return []corev1.VolumeMount{
 		{
@@ -42,7 +42,7 @@ import (
 //func encodeBase64(toEncode string) []byte {
 //	return []byte(base64.StdEncoding.EncodeToString([]byte(toEncode)))
 //}
-func (r *Reconciler) secretConfig(id int32, nodeConfig *v1alpha1.NodeConfig, serverPass, clientPass string, superUsers []string, log logr.Logger) runtimeClient.Object {
+func (r *Reconciler) secretConfig(id int32, nodeConfig *v1alpha1.NodeConfig, serverPass, clientPass string, superUsers []string, sensitiveProps *corev1.Secret, repositoryEncryptionPass string, singleUser *corev1.Secret, loginIdentityProviders []v1alpha1.LoginIdentityProvider, log logr.Logger) runtimeClient.Object {
 	secret := &corev1.Secret{
 		ObjectMeta: templates.ObjectMeta(
 			fmt.Sprintf(templates.NodeConfigTemplate+"-%d", r.NifiCluster.Name, id),
@@ -53,10 +53,10 @@ func (r *Reconciler) secretConfig(id int32, nodeConfig *v1alpha1.NodeConfig, ser
 			r.NifiCluster,
 		),
 		Data: map[string][]byte{
-			"nifi.properties":                     []byte(r.generateNifiPropertiesNodeConfig(id, nodeConfig, serverPass, clientPass, superUsers, log)),
+			"nifi.properties":                     []byte(r.generateNifiPropertiesNodeConfig(id, nodeConfig, serverPass, clientPass, superUsers, sensitiveProps, repositoryEncryptionPass, log)),
 			"zookeeper.properties":                []byte(r.generateZookeeperPropertiesNodeConfig(id, nodeConfig, log)),
 			"state-management.xml":                []byte(r.getStateManagementConfigString(nodeConfig, id, log)),
-			"login-identity-providers.xml":        []byte(r.getLoginIdentityProvidersConfigString(nodeConfig, id, log)),
+			"login-identity-providers.xml":        []byte(r.getLoginIdentityProvidersConfigString(nodeConfig, id, singleUser, loginIdentityProviders, log)),
 			"logback.xml":                         []byte(r.getLogbackConfigString(nodeConfig, id, log)),
 			"bootstrap.conf":                      []byte(r.generateBootstrapPropertiesNodeConfig(id, nodeConfig, log)),
 			"bootstrap-notification-services.xml": []byte(r.getBootstrapNotificationServicesConfigString(nodeConfig, id, log)),
@@ -64,7 +64,7 @@ func (r *Reconciler) secretConfig(id int32, nodeConfig *v1alpha1.NodeConfig, ser
 	}
 
 	if configcommon.UseSSL(r.NifiCluster) {
-		secret.Data["authorizers.xml"] = []byte(r.getAuthorizersConfigString(nodeConfig, id, log))
+		secret.Data["authorizers.xml"] = []byte(r.getAuthorizersConfigString(nodeConfig, id, singleUser, log))
 	}
 	return secret
 }
@@ -74,7 +74,7 @@ func (r *Reconciler) secretConfig(id int32, nodeConfig *v1alpha1.NodeConfig, ser
 ////////////////////////////////////
 
 //
-func (r Reconciler) generateNifiPropertiesNodeConfig(id int32, nodeConfig *v1alpha1.NodeConfig, serverPass, clientPass string, superUsers []string, log logr.Logger) string {
+func (r Reconciler) generateNifiPropertiesNodeConfig(id int32, nodeConfig *v1alpha1.NodeConfig, serverPass, clientPass string, superUsers []string, sensitiveProps *corev1.Secret, repositoryEncryptionPass string, log logr.Logger) string {
 	var readOnlyClusterConfig map[string]string
 	if &r.NifiCluster.Spec.ReadOnlyConfig != nil && &r.NifiCluster.Spec.ReadOnlyConfig.NifiProperties != nil {
 		r.generateReadOnlyConfig(
@@ -101,6 +101,15 @@ func (r Reconciler) generateNifiPropertiesNodeConfig(id int32, nodeConfig *v1alp
 		log.Error(err, "error occurred during merging readonly configs")
 	}
 
+	// The sensitive properties key and algorithm are managed by the operator, the overridden ones being only used as
+	// the ones in effect when the sensitive properties secret is created.
+	for _, property := range []string{sensitivePropertiesKeyProperty, sensitivePropertiesAlgorithmProperty} {
+		if _, ok := readOnlyNodeConfig[property]; ok {
+			log.Info("ignoring the override of a property managed by the operator", "property", property)
+			delete(readOnlyNodeConfig, property)
+		}
+	}
+
 	//Generate the Complete Configuration for the Node
 	completeConfigMap := map[string]string{}
 
@@ -108,7 +117,7 @@ func (r Reconciler) generateNifiPropertiesNodeConfig(id int32, nodeConfig *v1alp
 		log.Error(err, "error occurred during merging readOnly config to complete configs")
 	}
 
-	if err := mergo.Merge(&completeConfigMap, util.ParsePropertiesFormat(r.getNifiPropertiesConfigString(nodeConfig, id, serverPass, clientPass, superUsers, log))); err != nil {
+	if err := mergo.Merge(&completeConfigMap, util.ParsePropertiesFormat(r.getNifiPropertiesConfigString(nodeConfig, id, serverPass, clientPass, superUsers, sensitiveProps, repositoryEncryptionPass, log))); err != nil {
 		log.Error(err, "error occurred during merging operator generated configs")
 	}
 
@@ -125,7 +134,7 @@ func (r Reconciler) generateNifiPropertiesNodeConfig(id int32, nodeConfig *v1alp
 }
 
 //
-func (r *Reconciler) getNifiPropertiesConfigString(nConfig *v1alpha1.NodeConfig, id int32, serverPass, clientPass string, superUsers []string, log logr.Logger) string {
+func (r *Reconciler) getNifiPropertiesConfigString(nConfig *v1alpha1.NodeConfig, id int32, serverPass, clientPass string, superUsers []string, sensitiveProps *corev1.Secret, repositoryEncryptionPass string, log logr.Logger) string {
 
 	base := r.GetNifiPropertiesBase(id)
 	var dnsNames []string
@@ -167,16 +176,36 @@ func (r *Reconciler) getNifiPropertiesConfigString(nConfig *v1alpha1.NodeConfig,
 		"TrustStoreFile":                     v1alpha1.TLSJKSTrustStore,
 		"ServerKeystorePassword":             serverPass,
 		"ClientKeystorePassword":             clientPass,
+		"SensitivePropsKey":                  string(sensitiveProps.Data[v1alpha1.SensitivePropertiesKey]),
+		"SensitivePropsAlgorithm":            string(sensitiveProps.Data[v1alpha1.SensitivePropertiesAlgorithm]),
+		"RepositoryEncryption":               r.getRepositoryEncryption(id),
+		"RepositoryKeystorePath":             repositoryKeystorePath,
+		"RepositoryKeystoreFile":             repositoryKeystoreFile,
+		"RepositoryKeystorePassword":         repositoryEncryptionPass,
 		//
-		"LdapConfiguration":      r.NifiCluster.Spec.LdapConfiguration,
-		"IsNode":                 nConfig.GetIsNode(),
-		"ZookeeperConnectString": r.NifiCluster.Spec.ZKAddress,
-		"ZookeeperPath":          r.NifiCluster.Spec.GetZkPath(),
+		"LdapConfiguration":       r.NifiCluster.Spec.LdapConfiguration,
+		"SingleUserConfiguration": r.NifiCluster.Spec.SingleUserConfiguration,
+		"LoginIdentityProviders":  r.NifiCluster.Spec.LoginIdentityProviders,
+		"IsNode":                  nConfig.GetIsNode(),
+		"ZookeeperConnectString":  r.NifiCluster.Spec.ZKAddress,
+		"ZookeeperPath":           r.NifiCluster.Spec.GetZkPath(),
 	}); err != nil {
 		log.Error(err, "error occurred during parsing the config template")
 	}
 	return out.String()
 }
+
+// getRepositoryEncryption returns the repository encryption configuration of the node, the node configuration
+// overriding the cluster one.
+func (r *Reconciler) getRepositoryEncryption(id int32) *v1alpha1.RepositoryEncryptionConfig {
+	for _, node := range r.NifiCluster.Spec.Nodes {
+		if node.Id == id && node.ReadOnlyConfig != nil && node.ReadOnlyConfig.Encryption != nil {
+			return node.ReadOnlyConfig.Encryption
+		}
+	}
+	return r.NifiCluster.Spec.ReadOnlyConfig.Encryption
+}
+
 func generateSuperUsers(users []string) (suStrings []string) {
 	suStrings = make([]string, 0)
 	for _, x := range users {
@@ -284,14 +313,18 @@ func (r *Reconciler) getStateManagementConfigString(nConfig *v1alpha1.NodeConfig
 /////////////////////////////////////////////
 
 //
-func (r *Reconciler) getLoginIdentityProvidersConfigString(nConfig *v1alpha1.NodeConfig, id int32, log logr.Logger) string {
+func (r *Reconciler) getLoginIdentityProvidersConfigString(nConfig *v1alpha1.NodeConfig, id int32, singleUser *corev1.Secret, loginIdentityProviders []v1alpha1.LoginIdentityProvider, log logr.Logger) string {
 
 	var out bytes.Buffer
 	t := template.Must(template.New("nConfig-config").Parse(config.LoginIdentityProvidersTemplate))
 	if err := t.Execute(&out, map[string]interface{}{
-		"NifiCluster":       r.NifiCluster,
-		"Id":                id,
-		"LdapConfiguration": r.NifiCluster.Spec.LdapConfiguration,
+		"NifiCluster":             r.NifiCluster,
+		"Id":                      id,
+		"LdapConfiguration":       r.NifiCluster.Spec.LdapConfiguration,
+		"SingleUserConfiguration": r.NifiCluster.Spec.SingleUserConfiguration,
+		"SingleUserUsername":      string(singleUser.Data[v1alpha1.SingleUserUsername]),
+		"SingleUserPasswordHash":  string(singleUser.Data[v1alpha1.SingleUserPasswordHash]),
+		"LoginIdentityProviders":  loginIdentityProviders,
 	}); err != nil {
 		log.Error(err, "error occurred during parsing the config template")
 	}
@@ -413,7 +446,7 @@ func (r *Reconciler) getBootstrapNotificationServicesConfigString(nConfig *v1alp
 ////////////////////////////////
 
 // TODO: Check if cases where is it necessary before using it (seems to be used for secured use cases)
-func (r *Reconciler) getAuthorizersConfigString(nConfig *v1alpha1.NodeConfig, id int32, log logr.Logger) string {
+func (r *Reconciler) getAuthorizersConfigString(nConfig *v1alpha1.NodeConfig, id int32, singleUser *corev1.Secret, log logr.Logger) string {
 
 	nodeList := make(map[string]string)
 
@@ -427,19 +460,34 @@ func (r *Reconciler) getAuthorizersConfigString(nConfig *v1alpha1.NodeConfig, id
 		}
 	}
 
+	controllerUser := fmt.Sprintf(pkicommon.NodeControllerFQDNTemplate,
+		fmt.Sprintf(pkicommon.NodeControllerTemplate, r.NifiCluster.Name),
+		r.NifiCluster.Namespace,
+		r.NifiCluster.Spec.ListenersConfig.GetClusterDomain())
+	// The operator authenticates as the single user with the basic client type, so it must be the initial admin. The
+	// controller is then granted the admin policies through the managed admins group. As the authorizations are only
+	// seeded when the cluster is created, changing the client type of an existing cluster doesn't change its admin.
+	initialAdmin := controllerUser
+	var singleUserIdentity string
+	if r.NifiCluster.Spec.SingleUserConfiguration.Enabled {
+		singleUserIdentity = string(singleUser.Data[v1alpha1.SingleUserUsername])
+		if r.NifiCluster.GetClientType() == v1alpha1.ClientConfigBasic {
+			initialAdmin = singleUserIdentity
+		}
+	}
+
 	var out bytes.Buffer
 	t := template.Must(template.New("nConfig-config").Parse(authorizersTemplate))
 
 	if err := t.Execute(&out, map[string]interface{}{
-		"NifiCluster": r.NifiCluster,
-		"Id":          id,
-		"ClusterName": r.NifiCluster.Name,
-		"Namespace":   r.NifiCluster.Namespace,
-		"NodeList":    nodeList,
-		"ControllerUser": fmt.Sprintf(pkicommon.NodeControllerFQDNTemplate,
-			fmt.Sprintf(pkicommon.NodeControllerTemplate, r.NifiCluster.Name),
-			r.NifiCluster.Namespace,
-			r.NifiCluster.Spec.ListenersConfig.GetClusterDomain()),
+		"NifiCluster":    r.NifiCluster,
+		"Id":             id,
+		"ClusterName":    r.NifiCluster.Name,
+		"Namespace":      r.NifiCluster.Namespace,
+		"NodeList":       nodeList,
+		"ControllerUser": controllerUser,
+		"SingleUser":     singleUserIdentity,
+		"InitialAdmin":   initialAdmin,
 	}); err != nil {
 		log.Error(err, "error occurred during parsing the config template")
 	}
@@ -512,12 +560,19 @@ func (r *Reconciler) getBootstrapPropertiesConfigString(nConfig *v1alpha1.NodeCo
 		}
 	}
 
+	trustedCAsTruststore := ""
+	if len(r.NifiCluster.Spec.AdditionalTrustedCAs) > 0 {
+		trustedCAsTruststore = fmt.Sprintf("%s/%s", trustedCAsPath, v1alpha1.TrustedCAsTruststore)
+	}
+
 	var out bytes.Buffer
 	t := template.Must(template.New("nConfig-config").Parse(config.BootstrapPropertiesTemplate))
 	if err := t.Execute(&out, map[string]interface{}{
-		"NifiCluster": r.NifiCluster,
-		"Id":          id,
-		"JvmMemory":   base.GetNifiJvmMemory(),
+		"NifiCluster":                  r.NifiCluster,
+		"Id":                           id,
+		"JvmMemory":                    base.GetNifiJvmMemory(),
+		"TrustedCAsTruststore":         trustedCAsTruststore,
+		"TrustedCAsTruststorePassword": v1alpha1.TrustedCAsTruststorePassword,
 	}); err != nil {
 		log.Error(err, "error occurred during parsing the config template")
 	}
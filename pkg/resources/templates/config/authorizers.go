@@ -25,6 +25,9 @@ var AuthorizersTemplate = `{{- $nodeList := .NodeList }}
         <property name="Users File">../data/users.xml</property>
         <property name="Legacy Authorized Users File"></property>
         <property name="Initial User Identity admin">{{ .ControllerUser }}</property>
+{{- if .SingleUser }}
+        <property name="Initial User Identity single-user">{{ html .SingleUser }}</property>
+{{- end }}
 {{- range $i, $host := .NodeList }}
         <property name="Initial User Identity {{ $i }}">{{ $host }}</property>
 {{- end }}
@@ -34,7 +37,7 @@ var AuthorizersTemplate = `{{- $nodeList := .NodeList }}
         <class>org.apache.nifi.authorization.FileAccessPolicyProvider</class>
         <property name="User Group Provider">file-user-group-provider</property>
         <property name="Authorizations File">../data/authorizations.xml</property>
-        <property name="Initial Admin Identity">{{ .ControllerUser }}</property>
+        <property name="Initial Admin Identity">{{ html .InitialAdmin }}</property>
         <property name="Legacy Authorized Users File"></property>
 {{- range $i, $host := .NodeList }}
         <property name="Node Identity {{ $i }}">{{ $host }}</property>
@@ -72,6 +72,12 @@ nifi.bootstrap.sensitive.key=
 
 # Sets the provider of SecureRandom to /dev/urandom to prevent blocking on VMs
 java.arg.15=-Djava.security.egd=file:/dev/urandom
+{{- if .TrustedCAsTruststore }}
+
+# Uses the truststore holding the additional trusted certificate authorities as default truststore
+java.arg.trustedCAsTruststore=-Djavax.net.ssl.trustStore={{ .TrustedCAsTruststore }}
+java.arg.trustedCAsTruststorePassword=-Djavax.net.ssl.trustStorePassword={{ .TrustedCAsTruststorePassword }}
+{{- end }}
 
 ###
 # Notification Services for notifying interested parties when NiFi is stopped, started, dies
@@ -105,6 +105,28 @@ var LoginIdentityProvidersTemplate = `<?xml version="1.0" encoding="UTF-8" stand
         <property name="Authentication Expiration">12 hours</property>
     </provider>
     {{end}}
+    <!--
+        Identity Provider for a single user logging in with username/password, the password being hashed with bcrypt.
+        'Username' - The username of the single user.
+        'Password' - The bcrypt hash of the password of the single user.
+    -->
+    {{if .SingleUserConfiguration.Enabled}}
+    <provider>
+        <identifier>single-user-provider</identifier>
+        <class>org.apache.nifi.authentication.single.user.SingleUserLoginIdentityProvider</class>
+        <property name="Username">{{html .SingleUserUsername}}</property>
+        <property name="Password">{{.SingleUserPasswordHash}}</property>
+    </provider>
+    {{end}}
+    {{- range .LoginIdentityProviders}}
+    <provider>
+        <identifier>{{html .Identifier}}</identifier>
+        <class>{{html .Class}}</class>
+        {{- range .Properties}}
+        <property name="{{html .Name}}">{{html .Value}}</property>
+        {{- end}}
+    </provider>
+    {{- end}}
     <!--
         Identity Provider for users logging in with username/password against a Kerberos KDC server.
         'Default Realm' - Default realm to provide when user enters incomplete user principal (i.e. NIFI.APACHE.ORG).
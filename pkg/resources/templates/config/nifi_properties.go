@@ -80,6 +80,13 @@ nifi.h2.url.append=;LOCK_TIMEOUT=25000;WRITE_DELAY=0;AUTO_SERVER=FALSE
 
 # FlowFile Repository
 nifi.flowfile.repository.implementation=org.apache.nifi.controller.repository.WriteAheadFlowFileRepository
+{{- if .RepositoryEncryption }}
+nifi.flowfile.repository.wal.implementation=org.apache.nifi.wali.EncryptedSequentialAccessWriteAheadLog
+nifi.flowfile.repository.encryption.key.provider.implementation=org.apache.nifi.security.kms.KeyStoreKeyProvider
+nifi.flowfile.repository.encryption.key.provider.location={{ .RepositoryKeystorePath }}/{{ .RepositoryKeystoreFile }}
+nifi.flowfile.repository.encryption.key.provider.password={{ .RepositoryKeystorePassword }}
+nifi.flowfile.repository.encryption.key.id={{ .RepositoryEncryption.KeyId }}
+{{- end }}
 nifi.flowfile.repository.directory=../flowfile_repository
 nifi.flowfile.repository.partitions=256
 nifi.flowfile.repository.checkpoint.interval=2 mins
@@ -93,7 +100,13 @@ nifi.swap.out.period=5 sec
 nifi.swap.out.threads=4
 
 # Content Repository
-nifi.content.repository.implementation=org.apache.nifi.controller.repository.FileSystemRepository
+nifi.content.repository.implementation={{ if .RepositoryEncryption }}org.apache.nifi.controller.repository.crypto.EncryptedFileSystemRepository{{ else }}org.apache.nifi.controller.repository.FileSystemRepository{{ end }}
+{{- if .RepositoryEncryption }}
+nifi.content.repository.encryption.key.provider.implementation=org.apache.nifi.security.kms.KeyStoreKeyProvider
+nifi.content.repository.encryption.key.provider.location={{ .RepositoryKeystorePath }}/{{ .RepositoryKeystoreFile }}
+nifi.content.repository.encryption.key.provider.password={{ .RepositoryKeystorePassword }}
+nifi.content.repository.encryption.key.id={{ .RepositoryEncryption.KeyId }}
+{{- end }}
 nifi.content.claim.max.appendable.size=1 MB
 nifi.content.claim.max.flow.files=100
 nifi.content.repository.directory.default=../content_repository
@@ -104,11 +117,18 @@ nifi.content.repository.always.sync=false
 nifi.content.viewer.url=/nifi-content-viewer/
 
 # Provenance Repository Properties
-nifi.provenance.repository.implementation=org.apache.nifi.provenance.WriteAheadProvenanceRepository
+nifi.provenance.repository.implementation={{ if .RepositoryEncryption }}org.apache.nifi.provenance.EncryptedWriteAheadProvenanceRepository{{ else }}org.apache.nifi.provenance.WriteAheadProvenanceRepository{{ end }}
 nifi.provenance.repository.debug.frequency=1_000_000
+{{- if .RepositoryEncryption }}
+nifi.provenance.repository.encryption.key.provider.implementation=org.apache.nifi.security.kms.KeyStoreKeyProvider
+nifi.provenance.repository.encryption.key.provider.location={{ .RepositoryKeystorePath }}/{{ .RepositoryKeystoreFile }}
+nifi.provenance.repository.encryption.key.provider.password={{ .RepositoryKeystorePassword }}
+nifi.provenance.repository.encryption.key.id={{ .RepositoryEncryption.KeyId }}
+{{- else }}
 nifi.provenance.repository.encryption.key.provider.implementation=
 nifi.provenance.repository.encryption.key.provider.location=
 nifi.provenance.repository.encryption.key.id=
+{{- end }}
 nifi.provenance.repository.encryption.key=
 
 # Persistent Provenance Repository Properties
@@ -158,9 +178,9 @@ nifi.web.jetty.working.directory=./work/jetty
 nifi.web.jetty.threads=200
 
 # security properties #
-nifi.sensitive.props.key=
+nifi.sensitive.props.key={{ .SensitivePropsKey }}
 nifi.sensitive.props.key.protected=
-nifi.sensitive.props.algorithm=PBEWITHMD5AND256BITAES-CBC-OPENSSL
+nifi.sensitive.props.algorithm={{ .SensitivePropsAlgorithm }}
 nifi.sensitive.props.provider=BC
 nifi.sensitive.props.additional.keys=
 
@@ -177,6 +197,10 @@ nifi.security.needClientAuth={{ .NeedClientAuth }}
 nifi.security.user.authorizer={{ .Authorizer }}
 {{if .LdapConfiguration.Enabled}}
 nifi.security.user.login.identity.provider=ldap-provider
+{{else if .SingleUserConfiguration.Enabled}}
+nifi.security.user.login.identity.provider=single-user-provider
+{{else if .LoginIdentityProviders.Selected}}
+nifi.security.user.login.identity.provider={{ .LoginIdentityProviders.Selected }}
 {{else}}
 nifi.security.user.login.identity.provider=
 {{end}}
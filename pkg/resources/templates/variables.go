@@ -18,4 +18,6 @@ const (
 	NodeConfigTemplate            = "%s-config"
 	NodeStorageTemplate           = "%s-%d-storage"
 	ExternalClusterSecretTemplate = "%s-basic-secret"
+	SensitivePropsSecretTemplate  = "%s-sensitive-props"
+	SingleUserSecretTemplate      = "%s-single-user"
 )
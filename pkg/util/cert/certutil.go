@@ -22,8 +22,10 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"math/big"
 	mathrand "math/rand"
+	"net/url"
 	"strings"
 	"time"
 
@@ -86,7 +88,7 @@ func GeneratePass(length int) (passw []byte) {
 	return
 }
 
-// EnsureSecretPassJKS ensures a JKS password is present in a certificate secret
+// EnsureSecretPassJKS ensures a JKS password is present in a certificate secret, it is shared by the PKCS12 keystore
 func EnsureSecretPassJKS(secret *corev1.Secret) (injected *corev1.Secret, err error) {
 
 	// If the JKS Pass is already present - return
@@ -99,6 +101,36 @@ func EnsureSecretPassJKS(secret *corev1.Secret) (injected *corev1.Secret, err er
 	return
 }
 
+// UserSecretKeys returns the keys expected in the secret of a user certificate, according to its keystore formats
+func UserSecretKeys(user *v1alpha1.NifiUser) []string {
+	keys := []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, v1alpha1.CoreCACertKey}
+
+	formats := user.Spec.GetKeystoreFormats()
+	if len(formats) > 0 {
+		keys = append(keys, v1alpha1.PasswordKey)
+	}
+	for _, format := range formats {
+		switch format {
+		case v1alpha1.JKSKeystoreFormat:
+			keys = append(keys, v1alpha1.TLSJKSKeyStore, v1alpha1.TLSJKSTrustStore)
+		case v1alpha1.PKCS12KeystoreFormat:
+			keys = append(keys, v1alpha1.TLSPKCS12KeyStore, v1alpha1.TLSPKCS12TrustStore)
+		}
+	}
+	return keys
+}
+
+// X509Subject returns the subject of a user certificate without its organizations, set apart by cert-manager
+func X509Subject(subject *v1alpha1.CertificateSubject) *certv1.X509Subject {
+	if subject == nil || (len(subject.OrganizationalUnits) == 0 && len(subject.Countries) == 0) {
+		return nil
+	}
+	return &certv1.X509Subject{
+		OrganizationalUnits: subject.OrganizationalUnits,
+		Countries:           subject.Countries,
+	}
+}
+
 // GenerateJKS creates a JKS with a random password from a client cert/key combination
 func GenerateJKS(clientCert, clientKey, clientCA []byte) (out, passw []byte, err error) {
 
@@ -157,6 +189,118 @@ func GenerateJKS(clientCert, clientKey, clientCA []byte) (out, passw []byte, err
 	return outBuf.Bytes(), passw, err
 }
 
+// GeneratePrivateKey generates a PEM encoded PKCS8 RSA private key
+func GeneratePrivateKey() (key []byte, err error) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return
+	}
+	key = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return
+}
+
+// GenerateCertificateRequest creates a PEM encoded certificate request signed by the given PEM encoded private key
+func GenerateCertificateRequest(key []byte, subject pkix.Name, dnsNames []string, uris []*url.URL) (csr []byte, err error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		err = errors.New("failed to decode PEM data")
+		return
+	}
+	priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return
+	}
+	template := x509.CertificateRequest{
+		Subject:  subject,
+		DNSNames: dnsNames,
+		URIs:     uris,
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &template, priv)
+	if err != nil {
+		return
+	}
+	csr = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	return
+}
+
+// DecodeCertificates returns all the x509.Certificate of a PEM encoded bundle
+func DecodeCertificates(raw []byte) (certs []*x509.Certificate, err error) {
+	for block, rest := pem.Decode(raw); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err != nil {
+			return
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		err = errors.New("Failed to decode x509 certificates from PEM")
+	}
+	return
+}
+
+// GenerateJKSStores creates a JKS keystore from a client cert chain/key combination and a JKS truststore from a CA
+// bundle, both protected by the given password
+func GenerateJKSStores(clientCert, clientKey, caBundle, passw []byte) (keyStore, trustStore []byte, err error) {
+	chain, err := DecodeCertificates(clientCert)
+	if err != nil {
+		return
+	}
+
+	key, err := DecodeKey(clientKey)
+	if err != nil {
+		return
+	}
+
+	cas, err := DecodeCertificates(caBundle)
+	if err != nil {
+		return
+	}
+
+	var certChain []keystore.Certificate
+	for _, cert := range chain {
+		certChain = append(certChain, keystore.Certificate{Type: "X.509", Content: cert.Raw})
+	}
+
+	jks := keystore.KeyStore{
+		chain[0].Subject.CommonName: &keystore.PrivateKeyEntry{
+			Entry: keystore.Entry{
+				CreationDate: time.Now(),
+			},
+			PrivKey:   key,
+			CertChain: certChain,
+		},
+	}
+
+	truststore := keystore.KeyStore{}
+	for i, ca := range cas {
+		truststore[fmt.Sprintf("trusted_ca_%d", i)] = &keystore.TrustedCertificateEntry{
+			Entry: keystore.Entry{
+				CreationDate: time.Now(),
+			},
+			Certificate: keystore.Certificate{
+				Type:    "X.509",
+				Content: ca.Raw,
+			},
+		}
+	}
+
+	var keyStoreBuf, trustStoreBuf bytes.Buffer
+	if err = keystore.Encode(&keyStoreBuf, jks, passw); err != nil {
+		return
+	}
+	if err = keystore.Encode(&trustStoreBuf, truststore, passw); err != nil {
+		return
+	}
+	return keyStoreBuf.Bytes(), trustStoreBuf.Bytes(), nil
+}
+
 // GenerateTestCert is used from unit tests for generating certificates
 func GenerateTestCert() (cert, key []byte, expectedDn string, err error) {
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
@@ -17,6 +17,7 @@ package cert
 import (
 	"bytes"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/asn1"
 	"encoding/pem"
 	"reflect"
@@ -123,6 +124,54 @@ func TestGenerateJKS(t *testing.T) {
 	}
 }
 
+func TestGenerateCertificateRequest(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal("Failed to generate private key:", err)
+	}
+
+	raw, err := GenerateCertificateRequest(key, pkix.Name{CommonName: "test-cn"}, []string{"test.example.com"}, nil)
+	if err != nil {
+		t.Fatal("Expected to generate certificate request, got error:", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		t.Fatal("Expected a PEM encoded certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal("Expected a valid certificate request, got error:", err)
+	}
+	if csr.Subject.CommonName != "test-cn" || !reflect.DeepEqual(csr.DNSNames, []string{"test.example.com"}) {
+		t.Error("Expected subject and dns names to be requested, got:", csr.Subject, csr.DNSNames)
+	}
+
+	if _, err = GenerateCertificateRequest(key[:len(key)-10], pkix.Name{}, nil, nil); err == nil {
+		t.Error("Expected to fail decoding key, got nil error")
+	}
+}
+
+func TestGenerateJKSStores(t *testing.T) {
+	cert, key, _, err := GenerateTestCert()
+	if err != nil {
+		t.Error("Failed to generate test certificate")
+	}
+	caBundle := append(append([]byte{}, cert...), cert...)
+
+	if _, _, err = GenerateJKSStores(cert, key, caBundle, []byte("password")); err != nil {
+		t.Error("Expected to generate JKS stores, got error:", err)
+	}
+
+	if _, _, err = GenerateJKSStores(cert, key, []byte("bad bundle"), []byte("password")); err == nil {
+		t.Error("Expected to fail decoding CA bundle, got nil error")
+	}
+
+	badKey := key[:len(key)-10]
+	if _, _, err = GenerateJKSStores(cert, badKey, caBundle, []byte("password")); err == nil {
+		t.Error("Expected to fail decoding key, got nil error")
+	}
+}
+
 func TesEnsureSecretPassJKS(t *testing.T) {
 	cert, key, _, err := GenerateTestCert()
 	if err != nil {
@@ -149,3 +198,38 @@ func TesEnsureSecretPassJKS(t *testing.T) {
 		}
 	}
 }
+
+func TestUserSecretKeys(t *testing.T) {
+	user := &v1alpha1.NifiUser{}
+	expected := []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, v1alpha1.CoreCACertKey}
+	if keys := UserSecretKeys(user); !reflect.DeepEqual(keys, expected) {
+		t.Error("Expected:", expected, "Got:", keys)
+	}
+
+	user.Spec.IncludeJKS = true
+	user.Spec.KeystoreFormats = []v1alpha1.KeystoreFormat{v1alpha1.PKCS12KeystoreFormat}
+	expected = append(expected, v1alpha1.PasswordKey,
+		v1alpha1.TLSJKSKeyStore, v1alpha1.TLSJKSTrustStore,
+		v1alpha1.TLSPKCS12KeyStore, v1alpha1.TLSPKCS12TrustStore)
+	if keys := UserSecretKeys(user); !reflect.DeepEqual(keys, expected) {
+		t.Error("Expected:", expected, "Got:", keys)
+	}
+}
+
+func TestX509Subject(t *testing.T) {
+	if subject := X509Subject(nil); subject != nil {
+		t.Error("Expected no subject, got:", subject)
+	}
+	if subject := X509Subject(&v1alpha1.CertificateSubject{Organizations: []string{"test-o"}}); subject != nil {
+		t.Error("Expected the organizations to be set apart, got:", subject)
+	}
+
+	subject := X509Subject(&v1alpha1.CertificateSubject{
+		OrganizationalUnits: []string{"test-ou"},
+		Countries:           []string{"FR"},
+	})
+	if subject == nil || !reflect.DeepEqual(subject.OrganizationalUnits, []string{"test-ou"}) ||
+		!reflect.DeepEqual(subject.Countries, []string{"FR"}) {
+		t.Error("Expected the organizational units and countries in the subject, got:", subject)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Window is a running window defined by the cron expressions of its start and stop times.
+type Window struct {
+	start cron.Schedule
+	stop  cron.Schedule
+}
+
+// Transition is the next start or stop of a running window.
+type Transition struct {
+	// Time is the time of the transition.
+	Time time.Time
+	// Start is true if the transition starts the window, false if it stops it.
+	Start bool
+}
+
+// NewWindow parses the standard cron expressions of the window start and stop, evaluated in the given IANA
+// timezone, UTC if empty. An expression with its own CRON_TZ or TZ prefix is evaluated in this timezone instead.
+func NewWindow(start, stop, timezone string) (*Window, error) {
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %s: %v", timezone, err)
+		}
+		start = withTimezone(start, timezone)
+		stop = withTimezone(stop, timezone)
+	}
+
+	startSchedule, err := cron.ParseStandard(start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start schedule: %v", err)
+	}
+	stopSchedule, err := cron.ParseStandard(stop)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stop schedule: %v", err)
+	}
+
+	return &Window{start: startSchedule, stop: stopSchedule}, nil
+}
+
+// IsOpen returns whether the window is open at the given time, which is the case when its next transition is a stop.
+func (w *Window) IsOpen(now time.Time) bool {
+	return !w.NextTransition(now).Start
+}
+
+// NextTransition returns the first start or stop of the window after the given time.
+func (w *Window) NextTransition(now time.Time) Transition {
+	nextStart := w.start.Next(now)
+	nextStop := w.stop.Next(now)
+	if nextStop.Before(nextStart) {
+		return Transition{Time: nextStop, Start: false}
+	}
+	return Transition{Time: nextStart, Start: true}
+}
+
+// withTimezone prefixes the cron expression with the timezone, unless it already defines one.
+func withTimezone(spec, timezone string) string {
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		return spec
+	}
+	return fmt.Sprintf("CRON_TZ=%s %s", timezone, spec)
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 Orange SA
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.package apis
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWindow(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewWindow("0 20 * * *", "0 6 * * *", "")
+	assert.Nil(err)
+
+	_, err = NewWindow("0 20 * * *", "0 6 * * *", "Europe/Paris")
+	assert.Nil(err)
+
+	_, err = NewWindow("0 20 * *", "0 6 * * *", "")
+	assert.NotNil(err)
+
+	_, err = NewWindow("0 20 * * *", "0 6 * * *", "Europe/Nowhere")
+	assert.NotNil(err)
+
+	_, err = NewWindow("CRON_TZ=Europe/Paris 0 20 * * *", "0 6 * * *", "Europe/Paris")
+	assert.Nil(err)
+}
+
+func TestWindow(t *testing.T) {
+	assert := assert.New(t)
+
+	// Batch window from 20:00 to 06:00 UTC
+	window, err := NewWindow("0 20 * * *", "0 6 * * *", "")
+	assert.Nil(err)
+
+	now := time.Date(2021, 3, 10, 22, 30, 0, 0, time.UTC)
+	assert.True(window.IsOpen(now))
+	assert.Equal(Transition{Time: time.Date(2021, 3, 11, 6, 0, 0, 0, time.UTC), Start: false}, window.NextTransition(now))
+
+	now = time.Date(2021, 3, 10, 12, 0, 0, 0, time.UTC)
+	assert.False(window.IsOpen(now))
+	assert.Equal(Transition{Time: time.Date(2021, 3, 10, 20, 0, 0, 0, time.UTC), Start: true}, window.NextTransition(now))
+
+	// Same window in the Europe/Paris timezone (UTC+1 in March before the DST)
+	window, err = NewWindow("0 20 * * *", "0 6 * * *", "Europe/Paris")
+	assert.Nil(err)
+
+	now = time.Date(2021, 3, 10, 19, 30, 0, 0, time.UTC)
+	assert.True(window.IsOpen(now))
+	assert.True(window.NextTransition(now).Time.Equal(time.Date(2021, 3, 11, 5, 0, 0, 0, time.UTC)))
+}